@@ -2,14 +2,18 @@ package api
 
 import (
 	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
 )
 
 type createAccountParams struct {
-	Owner    string `json:"owner" binding:"required"`
 	Currency string `json:"currency" binding:"required,currency"`
 }
 
@@ -20,17 +24,41 @@ func (server *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.CreateAccountParams{
-		Owner:    req.Owner,
-		Balance:  0,
-		Currency: req.Currency,
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CreateAccountTxParams{
+		CreateAccountParams: db.CreateAccountParams{
+			Owner:    authPayload.Username,
+			Balance:  0,
+			Currency: req.Currency,
+		},
+	}
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		arg.Idempotency = idempotency.params(authPayload.Username, http.StatusCreated)
 	}
 
-	account, err := server.store.CreateAccount(ctx, arg)
+	account, err := server.store.CreateAccountTx(ctx, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey {
+			server.replayConcurrentRequest(ctx, idempotency, authPayload.Username)
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusForbidden, errorResponse(err))
+				return
+			case "unique_violation":
+				ctx.JSON(http.StatusConflict, errorResponse(err))
+				return
+			}
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
 
 	ctx.JSON(http.StatusCreated, account)
 }
@@ -46,20 +74,74 @@ func (server *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	account, err := server.store.GetAccount(ctx, req.ID)
+	account, valid := server.authorizedAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+type accountBalanceQuery struct {
+	AsOf time.Time `form:"as_of"`
+}
+
+type accountBalanceResponse struct {
+	AccountID int64     `json:"account_id"`
+	Currency  string    `json:"currency"`
+	Balance   int64     `json:"balance"`
+	AsOf      time.Time `json:"as_of"`
+}
+
+// getAccountBalance works out the balance of an account at a point in time from its entries. It is open to
+// the owner of the account and to admins, who answer auditors. Without as_of the current time is used.
+func (server *Server) getAccountBalance(ctx *gin.Context) {
+	var uri getAccountParams
+	var req accountBalanceQuery
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if req.AsOf.IsZero() {
+		req.AsOf = time.Now()
+	}
+
+	account, valid := server.existingAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username && ctx.GetString(authorizationRoleKey) != roleAdmin {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	balance, err := server.store.GetAccountBalanceAsOf(ctx, db.GetAccountBalanceAsOfParams{
+		AsOf:      req.AsOf,
+		AccountID: account.ID,
+	})
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, accountBalanceResponse{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		Balance:   balance,
+		AsOf:      req.AsOf,
+	})
 }
 
 type listAccountsParams struct {
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	paginationParams
 	PageSize int32 `form:"page_size" binding:"required,min=1,max=10"`
 }
 
@@ -70,16 +152,160 @@ func (server *Server) listAccounts(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListAccountsParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.PageID != nil {
+		arg := db.ListAccountsByOwnerParams{
+			Owner:  authPayload.Username,
+			Limit:  req.PageSize,
+			Offset: req.offset(req.PageSize),
+		}
+
+		accounts, err := server.store.ListAccountsByOwner(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, accounts)
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, req.paginationParams)
+	if !valid {
+		return
+	}
+
+	accounts, err := server.store.ListAccountsByOwnerAfter(ctx, db.ListAccountsByOwnerAfterParams{
+		Limit:          req.PageSize + 1,
+		Owner:          authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(accounts, req.PageSize, func(account db.Account) pageCursor {
+		return pageCursor{CreatedAt: account.CreatedAt, ID: account.ID}
+	}))
+}
+
+// authorizedAccount fetches the account and makes sure it belongs to the authenticated user
+func (server *Server) authorizedAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+type closeAccountParams struct {
+	SweepToAccountID int64 `json:"sweep_to_account_id" binding:"omitempty,min=1"`
+}
+
+func (server *Server) closeAccount(ctx *gin.Context) {
+	var uri getAccountParams
+	var req closeAccountParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	// the body is optional, an account with a zero balance can be closed without one
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	account, valid := server.authorizedAccount(ctx, uri.ID)
+	if !valid {
+		return
 	}
+	if req.SweepToAccountID != 0 {
+		sweepAccount, valid := server.existingAccount(ctx, req.SweepToAccountID)
+		if !valid {
+			return
+		}
+		if sweepAccount.Kind != db.AccountKindCustomer {
+			err := fmt.Errorf("account [%d] cannot receive transfers", sweepAccount.ID)
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	result, err := server.store.CloseAccountTx(ctx, db.CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: req.SweepToAccountID,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSweepCurrencyMismatch):
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		case errors.Is(err, db.ErrAccountNotActive),
+			errors.Is(err, db.ErrNonZeroBalance),
+			errors.Is(err, db.ErrActiveHolds),
+			errors.Is(err, db.ErrInsufficientFunds):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+func (server *Server) freezeAccount(ctx *gin.Context) {
+	server.transitionAccountStatus(ctx, db.AccountStatusActive, db.AccountStatusFrozen)
+}
+
+func (server *Server) unfreezeAccount(ctx *gin.Context) {
+	server.transitionAccountStatus(ctx, db.AccountStatusFrozen, db.AccountStatusActive)
+}
 
-	accounts, err := server.store.ListAccounts(ctx, arg)
+// transitionAccountStatus moves an account between statuses, provided it is currently in fromStatus
+func (server *Server) transitionAccountStatus(ctx *gin.Context, fromStatus, toStatus string) {
+	var req getAccountParams
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.existingAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	account, err := server.store.UpdateAccountStatus(ctx, db.UpdateAccountStatusParams{
+		ID:         account.ID,
+		FromStatus: fromStatus,
+		ToStatus:   toStatus,
+	})
 	if err != nil {
+		if err == sql.ErrNoRows {
+			err := fmt.Errorf("account [%d] is not %s", req.ID, fromStatus)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	ctx.JSON(http.StatusOK, account)
 }
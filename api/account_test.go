@@ -5,39 +5,50 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
 	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
 	"github.com/mrityunjaygr8/simplebank/utils"
 	"github.com/stretchr/testify/require"
 )
 
-func randomAccount() db.Account {
+func randomAccount(owner string) db.Account {
 	return db.Account{
 		ID:       utils.RandomInt(1, 1000),
-		Owner:    utils.RandomOwner(),
-		Currency: utils.RandomCurrency(),
+		Owner:    owner,
+		Currency: randomCurrency(),
 		Balance:  utils.RandomMoney(),
+		Kind:     db.AccountKindCustomer,
 	}
 }
 
 func TestGetAccountApi(t *testing.T) {
-	account := randomAccount()
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
 
 	testCases := []struct {
 		name          string
 		accountID     int64
+		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs    func(store *mockdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name:      "OK",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 			},
@@ -49,6 +60,9 @@ func TestGetAccountApi(t *testing.T) {
 		{
 			name:      "NotFOund",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
 			},
@@ -59,6 +73,9 @@ func TestGetAccountApi(t *testing.T) {
 		{
 			name:      "InternalServerError",
 			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
 			},
@@ -69,6 +86,9 @@ func TestGetAccountApi(t *testing.T) {
 		{
 			name:      "BadRequest",
 			accountID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
 			},
@@ -76,6 +96,31 @@ func TestGetAccountApi(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:      "UnauthorizedUser",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:      "NoAuthorization",
+			accountID: account.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
@@ -85,7 +130,8 @@ func TestGetAccountApi(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -93,6 +139,7 @@ func TestGetAccountApi(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 
@@ -114,9 +161,11 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 }
 
 func TestListAccountsApi(t *testing.T) {
+	user, _ := randomUser(t)
+
 	var accounts []db.Account
 	for i := 0; i < 10; i++ {
-		accounts = append(accounts, randomAccount())
+		accounts = append(accounts, randomAccount(user.Username))
 	}
 	// page_id := int32(1)
 	// page_size := int32(1)
@@ -133,7 +182,8 @@ func TestListAccountsApi(t *testing.T) {
 			page_id:   1,
 			page_size: 5,
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(db.ListAccountsParams{
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Eq(db.ListAccountsByOwnerParams{
+					Owner:  user.Username,
 					Limit:  5,
 					Offset: 0,
 				})).Times(1).Return(accounts[0:5], nil)
@@ -148,7 +198,8 @@ func TestListAccountsApi(t *testing.T) {
 			page_id:   15,
 			page_size: 5,
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(db.ListAccountsParams{
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Eq(db.ListAccountsByOwnerParams{
+					Owner:  user.Username,
 					Limit:  5,
 					Offset: 70,
 				})).Times(1).Return([]db.Account{}, nil)
@@ -163,7 +214,7 @@ func TestListAccountsApi(t *testing.T) {
 			page_id:   0,
 			page_size: 5,
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -175,7 +226,7 @@ func TestListAccountsApi(t *testing.T) {
 			page_id:   5,
 			page_size: 0,
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -187,7 +238,7 @@ func TestListAccountsApi(t *testing.T) {
 			page_id:   5,
 			page_size: 0,
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -199,7 +250,8 @@ func TestListAccountsApi(t *testing.T) {
 			page_id:   1,
 			page_size: 5,
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(db.ListAccountsParams{
+				store.EXPECT().ListAccountsByOwner(gomock.Any(), gomock.Eq(db.ListAccountsByOwnerParams{
+					Owner:  user.Username,
 					Limit:  5,
 					Offset: 0,
 				})).Times(1).Return(nil, sql.ErrConnDone)
@@ -219,7 +271,8 @@ func TestListAccountsApi(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -227,6 +280,7 @@ func TestListAccountsApi(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -246,9 +300,10 @@ func requireBodyMatchAccounts(t *testing.T, body *bytes.Buffer, accounts []db.Ac
 }
 
 func TestCreateAccountApi(t *testing.T) {
+	user, _ := randomUser(t)
+
 	testCases := []struct {
 		name          string
-		owner         string
 		currency      string
 		jsonStr       []byte
 		buildStubs    func(store *mockdb.MockStore)
@@ -256,28 +311,28 @@ func TestCreateAccountApi(t *testing.T) {
 	}{
 		{
 			name:     "OK",
-			owner:    "yo",
 			currency: "USD",
-			jsonStr:  []byte(`{"owner": "yo", "currency": "USD"}`),
+			jsonStr:  []byte(`{"currency": "USD"}`),
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{
-					Owner:    "yo",
-					Balance:  0,
-					Currency: "USD",
-				})).Times(1).Return(db.Account{Owner: "yo", Currency: "USD", Balance: 0}, nil)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    user.Username,
+						Balance:  0,
+						Currency: "USD",
+					},
+				})).Times(1).Return(db.Account{Owner: user.Username, Currency: "USD", Balance: 0}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusCreated, recorder.Code)
-				requireBodyMatchCreateAccount(t, recorder.Body, db.Account{Owner: "yo", Currency: "USD", Balance: 0})
+				requireBodyMatchCreateAccount(t, recorder.Body, db.Account{Owner: user.Username, Currency: "USD", Balance: 0})
 			},
 		},
 		{
 			name:     "BadCurrency",
-			owner:    "yo",
 			currency: "USD",
-			jsonStr:  []byte(`{"owner": "yo", "currency": "USD123"}`),
+			jsonStr:  []byte(`{"currency": "USD123"}`),
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -286,42 +341,41 @@ func TestCreateAccountApi(t *testing.T) {
 		},
 		{
 			name:     "BlankCurrency",
-			owner:    "yo",
 			currency: "",
-			jsonStr:  []byte(`{"owner": "yo", "currency": ""}`),
+			jsonStr:  []byte(`{"currency": ""}`),
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
 		{
-			name:     "BlankOwner",
-			owner:    "",
+			name:     "InternalError",
 			currency: "USD",
-			jsonStr:  []byte(`{"owner": "", "currency": "USD"}`),
+			jsonStr:  []byte(`{"currency": "USD"}`),
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Eq(db.CreateAccountTxParams{
+					CreateAccountParams: db.CreateAccountParams{
+						Owner:    user.Username,
+						Balance:  0,
+						Currency: "USD",
+					},
+				})).Times(1).Return(db.Account{}, sql.ErrConnDone)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
 		{
-			name:     "InternalError",
-			owner:    "qwe",
+			name:     "DuplicateCurrency",
 			currency: "USD",
-			jsonStr:  []byte(`{"owner": "qwe", "currency": "USD"}`),
+			jsonStr:  []byte(`{"currency": "USD"}`),
 			buildStubs: func(store *mockdb.MockStore) {
-				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(db.CreateAccountParams{
-					Owner:    "qwe",
-					Balance:  0,
-					Currency: "USD",
-				})).Times(1).Return(db.Account{}, sql.ErrConnDone)
+				store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, &pq.Error{Code: "23505"})
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, http.StatusConflict, recorder.Code)
 			},
 		},
 	}
@@ -334,7 +388,8 @@ func TestCreateAccountApi(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -343,6 +398,7 @@ func TestCreateAccountApi(t *testing.T) {
 			request.Header.Add("Content-Type", "application/json")
 			require.NoError(t, err)
 
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 
@@ -362,3 +418,390 @@ func requireBodyMatchCreateAccount(t *testing.T, body *bytes.Buffer, account db.
 
 	require.Equal(t, account, gotAccount)
 }
+
+func TestCloseAccountApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	sweepAccount := randomAccount(user.Username)
+	sweepAccount.ID = account.ID + 1
+	cashAccount := randomAccount(db.SystemUsername)
+	cashAccount.ID = account.ID + 2
+	cashAccount.Kind = db.AccountKindCash
+	otherAccount := randomAccount("other")
+	otherAccount.ID = account.ID
+
+	closed := account
+	closed.Balance = 0
+	closed.Status = db.AccountStatusClosed
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "ZeroBalance",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Eq(db.CloseAccountTxParams{
+					AccountID: account.ID,
+				})).Times(1).Return(db.CloseAccountTxResult{Account: closed}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.CloseAccountTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, closed, got.Account)
+				require.Nil(t, got.Sweep)
+			},
+		},
+		{
+			name: "Sweep",
+			body: gin.H{"sweep_to_account_id": sweepAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sweepAccount.ID)).Times(1).Return(sweepAccount, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Eq(db.CloseAccountTxParams{
+					AccountID:        account.ID,
+					SweepToAccountID: sweepAccount.ID,
+				})).Times(1).Return(db.CloseAccountTxResult{Account: closed, Sweep: &db.TransferTxResult{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "NonZeroBalance",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CloseAccountTxResult{}, db.ErrNonZeroBalance)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, db.ErrNonZeroBalance.Error())
+			},
+		},
+		{
+			name: "AlreadyClosed",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CloseAccountTxResult{}, &db.AccountStatusError{
+					AccountID: account.ID,
+					Status:    db.AccountStatusClosed,
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "SweepCurrencyMismatch",
+			body: gin.H{"sweep_to_account_id": sweepAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sweepAccount.ID)).Times(1).Return(sweepAccount, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CloseAccountTxResult{}, db.ErrSweepCurrencyMismatch)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "SweepToCashAccount",
+			body: gin.H{"sweep_to_account_id": cashAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(cashAccount.ID)).Times(1).Return(cashAccount, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "SweepAccountNotFound",
+			body: gin.H{"sweep_to_account_id": sweepAccount.ID},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(sweepAccount.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwner",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().CloseAccountTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			var body io.Reader = http.NoBody
+			if tc.body != nil {
+				data, err := json.Marshal(tc.body)
+				require.NoError(t, err)
+				body = bytes.NewReader(data)
+			}
+			url := fmt.Sprintf("/accounts/%d/close", account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, body)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestAccountStatusTransitionApi(t *testing.T) {
+	account := randomAccount("owner")
+
+	frozen := account
+	frozen.Status = db.AccountStatusFrozen
+	active := account
+	active.Status = db.AccountStatusActive
+
+	testCases := []struct {
+		name          string
+		path          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Freeze",
+			path: "freeze",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(active, nil)
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Eq(db.UpdateAccountStatusParams{
+					ToStatus:   db.AccountStatusFrozen,
+					ID:         account.ID,
+					FromStatus: db.AccountStatusActive,
+				})).Times(1).Return(frozen, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, frozen)
+			},
+		},
+		{
+			name: "Unfreeze",
+			path: "unfreeze",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(frozen, nil)
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Eq(db.UpdateAccountStatusParams{
+					ToStatus:   db.AccountStatusActive,
+					ID:         account.ID,
+					FromStatus: db.AccountStatusFrozen,
+				})).Times(1).Return(active, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchAccount(t, recorder.Body, active)
+			},
+		},
+		{
+			name: "InvalidTransition",
+			path: "unfreeze",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(active, nil)
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			path: "freeze",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			path: "freeze",
+			role: roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().UpdateAccountStatus(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d/%s", account.ID, tc.path)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetAccountBalanceApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	otherAccount := randomAccount("other")
+	otherAccount.ID = account.ID
+	asOf := time.Date(2022, 3, 31, 23, 59, 59, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "AsOf",
+			role:  roleDepositor,
+			query: "?as_of=" + asOf.Format(time.RFC3339),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Eq(db.GetAccountBalanceAsOfParams{
+					AsOf:      asOf,
+					AccountID: account.ID,
+				})).Times(1).Return(int64(420), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, account.ID, got.AccountID)
+				require.Equal(t, account.Currency, got.Currency)
+				require.Equal(t, int64(420), got.Balance)
+				require.True(t, asOf.Equal(got.AsOf))
+			},
+		},
+		{
+			name: "DefaultsToNow",
+			role: roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountBalanceResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.WithinDuration(t, time.Now(), got.AsOf, time.Minute)
+			},
+		},
+		{
+			name:  "InvalidAsOf",
+			role:  roleDepositor,
+			query: "?as_of=yesterday",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwner",
+			role: roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Admin",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(1).Return(int64(10), nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			role: roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountBalanceAsOf(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/balance%s", account.ID, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+// listAuditEventsParams filter the audit log, newest events first. The time range includes start_time but
+// not end_time.
+type listAuditEventsParams struct {
+	PageSize     int32     `form:"page_size" binding:"required,min=1,max=100"`
+	Cursor       string    `form:"cursor"`
+	Actor        string    `form:"actor"`
+	Action       string    `form:"action"`
+	ResourceType string    `form:"resource_type"`
+	ResourceID   string    `form:"resource_id"`
+	StartTime    time.Time `form:"start_time"`
+	EndTime      time.Time `form:"end_time" binding:"omitempty,gtfield=StartTime"`
+}
+
+func (server *Server) listAuditEvents(ctx *gin.Context) {
+	var req listAuditEventsParams
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, paginationParams{Cursor: req.Cursor})
+	if !valid {
+		return
+	}
+
+	events, err := server.store.ListAuditEvents(ctx, db.ListAuditEventsParams{
+		Limit:        req.PageSize + 1,
+		Actor:        nullString(req.Actor),
+		Action:       nullString(req.Action),
+		ResourceType: nullString(req.ResourceType),
+		ResourceID:   nullString(req.ResourceID),
+		StartTime:    nullTime(req.StartTime),
+		EndTime:      nullTime(req.EndTime),
+		BeforeID:     nullInt64(cursor.ID),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(events, req.PageSize, func(event db.AuditEvent) pageCursor {
+		return pageCursor{CreatedAt: event.CreatedAt, ID: event.ID}
+	}))
+}
+
+// verifyAuditEvents checks the whole audit chain. A broken chain is reported in the body, not as an error.
+func (server *Server) verifyAuditEvents(ctx *gin.Context) {
+	verification, err := server.store.VerifyAuditChain(ctx, 0)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, verification)
+}
@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func randomAuditEvents(n int) []db.AuditEvent {
+	events := make([]db.AuditEvent, n)
+	for i := range events {
+		events[i] = db.AuditEvent{
+			ID:           int64(n - i),
+			Actor:        "admin",
+			Action:       "account.update",
+			ResourceType: "account",
+			ResourceID:   "1",
+			Before:       json.RawMessage(`{"balance":1}`),
+			After:        json.RawMessage(`{"balance":2}`),
+			CreatedAt:    time.Date(2022, 3, 1, 0, 0, n-i, 0, time.UTC),
+		}
+	}
+	return events
+}
+
+func TestListAuditEventsApi(t *testing.T) {
+	events := randomAuditEvents(3)
+	cursor := pageCursor{CreatedAt: events[1].CreatedAt, ID: events[1].ID}
+
+	testCases := []struct {
+		name          string
+		query         string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			query: "page_size=2&actor=admin&resource_type=account",
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(db.ListAuditEventsParams{
+					Limit:        3,
+					Actor:        sql.NullString{String: "admin", Valid: true},
+					ResourceType: sql.NullString{String: "account", Valid: true},
+				})).Times(1).Return(events, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.AuditEvent]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, events[:2], got.Items)
+				require.Equal(t, cursor.encode(), got.NextCursor)
+			},
+		},
+		{
+			name:  "NextPage",
+			query: "page_size=2&cursor=" + cursor.encode(),
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Eq(db.ListAuditEventsParams{
+					Limit:    3,
+					BeforeID: sql.NullInt64{Int64: cursor.ID, Valid: true},
+				})).Times(1).Return(events[2:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.AuditEvent]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, events[2:], got.Items)
+				require.Empty(t, got.NextCursor)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			query: "page_size=2",
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidTimeRange",
+			query: "page_size=2&start_time=2022-03-02T00:00:00Z&end_time=2022-03-01T00:00:00Z",
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: "page_size=2&cursor=not-a-cursor",
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errInvalidCursor.Error())
+			},
+		},
+		{
+			name:  "InternalError",
+			query: "page_size=2",
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAuditEvents(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/admin/audit_events?%s", tc.query), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestVerifyAuditEventsApi(t *testing.T) {
+	verification := db.AuditVerification{Checked: 10, LastID: 9, LastHash: "abc", BrokenAt: 10, Problem: "event does not match its hash"}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().VerifyAuditChain(gomock.Any(), gomock.Eq(int32(0))).Times(1).Return(verification, nil)
+	stubAuthUserWithRole(store, roleAdmin)
+	server := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/admin/audit_events/verify", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got db.AuditVerification
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, verification, got)
+}
+
+func TestAuditMiddleware(t *testing.T) {
+	testCases := []struct {
+		name      string
+		requestID string
+		check     func(t *testing.T, audit db.AuditContext, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:      "ClientRequestID",
+			requestID: "client-request-1",
+			check: func(t *testing.T, audit db.AuditContext, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, "client-request-1", audit.RequestID)
+				require.Equal(t, "client-request-1", recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+		{
+			name: "GeneratedRequestID",
+			check: func(t *testing.T, audit db.AuditContext, recorder *httptest.ResponseRecorder) {
+				require.NotEmpty(t, audit.RequestID)
+				require.Equal(t, audit.RequestID, recorder.Header().Get(requestIDHeaderKey))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			var audit db.AuditContext
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().VerifyAuditChain(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(
+				func(ctx context.Context, _ int32) (db.AuditVerification, error) {
+					audit = db.AuditContextFrom(ctx)
+					return db.AuditVerification{Valid: true}, nil
+				})
+			stubAuthUserWithRole(store, roleAdmin)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/admin/audit_events/verify", nil)
+			require.NoError(t, err)
+			if tc.requestID != "" {
+				request.Header.Set(requestIDHeaderKey, tc.requestID)
+			}
+			request.RemoteAddr = "192.0.2.1:1234"
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusOK, recorder.Code)
+
+			require.Equal(t, "admin", audit.Actor)
+			require.Equal(t, "192.0.2.1", audit.ClientIP)
+			tc.check(t, audit, recorder)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+type batchTransferLegParams struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	amountParams
+}
+
+// batchTransferParams takes a single currency for the whole batch; every account on every leg must hold it
+type batchTransferParams struct {
+	Currency string                   `json:"currency" binding:"required,currency"`
+	Legs     []batchTransferLegParams `json:"legs" binding:"required,min=1,max=100,dive"`
+}
+
+// createBatchTransfer posts a list of transfers atomically. All source accounts must belong to the caller,
+// and the batch is refused when the fraud rules would block or review any of its transfers.
+func (server *Server) createBatchTransfer(ctx *gin.Context) {
+	var req batchTransferParams
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	legs := make([]db.BatchTransferLeg, len(req.Legs))
+	for i, leg := range req.Legs {
+		_, amount, valid := server.parseAmount(ctx, leg.amountParams, req.Currency)
+		if !valid {
+			return
+		}
+		legs[i] = db.BatchTransferLeg{
+			FromAccountID: leg.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        amount,
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	accounts := make(map[int64]db.Account)
+	for _, leg := range legs {
+		for _, id := range []int64{leg.FromAccountID, leg.ToAccountID} {
+			if _, seen := accounts[id]; seen {
+				continue
+			}
+			account, valid := server.validAccount(ctx, id, req.Currency)
+			if !valid {
+				return
+			}
+			accounts[id] = account
+		}
+
+		if accounts[leg.FromAccountID].Owner != authPayload.Username {
+			err := fmt.Errorf("account [%d] doesn't belong to the authenticated user", leg.FromAccountID)
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		if accounts[leg.ToAccountID].Kind != db.AccountKindCustomer {
+			err := fmt.Errorf("account [%d] cannot receive transfers", leg.ToAccountID)
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+	}
+
+	screened := make([]fraud.Transfer, len(legs))
+	for i, leg := range legs {
+		screened[i] = fraud.Transfer{
+			FromAccountID: leg.FromAccountID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        leg.Amount,
+			Currency:      req.Currency,
+		}
+	}
+	if err := fraud.ScreenBatch(ctx, server.screener, screened); err != nil {
+		if response, refused := screeningErrorResponse(err); refused {
+			ctx.JSON(http.StatusUnprocessableEntity, response)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	arg := db.BatchTransferTxParams{Legs: legs}
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		arg.Idempotency = idempotency.params(authPayload.Username, http.StatusCreated)
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrBatchCurrencyMismatch) {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountNotActive) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrTransferLimitExceeded) {
+			ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey {
+			server.replayConcurrentRequest(ctx, idempotency, authPayload.Username)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
@@ -0,0 +1,288 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBatchTransferApi(t *testing.T) {
+	user, _ := randomUser(t)
+	source := randomAccount(user.Username)
+	source.Currency = "USD"
+	payee1 := randomAccount("payee1")
+	payee1.ID = source.ID + 1
+	payee1.Currency = "USD"
+	payee2 := randomAccount("payee2")
+	payee2.ID = source.ID + 2
+	payee2.Currency = "USD"
+
+	legs := []gin.H{
+		{"from_account_id": source.ID, "to_account_id": payee1.ID, "amount": 10},
+		{"from_account_id": source.ID, "to_account_id": payee2.ID, "decimal_amount": "0.25"},
+	}
+	batchLegs := []db.BatchTransferLeg{
+		{FromAccountID: source.ID, ToAccountID: payee1.ID, Amount: 10},
+		{FromAccountID: source.ID, ToAccountID: payee2.ID, Amount: 25},
+	}
+	result := db.BatchTransferTxResult{
+		Batch: db.TransferBatch{ID: 1},
+		Transfers: []db.TransferTxResult{
+			{Transfer: db.Transfer{ID: 1, FromAccountID: source.ID, ToAccountID: payee1.ID, Amount: 10}},
+			{Transfer: db.Transfer{ID: 2, FromAccountID: source.ID, ToAccountID: payee2.ID, Amount: 25}},
+		},
+	}
+
+	stubAccounts := func(store *mockdb.MockStore, accounts ...db.Account) {
+		for _, account := range accounts {
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"currency": "USD", "legs": legs},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, source, payee1, payee2)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Eq(db.BatchTransferTxParams{
+					Legs: batchLegs,
+				})).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.BatchTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, result, got)
+			},
+		},
+		{
+			name: "NoLegs",
+			body: gin.H{"currency": "USD", "legs": []gin.H{}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidLeg",
+			body: gin.H{"currency": "USD", "legs": []gin.H{
+				{"from_account_id": source.ID, "to_account_id": source.ID, "amount": 10},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			body: gin.H{"currency": "EUR", "legs": legs},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, source)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotOwner",
+			body: gin.H{"currency": "USD", "legs": []gin.H{
+				{"from_account_id": payee1.ID, "to_account_id": payee2.ID, "amount": 10},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, payee1, payee2)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			body: gin.H{"currency": "USD", "legs": legs},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, source)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			body: gin.H{"currency": "USD", "legs": legs},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, source, payee1, payee2)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.BatchTransferTxResult{}, fmt.Errorf("leg 1: %w", db.ErrInsufficientFunds))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, "leg 1: insufficient funds")
+			},
+		},
+		{
+			name: "InternalError",
+			body: gin.H{"currency": "USD", "legs": legs},
+			buildStubs: func(store *mockdb.MockStore) {
+				stubAccounts(store, source, payee1, payee2)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.BatchTransferTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateBatchTransferScreening(t *testing.T) {
+	user, _ := randomUser(t)
+	source := randomAccount(user.Username)
+	source.Currency = "USD"
+	payee := randomAccount("payee")
+	payee.ID = source.ID + 1
+	payee.Currency = "USD"
+
+	body := gin.H{"currency": "USD", "legs": []gin.H{
+		{"from_account_id": source.ID, "to_account_id": payee.ID, "amount": 10},
+	}}
+	reasons := []fraud.Reason{{Rule: "large_amount", Action: fraud.ActionReview, Message: "amount is large"}}
+
+	testCases := []struct {
+		name          string
+		screener      stubScreener
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Allow",
+			screener: stubScreener{decision: fraud.Decision{Action: fraud.ActionAllow}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.BatchTransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "Review",
+			screener: stubScreener{decision: fraud.Decision{Action: fraud.ActionReview, Reasons: reasons}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got struct {
+					Error   string         `json:"error"`
+					Reasons []fraud.Reason `json:"reasons"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, fraud.ErrBatchNeedsReview.Error(), got.Error)
+				require.Equal(t, reasons, got.Reasons)
+			},
+		},
+		{
+			name:     "Blocked",
+			screener: stubScreener{decision: fraud.Decision{Action: fraud.ActionBlock, Reasons: reasons}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got struct {
+					Error string `json:"error"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, fraud.ErrTransferBlocked.Error(), got.Error)
+			},
+		},
+		{
+			name:     "ScreeningError",
+			screener: stubScreener{err: sql.ErrConnDone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().BatchTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(source.ID)).Times(1).Return(source, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(payee.ID)).Times(1).Return(payee, nil)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+			server.screener = tc.screener
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
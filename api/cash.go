@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+type cashRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type cashRequestParams struct {
+	amountParams
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+type cashTxFunc func(ctx context.Context, arg db.CashTxParams) (db.CashTxResult, error)
+
+// cashAccountFunc looks up the account of a cash request, writing the response when it may not be used
+type cashAccountFunc func(ctx *gin.Context, accountID int64) (db.Account, bool)
+
+// createDeposit lets an admin credit cash that was paid in to any account
+func (server *Server) createDeposit(ctx *gin.Context) {
+	server.postCash(ctx, server.store.DepositTx, server.existingAccount)
+}
+
+func (server *Server) createWithdrawal(ctx *gin.Context) {
+	server.postCash(ctx, server.store.WithdrawTx, server.authorizedAccount)
+}
+
+// postCash moves money between an account and the bank's cash account
+func (server *Server) postCash(ctx *gin.Context, cashTx cashTxFunc, cashAccount cashAccountFunc) {
+	var uri cashRequestURI
+	var req cashRequestParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, amount, valid := server.parseAmount(ctx, req.amountParams, req.Currency)
+	if !valid {
+		return
+	}
+
+	account, valid := cashAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if account.Currency != req.Currency {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, req.Currency)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.CashTxParams{
+		AccountID: account.ID,
+		Amount:    amount,
+	}
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		arg.Idempotency = idempotency.params(authPayload.Username, http.StatusCreated)
+	}
+
+	result, err := cashTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountNotActive) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrTransferLimitExceeded) {
+			ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey {
+			server.replayConcurrentRequest(ctx, idempotency, authPayload.Username)
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
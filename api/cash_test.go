@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCashApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = "USD"
+	otherAccount := randomAccount("other")
+	otherAccount.ID = account.ID
+	otherAccount.Currency = "USD"
+
+	result := db.CashTxResult{
+		Transfer: db.Transfer{ID: 1, ToAccountID: account.ID, Amount: 100},
+		Account:  account,
+		Entry:    db.Entry{ID: 1, AccountID: account.ID, Amount: 100},
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		path          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Deposit",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Eq(db.CashTxParams{
+					AccountID: account.ID,
+					Amount:    100,
+				})).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.CashTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, result, got)
+			},
+		},
+		{
+			name: "DecimalWithdrawal",
+			role: roleDepositor,
+			path: "/accounts/%d/withdrawals",
+			body: gin.H{"decimal_amount": "1.5", "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Eq(db.CashTxParams{
+					AccountID: account.ID,
+					Amount:    150,
+				})).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			role: roleDepositor,
+			path: "/accounts/%d/withdrawals",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "WithdrawalLimitExceeded",
+			role: roleDepositor,
+			path: "/accounts/%d/withdrawals",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				limitErr := &db.TransferLimitError{
+					AccountID: account.ID,
+					Limit:     db.TransferLimitDailyAmount,
+					Max:       150,
+					Used:      100,
+					Requested: 10000,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, limitErr)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got struct {
+					Limit db.TransferLimitError `json:"limit"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.TransferLimitDailyAmount, got.Limit.Limit)
+			},
+		},
+		{
+			name: "AccountFrozen",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, &db.AccountStatusError{
+					AccountID: account.ID,
+					Status:    db.AccountStatusFrozen,
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, fmt.Sprintf("account [%d] is frozen", account.ID))
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "EUR"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "DepositToOtherUser",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "DepositNotAdmin",
+			role: roleDepositor,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "WithdrawalNotOwner",
+			role: roleDepositor,
+			path: "/accounts/%d/withdrawals",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().WithdrawTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NegativeAmount",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": -100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			role: roleAdmin,
+			path: "/admin/accounts/%d/deposits",
+			body: gin.H{"amount": 100, "currency": "USD"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().DepositTx(gomock.Any(), gomock.Any()).Times(1).Return(db.CashTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := fmt.Sprintf(tc.path, account.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
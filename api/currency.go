@@ -0,0 +1,125 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+)
+
+// amountParams lets clients give an amount either in minor units of the currency or as a decimal
+// in major units. Exactly one of the two is required.
+type amountParams struct {
+	Amount        int64  `json:"amount" binding:"required_without=DecimalAmount,excluded_with=DecimalAmount,omitempty,gt=0"`
+	DecimalAmount string `json:"decimal_amount" binding:"required_without=Amount,excluded_with=Amount"`
+}
+
+// parseAmount returns the currency and the amount in its minor units. Decimal amounts with more
+// places than the currency allows are rejected.
+func (server *Server) parseAmount(ctx *gin.Context, req amountParams, code string) (db.Currency, int64, bool) {
+	currency, enabled := server.currencies.Enabled(ctx, code)
+	if !enabled {
+		err := fmt.Errorf("currency %s is not supported", code)
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return currency, 0, false
+	}
+
+	if req.DecimalAmount == "" {
+		return currency, req.Amount, true
+	}
+
+	amount, err := utils.ParseAmount(req.DecimalAmount, currency.MinorUnits)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return currency, 0, false
+	}
+	return currency, amount, true
+}
+
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, currencies)
+}
+
+type createCurrencyParams struct {
+	Code       string `json:"code" binding:"required,len=3,uppercase,alpha"`
+	Name       string `json:"name" binding:"required"`
+	MinorUnits *int32 `json:"minor_units" binding:"required,min=0,max=4"`
+}
+
+func (server *Server) createCurrency(ctx *gin.Context) {
+	var req createCurrencyParams
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	currency, err := server.store.CreateCurrency(ctx, db.CreateCurrencyParams{
+		Code:       req.Code,
+		Name:       req.Name,
+		MinorUnits: *req.MinorUnits,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				ctx.JSON(http.StatusConflict, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.currencies.Invalidate()
+	ctx.JSON(http.StatusCreated, currency)
+}
+
+type updateCurrencyURI struct {
+	Code string `uri:"code" binding:"required,len=3"`
+}
+
+type updateCurrencyParams struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
+
+// updateCurrency enables or disables a currency. Existing accounts keep their balances, but no new
+// accounts or transfers can be made in a disabled currency.
+func (server *Server) updateCurrency(ctx *gin.Context) {
+	var uri updateCurrencyURI
+	var req updateCurrencyParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	currency, err := server.store.SetCurrencyEnabled(ctx, db.SetCurrencyEnabledParams{
+		Code:    uri.Code,
+		Enabled: *req.Enabled,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	server.currencies.Invalidate()
+	ctx.JSON(http.StatusOK, currency)
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCurrenciesApi(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var gotCurrencies []db.Currency
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &gotCurrencies))
+	require.Equal(t, testCurrencies, gotCurrencies)
+}
+
+func TestCreateCurrencyApi(t *testing.T) {
+	currency := db.Currency{Code: "JPY", Name: "Yen", MinorUnits: 0, Enabled: true}
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: roleAdmin,
+			body: gin.H{"code": "JPY", "name": "Yen", "minor_units": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Eq(db.CreateCurrencyParams{
+					Code:       "JPY",
+					Name:       "Yen",
+					MinorUnits: 0,
+				})).Times(1).Return(currency, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: roleDepositor,
+			body: gin.H{"code": "JPY", "name": "Yen", "minor_units": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "MissingMinorUnits",
+			role: roleAdmin,
+			body: gin.H{"code": "JPY", "name": "Yen"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCode",
+			role: roleAdmin,
+			body: gin.H{"code": "jp1", "name": "Yen", "minor_units": 0},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Duplicate",
+			role: roleAdmin,
+			body: gin.H{"code": "USD", "name": "US Dollar", "minor_units": 2},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			request, err := http.NewRequest(http.MethodPost, "/admin/currencies", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateCurrencyApi(t *testing.T) {
+	testCases := []struct {
+		name          string
+		code          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Disable",
+			code: "CAD",
+			body: gin.H{"enabled": false},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetCurrencyEnabled(gomock.Any(), gomock.Eq(db.SetCurrencyEnabledParams{
+					Code:    "CAD",
+					Enabled: false,
+				})).Times(1).Return(db.Currency{Code: "CAD", MinorUnits: 2, Enabled: false}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "MissingEnabled",
+			code: "CAD",
+			body: gin.H{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetCurrencyEnabled(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			code: "XYZ",
+			body: gin.H{"enabled": true},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SetCurrencyEnabled(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, roleAdmin)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+			url := fmt.Sprintf("/admin/currencies/%s", tc.code)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestDisabledCurrencyRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+		{Code: "USD", MinorUnits: 2, Enabled: true},
+		{Code: "CAD", MinorUnits: 2, Enabled: false},
+	}, nil)
+	store.EXPECT().CreateAccountTx(gomock.Any(), gomock.Any()).Times(0)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/accounts", bytes.NewBufferString(`{"currency": "CAD"}`))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -1,15 +1,21 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
 )
 
+var errRunningBalanceWithFilters = errors.New("running_balance cannot be combined with filters or sorting")
+
 type listEntriesParams struct {
+	paginationParams
+	listFilterParams
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	// AccountID limits the list to the entries of one account
+	AccountID int64 `form:"account_id" binding:"omitempty,min=1"`
 }
 
 func (server *Server) listEntries(ctx *gin.Context) {
@@ -20,23 +26,60 @@ func (server *Server) listEntries(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListEntriesParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	filtered := req.AccountID != 0 || req.listFilterParams.active()
+	if req.PageID != nil && filtered {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errFiltersWithPageID))
+		return
+	}
+	if !server.authorizedSearch(ctx, req.AccountID) {
+		return
 	}
 
-	entries, err := server.store.ListEntries(ctx, arg)
+	if req.PageID != nil {
+		arg := db.ListEntriesParams{
+			Limit:  req.PageSize,
+			Offset: req.offset(req.PageSize),
+		}
+
+		entries, err := server.store.ListEntries(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, entries)
+		return
+	}
+
+	if filtered {
+		server.searchEntries(ctx, req.listFilterParams, req.AccountID, req.paginationParams, req.PageSize)
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, req.paginationParams)
+	if !valid {
+		return
+	}
+
+	entries, err := server.store.ListEntriesAfter(ctx, db.ListEntriesAfterParams{
+		Limit:          req.PageSize + 1,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, entries)
+	ctx.JSON(http.StatusOK, newListPage(entries, req.PageSize, entryCursor))
 }
 
 type listEntriesForAccountQueryParams struct {
+	paginationParams
+	listFilterParams
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	// RunningBalance adds the balance of the account after each entry, with entries in the order they were posted
+	RunningBalance bool `form:"running_balance"`
 }
 
 type listEntriesForAccountURI struct {
@@ -57,9 +100,43 @@ func (server *Server) listEntriesForAccount(ctx *gin.Context) {
 		return
 	}
 
+	if _, valid := server.authorizedAccount(ctx, req.AccountID); !valid {
+		return
+	}
+
+	if queryParams.RunningBalance && queryParams.listFilterParams.active() {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errRunningBalanceWithFilters))
+		return
+	}
+
+	if queryParams.PageID == nil {
+		server.listEntriesForAccountAfter(ctx, req.AccountID, queryParams)
+		return
+	}
+
+	if queryParams.listFilterParams.active() {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errFiltersWithPageID))
+		return
+	}
+
+	if queryParams.RunningBalance {
+		entries, err := server.store.ListEntriesWithBalanceForAccount(ctx, db.ListEntriesWithBalanceForAccountParams{
+			AccountID: req.AccountID,
+			Limit:     queryParams.PageSize,
+			Offset:    queryParams.offset(queryParams.PageSize),
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, entries)
+		return
+	}
+
 	arg := db.ListEntriesForAccountParams{
 		Limit:     queryParams.PageSize,
-		Offset:    (queryParams.PageID - 1) * queryParams.PageSize,
+		Offset:    queryParams.offset(queryParams.PageSize),
 		AccountID: req.AccountID,
 	}
 
@@ -71,3 +148,51 @@ func (server *Server) listEntriesForAccount(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, entries)
 }
+
+// listEntriesForAccountAfter serves the cursor paginated variant of listEntriesForAccount
+func (server *Server) listEntriesForAccountAfter(ctx *gin.Context, accountID int64, queryParams listEntriesForAccountQueryParams) {
+	if queryParams.listFilterParams.active() {
+		server.searchEntries(ctx, queryParams.listFilterParams, accountID, queryParams.paginationParams, queryParams.PageSize)
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, queryParams.paginationParams)
+	if !valid {
+		return
+	}
+
+	if queryParams.RunningBalance {
+		entries, err := server.store.ListEntriesWithBalanceForAccountAfter(ctx, db.ListEntriesWithBalanceForAccountAfterParams{
+			Limit:          queryParams.PageSize + 1,
+			AccountID:      accountID,
+			AfterCreatedAt: cursor.CreatedAt,
+			AfterID:        cursor.ID,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, newListPage(entries, queryParams.PageSize, func(entry db.ListEntriesWithBalanceForAccountAfterRow) pageCursor {
+			return pageCursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+		}))
+		return
+	}
+
+	entries, err := server.store.ListEntriesForAccountAfter(ctx, db.ListEntriesForAccountAfterParams{
+		Limit:          queryParams.PageSize + 1,
+		AccountID:      accountID,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(entries, queryParams.PageSize, entryCursor))
+}
+
+func entryCursor(entry db.Entry) pageCursor {
+	return pageCursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
@@ -27,6 +28,8 @@ func randomEntry() db.Entry {
 }
 
 func TestListEntries(t *testing.T) {
+	user, _ := randomUser(t)
+
 	var entries []db.Entry
 	for x := 0; x < 10; x++ {
 		entries = append(entries, randomEntry())
@@ -35,6 +38,7 @@ func TestListEntries(t *testing.T) {
 
 	testCases := []struct {
 		name          string
+		role          string
 		page_size     int32
 		page_id       int32
 		buildStubs    func(store *mockdb.MockStore)
@@ -42,6 +46,7 @@ func TestListEntries(t *testing.T) {
 	}{
 		{
 			name:      "OK",
+			role:      roleAdmin,
 			page_size: 5,
 			page_id:   1,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -57,6 +62,7 @@ func TestListEntries(t *testing.T) {
 		},
 		{
 			name:      "Zero-Page",
+			role:      roleAdmin,
 			page_size: 5,
 			page_id:   0,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -68,6 +74,7 @@ func TestListEntries(t *testing.T) {
 		},
 		{
 			name:      "Big-Page-Size",
+			role:      roleAdmin,
 			page_size: 50,
 			page_id:   0,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -79,6 +86,7 @@ func TestListEntries(t *testing.T) {
 		},
 		{
 			name:      "Internal-error",
+			role:      roleAdmin,
 			page_size: 5,
 			page_id:   1,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -91,6 +99,19 @@ func TestListEntries(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:      "NotAdmin",
+			role:      roleDepositor,
+			page_size: 5,
+			page_id:   1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errSearchAllAccounts.Error())
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
@@ -100,7 +121,8 @@ func TestListEntries(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -108,6 +130,7 @@ func TestListEntries(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -126,6 +149,10 @@ func requireBodyMatchListEntry(t *testing.T, body *bytes.Buffer, entries []db.En
 	require.Equal(t, entries, gotEntries)
 }
 func TestListEntriesForAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.ID = 1
+
 	var entries []db.Entry
 	for x := 0; x < 10; x++ {
 		entry := randomEntry()
@@ -147,6 +174,7 @@ func TestListEntriesForAccount(t *testing.T) {
 			page_id:    1,
 			account_id: 1,
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 				store.EXPECT().ListEntriesForAccount(gomock.Any(), gomock.Eq(db.ListEntriesForAccountParams{
 					Limit:     5,
 					Offset:    0,
@@ -188,6 +216,7 @@ func TestListEntriesForAccount(t *testing.T) {
 			page_id:    1,
 			account_id: 1,
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 				store.EXPECT().ListEntriesForAccount(gomock.Any(), gomock.Eq(db.ListEntriesForAccountParams{
 					Limit:     5,
 					Offset:    0,
@@ -210,6 +239,21 @@ func TestListEntriesForAccount(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:       "Unauthorized-User",
+			page_size:  5,
+			page_id:    1,
+			account_id: 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				otherAccount := randomAccount("other_user")
+				otherAccount.ID = 1
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().ListEntriesForAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
@@ -219,7 +263,8 @@ func TestListEntriesForAccount(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -227,8 +272,46 @@ func TestListEntriesForAccount(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
+
+func TestListEntriesWithRunningBalance(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	entries := []db.ListEntriesWithBalanceForAccountRow{
+		{ID: 1, AccountID: account.ID, Amount: 100, RunningBalance: 100},
+		{ID: 2, AccountID: account.ID, Amount: -30, RunningBalance: 70},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListEntriesWithBalanceForAccount(gomock.Any(), gomock.Eq(db.ListEntriesWithBalanceForAccountParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    5,
+	})).Times(1).Return(entries, nil)
+	store.EXPECT().ListEntriesForAccount(gomock.Any(), gomock.Any()).Times(0)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/entries/%d?page_size=5&page_id=2&running_balance=true", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []db.ListEntriesWithBalanceForAccountRow
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, entries, got)
+}
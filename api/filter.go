@@ -0,0 +1,182 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+const (
+	directionIncoming = "incoming"
+	directionOutgoing = "outgoing"
+
+	sortByAmount  = "amount"
+	sortOrderDesc = "desc"
+)
+
+var (
+	errFiltersWithPageID = errors.New("filters and sorting are only available with cursor pagination")
+	errSearchAllAccounts = fmt.Errorf("listing across all accounts requires the %s role", roleAdmin)
+)
+
+// listFilterParams narrow down and sort the entries and transfers lists. Amounts are in minor units and the
+// time range includes start_time but not end_time. The direction and the counterparty are relative to the
+// account whose entries or transfers are listed.
+type listFilterParams struct {
+	StartTime      time.Time `form:"start_time"`
+	EndTime        time.Time `form:"end_time" binding:"omitempty,gtfield=StartTime"`
+	MinAmount      int64     `form:"min_amount" binding:"omitempty,min=1"`
+	MaxAmount      int64     `form:"max_amount" binding:"omitempty,min=1,gtefield=MinAmount"`
+	Direction      string    `form:"direction" binding:"omitempty,oneof=incoming outgoing"`
+	CounterpartyID int64     `form:"counterparty_id" binding:"omitempty,min=1"`
+	SortBy         string    `form:"sort_by" binding:"omitempty,oneof=created_at amount"`
+	SortOrder      string    `form:"sort_order" binding:"omitempty,oneof=asc desc"`
+}
+
+// active tells whether any filter or sort option was given
+func (filters listFilterParams) active() bool {
+	return filters != listFilterParams{}
+}
+
+// cursor points at the last row of a page in the sort order of the filters
+func (filters listFilterParams) cursor(createdAt time.Time, id int64, amount int64) pageCursor {
+	cursor := pageCursor{CreatedAt: createdAt, ID: id}
+	if filters.SortBy == sortByAmount {
+		cursor.Amount = amount
+	}
+	return cursor
+}
+
+// searchCursor decodes the cursor of a filtered list. A cursor only continues the sort order it was issued for.
+func searchCursor(ctx *gin.Context, params paginationParams, filters listFilterParams) (pageCursor, bool) {
+	cursor, valid := parseCursor(ctx, params)
+	if !valid {
+		return cursor, false
+	}
+	if cursor.ID != 0 && (filters.SortBy == sortByAmount) != (cursor.Amount != 0) {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidCursor))
+		return cursor, false
+	}
+	return cursor, true
+}
+
+// searchTransfersParams turns the filters into the arguments of SearchTransfers. accountID is the account the
+// direction and the counterparty are relative to, without one they match either side of a transfer.
+func (filters listFilterParams) searchTransfersParams(accountID int64, pageSize int32, cursor pageCursor) db.SearchTransfersParams {
+	arg := db.SearchTransfersParams{
+		Limit:          pageSize + 1,
+		StartTime:      nullTime(filters.StartTime),
+		EndTime:        nullTime(filters.EndTime),
+		MinAmount:      nullInt64(filters.MinAmount),
+		MaxAmount:      nullInt64(filters.MaxAmount),
+		AfterID:        nullInt64(cursor.ID),
+		SortBy:         filters.SortBy,
+		Descending:     filters.SortOrder == sortOrderDesc,
+		AfterAmount:    nullInt64(cursor.Amount),
+		AfterCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: cursor.ID != 0},
+	}
+
+	switch filters.Direction {
+	case directionOutgoing:
+		arg.FromAccountID = nullInt64(accountID)
+		arg.ToAccountID = nullInt64(filters.CounterpartyID)
+	case directionIncoming:
+		arg.FromAccountID = nullInt64(filters.CounterpartyID)
+		arg.ToAccountID = nullInt64(accountID)
+	default:
+		arg.AccountID = nullInt64(accountID)
+		arg.CounterpartyID = nullInt64(filters.CounterpartyID)
+	}
+
+	return arg
+}
+
+// searchEntriesParams turns the filters into the arguments of SearchEntries. Entries are matched and sorted on
+// the absolute amount, the direction is given by the sign.
+func (filters listFilterParams) searchEntriesParams(accountID int64, pageSize int32, cursor pageCursor) db.SearchEntriesParams {
+	return db.SearchEntriesParams{
+		Limit:          pageSize + 1,
+		AccountID:      nullInt64(accountID),
+		Direction:      sql.NullString{String: filters.Direction, Valid: filters.Direction != ""},
+		CounterpartyID: nullInt64(filters.CounterpartyID),
+		StartTime:      nullTime(filters.StartTime),
+		EndTime:        nullTime(filters.EndTime),
+		MinAmount:      nullInt64(filters.MinAmount),
+		MaxAmount:      nullInt64(filters.MaxAmount),
+		AfterID:        nullInt64(cursor.ID),
+		SortBy:         filters.SortBy,
+		Descending:     filters.SortOrder == sortOrderDesc,
+		AfterAmount:    nullInt64(cursor.Amount),
+		AfterCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: cursor.ID != 0},
+	}
+}
+
+// authorizedSearch makes sure the caller may list or search the given account. Admins may search any
+// account, and they are the only ones who may list or search across all accounts.
+func (server *Server) authorizedSearch(ctx *gin.Context, accountID int64) bool {
+	if ctx.GetString(authorizationRoleKey) == roleAdmin {
+		return true
+	}
+	if accountID == 0 {
+		ctx.JSON(http.StatusForbidden, errorResponse(errSearchAllAccounts))
+		return false
+	}
+
+	_, valid := server.authorizedAccount(ctx, accountID)
+	return valid
+}
+
+func (server *Server) searchTransfers(ctx *gin.Context, filters listFilterParams, accountID int64, params paginationParams, pageSize int32) {
+	cursor, valid := searchCursor(ctx, params, filters)
+	if !valid {
+		return
+	}
+
+	transfers, err := server.store.SearchTransfers(ctx, filters.searchTransfersParams(accountID, pageSize, cursor))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(transfers, pageSize, func(transfer db.Transfer) pageCursor {
+		return filters.cursor(transfer.CreatedAt, transfer.ID, transfer.Amount)
+	}))
+}
+
+func (server *Server) searchEntries(ctx *gin.Context, filters listFilterParams, accountID int64, params paginationParams, pageSize int32) {
+	cursor, valid := searchCursor(ctx, params, filters)
+	if !valid {
+		return
+	}
+
+	entries, err := server.store.SearchEntries(ctx, filters.searchEntriesParams(accountID, pageSize, cursor))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(entries, pageSize, func(entry db.Entry) pageCursor {
+		amount := entry.Amount
+		if amount < 0 {
+			amount = -amount
+		}
+		return filters.cursor(entry.CreatedAt, entry.ID, amount)
+	}))
+}
+
+func nullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: value != 0}
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
@@ -0,0 +1,526 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCursorSortOrder(t *testing.T) {
+	byAmount := listFilterParams{SortBy: sortByAmount}
+	cursor := byAmount.cursor(time.Now(), 42, 500)
+	require.Equal(t, int64(500), cursor.Amount)
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	_, valid := searchCursor(ctx, paginationParams{Cursor: cursor.encode()}, byAmount)
+	require.True(t, valid)
+
+	// a cursor issued for one sort order cannot continue another
+	ctx, _ = gin.CreateTestContext(httptest.NewRecorder())
+	_, valid = searchCursor(ctx, paginationParams{Cursor: cursor.encode()}, listFilterParams{})
+	require.False(t, valid)
+}
+
+func TestListTransfersForAccountFilterApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	start := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	transfers := make([]db.Transfer, 6)
+	for i := range transfers {
+		transfers[i] = randomTransfer()
+		transfers[i].ID = int64(i + 1)
+		transfers[i].ToAccountID = account.ID
+		transfers[i].Amount = int64(100000 - i)
+		transfers[i].CreatedAt = start.Add(time.Duration(i) * time.Hour)
+	}
+	byAmount := listFilterParams{SortBy: sortByAmount, SortOrder: sortOrderDesc}
+	cursor := byAmount.cursor(transfers[4].CreatedAt, transfers[4].ID, transfers[4].Amount)
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "IncomingFromCounterpartyLastWeek",
+			query: url.Values{
+				"page_size":       {"5"},
+				"direction":       {"incoming"},
+				"counterparty_id": {"7"},
+				"min_amount":      {"50000"},
+				"start_time":      {start.Format(time.RFC3339)},
+				"end_time":        {end.Format(time.RFC3339)},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					Limit:         6,
+					FromAccountID: sql.NullInt64{Int64: 7, Valid: true},
+					ToAccountID:   sql.NullInt64{Int64: account.ID, Valid: true},
+					StartTime:     sql.NullTime{Time: start, Valid: true},
+					EndTime:       sql.NullTime{Time: end, Valid: true},
+					MinAmount:     sql.NullInt64{Int64: 50000, Valid: true},
+				})).Times(1).Return(transfers[:2], nil)
+				store.EXPECT().ListTransfersForAccountAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.Transfer]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, transfers[:2], got.Items)
+				require.Empty(t, got.NextCursor)
+			},
+		},
+		{
+			name:  "EitherDirectionWithCounterparty",
+			query: url.Values{"page_size": {"5"}, "counterparty_id": {"7"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					Limit:          6,
+					AccountID:      sql.NullInt64{Int64: account.ID, Valid: true},
+					CounterpartyID: sql.NullInt64{Int64: 7, Valid: true},
+				})).Times(1).Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "SortByAmountFirstPage",
+			query: url.Values{"page_size": {"5"}, "sort_by": {"amount"}, "sort_order": {"desc"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					Limit:      6,
+					AccountID:  sql.NullInt64{Int64: account.ID, Valid: true},
+					SortBy:     sortByAmount,
+					Descending: true,
+				})).Times(1).Return(transfers, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.Transfer]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, transfers[:5], got.Items)
+				require.Equal(t, cursor.encode(), got.NextCursor)
+			},
+		},
+		{
+			name:  "SortByAmountNextPage",
+			query: url.Values{"page_size": {"5"}, "sort_by": {"amount"}, "sort_order": {"desc"}, "cursor": {cursor.encode()}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					Limit:          6,
+					AccountID:      sql.NullInt64{Int64: account.ID, Valid: true},
+					SortBy:         sortByAmount,
+					Descending:     true,
+					AfterID:        sql.NullInt64{Int64: cursor.ID, Valid: true},
+					AfterAmount:    sql.NullInt64{Int64: cursor.Amount, Valid: true},
+					AfterCreatedAt: sql.NullTime{Time: cursor.CreatedAt, Valid: true},
+				})).Times(1).Return(transfers[5:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "CursorOfAnotherSortOrder",
+			query: url.Values{"page_size": {"5"}, "min_amount": {"1"}, "cursor": {cursor.encode()}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errInvalidCursor.Error())
+			},
+		},
+		{
+			name:  "FiltersWithPageID",
+			query: url.Values{"page_size": {"5"}, "page_id": {"1"}, "direction": {"outgoing"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransfersForAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errFiltersWithPageID.Error())
+			},
+		},
+		{
+			name:  "MaxAmountBelowMinAmount",
+			query: url.Values{"page_size": {"5"}, "min_amount": {"500"}, "max_amount": {"100"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "EndTimeBeforeStartTime",
+			query: url.Values{"page_size": {"5"}, "start_time": {end.Format(time.RFC3339)}, "end_time": {start.Format(time.RFC3339)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidDirection",
+			query: url.Values{"page_size": {"5"}, "direction": {"sideways"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidSortField",
+			query: url.Values{"page_size": {"5"}, "sort_by": {"to_account_id"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			query: url.Values{"page_size": {"5"}, "direction": {"outgoing"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/transfers/%d?%s", account.ID, tc.query.Encode()), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListTransfersFilterApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	other := randomAccount("other")
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OutgoingFromAccount",
+			query: url.Values{"page_size": {"5"}, "account_id": {fmt.Sprint(account.ID)}, "direction": {"outgoing"}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					Limit:         6,
+					FromAccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+				})).Times(1).Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "AccountOfAnotherUser",
+			query: url.Values{"page_size": {"5"}, "account_id": {fmt.Sprint(other.ID)}, "counterparty_id": {"7"}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "AllAccountsNotAdmin",
+			query: url.Values{"page_size": {"5"}, "counterparty_id": {fmt.Sprint(other.ID)}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errSearchAllAccounts.Error())
+			},
+		},
+		{
+			name:  "AllAccountsAdmin",
+			query: url.Values{"page_size": {"5"}, "min_amount": {"500"}},
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					Limit:     6,
+					MinAmount: sql.NullInt64{Int64: 500, Valid: true},
+				})).Times(1).Return([]db.Transfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "DirectionWithoutAccount",
+			query: url.Values{"page_size": {"5"}, "direction": {"outgoing"}},
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "AccountWithPageID",
+			query: url.Values{"page_size": {"5"}, "page_id": {"1"}, "account_id": {fmt.Sprint(account.ID)}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errFiltersWithPageID.Error())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/transfers?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListEntriesFilterApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	other := randomAccount("other")
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "IncomingToAccount",
+			query: url.Values{"page_size": {"5"}, "account_id": {fmt.Sprint(account.ID)}, "direction": {"incoming"}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().SearchEntries(gomock.Any(), gomock.Eq(db.SearchEntriesParams{
+					Limit:     6,
+					AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+					Direction: sql.NullString{String: directionIncoming, Valid: true},
+				})).Times(1).Return([]db.Entry{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "AccountOfAnotherUser",
+			query: url.Values{"page_size": {"5"}, "account_id": {fmt.Sprint(other.ID)}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(other.ID)).Times(1).Return(other, nil)
+				store.EXPECT().SearchEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:  "AllAccountsNotAdmin",
+			query: url.Values{"page_size": {"5"}, "counterparty_id": {fmt.Sprint(other.ID)}},
+			role:  roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchEntries(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errSearchAllAccounts.Error())
+			},
+		},
+		{
+			name:  "AllAccountsAdmin",
+			query: url.Values{"page_size": {"5"}, "sort_by": {"amount"}},
+			role:  roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchEntries(gomock.Any(), gomock.Eq(db.SearchEntriesParams{
+					Limit:  6,
+					SortBy: sortByAmount,
+				})).Times(1).Return([]db.Entry{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/entries?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListEntriesForAccountFilterApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	entries := []db.Entry{
+		{ID: 2, AccountID: account.ID, Amount: -700},
+		{ID: 1, AccountID: account.ID, Amount: -600},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OutgoingSortedByAmount",
+			query: url.Values{"page_size": {"5"}, "direction": {"outgoing"}, "min_amount": {"500"}, "sort_by": {"amount"}, "sort_order": {"desc"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchEntries(gomock.Any(), gomock.Eq(db.SearchEntriesParams{
+					Limit:      6,
+					AccountID:  sql.NullInt64{Int64: account.ID, Valid: true},
+					Direction:  sql.NullString{String: directionOutgoing, Valid: true},
+					MinAmount:  sql.NullInt64{Int64: 500, Valid: true},
+					SortBy:     sortByAmount,
+					Descending: true,
+				})).Times(1).Return(entries, nil)
+				store.EXPECT().ListEntriesForAccountAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.Entry]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, entries, got.Items)
+			},
+		},
+		{
+			name:  "RunningBalanceWithFilters",
+			query: url.Values{"page_size": {"5"}, "running_balance": {"true"}, "direction": {"incoming"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().SearchEntries(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListEntriesWithBalanceForAccountAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errRunningBalanceWithFilters.Error())
+			},
+		},
+		{
+			name:  "FiltersWithPageID",
+			query: url.Values{"page_size": {"5"}, "page_id": {"1"}, "counterparty_id": {"7"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListEntriesForAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errFiltersWithPageID.Error())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/entries/%d?%s", account.ID, tc.query.Encode()), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	idempotencyRequestKey     = "idempotency_request"
+	maxIdempotencyKeyLength   = 255
+	idempotencyResponseFormat = "application/json; charset=utf-8"
+)
+
+var errIdempotencyKeyReused = errors.New("idempotency key was already used for a different request")
+
+// idempotencyRequest is attached to the context of a request carrying an Idempotency-Key header.
+// Handlers that record the response inside their own transaction mark it as stored.
+type idempotencyRequest struct {
+	key         string
+	requestHash string
+	stored      bool
+}
+
+// params returns the arguments a store transaction needs to record its result under this key
+func (req *idempotencyRequest) params(username string, responseCode int) *db.IdempotencyParams {
+	return &db.IdempotencyParams{
+		Username:     username,
+		Key:          req.key,
+		RequestHash:  req.requestHash,
+		ResponseCode: int32(responseCode),
+	}
+}
+
+// bodyCapturingWriter keeps a copy of the response body so it can be stored after the handler has run
+type bodyCapturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCapturingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCapturingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// idempotencyMiddleware makes a mutating route safe to retry. Requests without an Idempotency-Key header
+// pass straight through. The first successful response for a key is stored and replayed on every retry
+// with the same method, path and body; reusing the key for a different request is rejected.
+// It must run after authMiddleware since keys are scoped to the authenticated user.
+func idempotencyMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if len(key) == 0 {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			err := errors.New("idempotency key is too long")
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+
+		body, err := io.ReadAll(ctx.Request.Body)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+		req := &idempotencyRequest{
+			key:         key,
+			requestHash: hashRequest(ctx.Request.Method, ctx.Request.URL.Path, body),
+		}
+
+		stored, err := store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+			Username: authPayload.Username,
+			Key:      key,
+		})
+		if err == nil {
+			replayIdempotentResponse(ctx, req, stored)
+			ctx.Abort()
+			return
+		}
+		if err != sql.ErrNoRows {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		writer := &bodyCapturingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+		ctx.Set(idempotencyRequestKey, req)
+
+		ctx.Next()
+
+		status := writer.Status()
+		if req.stored || status < http.StatusOK || status >= http.StatusMultipleChoices {
+			return
+		}
+
+		_, err = store.CreateIdempotencyKey(ctx, db.CreateIdempotencyKeyParams{
+			Username:     authPayload.Username,
+			Key:          key,
+			RequestHash:  req.requestHash,
+			ResponseCode: int32(status),
+			ResponseBody: writer.body.Bytes(),
+		})
+		if err != nil {
+			log.Printf("cannot store idempotency key %q: %v", key, err)
+		}
+	}
+}
+
+// replayIdempotentResponse writes a previously stored response, provided it was stored for the same request
+func replayIdempotentResponse(ctx *gin.Context, req *idempotencyRequest, stored db.IdempotencyKey) {
+	if stored.RequestHash != req.requestHash {
+		ctx.JSON(http.StatusUnprocessableEntity, errorResponse(errIdempotencyKeyReused))
+		return
+	}
+
+	ctx.Header(idempotentReplayedHeader, "true")
+	ctx.Data(int(stored.ResponseCode), idempotencyResponseFormat, stored.ResponseBody)
+}
+
+// replayConcurrentRequest answers a request that lost the race to store its idempotency key
+// with the response of the request that won
+func (server *Server) replayConcurrentRequest(ctx *gin.Context, req *idempotencyRequest, username string) {
+	stored, err := server.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: username,
+		Key:      req.key,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	req.stored = true
+	replayIdempotentResponse(ctx, req, stored)
+}
+
+// idempotencyFromContext returns the idempotency key of the current request, if it carried one
+func idempotencyFromContext(ctx *gin.Context) (*idempotencyRequest, bool) {
+	value, exists := ctx.Get(idempotencyRequestKey)
+	if !exists {
+		return nil, false
+	}
+	req, ok := value.(*idempotencyRequest)
+	return req, ok
+}
+
+func hashRequest(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
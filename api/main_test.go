@@ -3,10 +3,51 @@ package api
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
 )
 
+// testCurrencies mirrors the currencies seeded by the migrations
+var testCurrencies = []db.Currency{
+	{Code: "CAD", Name: "Canadian Dollar", MinorUnits: 2, Enabled: true},
+	{Code: "EUR", Name: "Euro", MinorUnits: 2, Enabled: true},
+	{Code: "USD", Name: "US Dollar", MinorUnits: 2, Enabled: true},
+}
+
+// randomCurrency returns the code of one of testCurrencies
+func randomCurrency() string {
+	codes := make([]string, len(testCurrencies))
+	for i, currency := range testCurrencies {
+		codes[i] = currency.Code
+	}
+	return utils.RandomCurrency(codes)
+}
+
+// newTestServer creates a server for the given store. When the store is a mock, the currency
+// registry is stubbed with testCurrencies unless the test set up its own expectation first.
+func newTestServer(t *testing.T, store db.Store) *Server {
+	if mockStore, ok := store.(*mockdb.MockStore); ok {
+		mockStore.EXPECT().ListCurrencies(gomock.Any()).AnyTimes().Return(testCurrencies, nil)
+	}
+
+	config := utils.Config{
+		TokenSymmetricKey:    utils.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 	os.Exit(m.Run())
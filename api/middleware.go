@@ -0,0 +1,126 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+const (
+	authorizationHeaderKey  = "authorization"
+	authorizationTypeBearer = "bearer"
+	authorizationPayloadKey = "authorization_payload"
+	authorizationRoleKey    = "authorization_role"
+)
+
+const (
+	roleDepositor = "depositor"
+	roleAdmin     = "admin"
+)
+
+const (
+	requestIDHeaderKey = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+var errTokenRevoked = errors.New("token was issued before the last password change")
+
+// authMiddleware parses the bearer token of the request and stores its payload in the context.
+// Tokens issued before the user last changed their password are rejected.
+func authMiddleware(tokenMaker token.Maker, store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
+		if len(authorizationHeader) == 0 {
+			err := errors.New("authorization header is not provided")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		fields := strings.Fields(authorizationHeader)
+		if len(fields) < 2 {
+			err := errors.New("invalid authorization header format")
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		authorizationType := strings.ToLower(fields[0])
+		if authorizationType != authorizationTypeBearer {
+			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		accessToken := fields[1]
+		payload, err := tokenMaker.VerifyToken(accessToken, token.TokenTypeAccess)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		user, err := store.GetUser(ctx, payload.Username)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		if payload.IssuedAt.Before(user.PasswordChangedAt) {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(errTokenRevoked))
+			return
+		}
+
+		ctx.Set(authorizationPayloadKey, payload)
+		ctx.Set(authorizationRoleKey, user.Role)
+		setAuditActor(ctx, payload.Username)
+		ctx.Next()
+	}
+}
+
+// roleMiddleware only lets through users holding the given role. It must run after authMiddleware.
+func roleMiddleware(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.GetString(authorizationRoleKey) != role {
+			err := fmt.Errorf("this action requires the %s role", role)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// auditMiddleware tags the request with the id and client address that its audit events record, starting
+// out with the anonymous actor until authMiddleware knows who is calling. A request id sent by the client is
+// kept, otherwise one is generated, and either way it is echoed in the response.
+func auditMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(requestIDHeaderKey)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = uuid.NewString()
+		}
+		ctx.Header(requestIDHeaderKey, requestID)
+
+		ctx.Request = ctx.Request.WithContext(db.WithAuditContext(ctx.Request.Context(), db.AuditContext{
+			Actor:     db.AuditActorAnonymous,
+			RequestID: requestID,
+			ClientIP:  ctx.ClientIP(),
+		}))
+		ctx.Next()
+	}
+}
+
+func setAuditActor(ctx *gin.Context, actor string) {
+	audit := db.AuditContextFrom(ctx.Request.Context())
+	audit.Actor = actor
+	ctx.Request = ctx.Request.WithContext(db.WithAuditContext(ctx.Request.Context(), audit))
+}
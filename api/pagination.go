@@ -0,0 +1,76 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+// paginationParams is embedded in the query of every list endpoint. Requests with a page_id keep the old
+// offset pagination and get a plain array back; all others page with cursors and get a listPage.
+type paginationParams struct {
+	PageID *int32 `form:"page_id" binding:"omitempty,min=1"`
+	Cursor string `form:"cursor" binding:"excluded_with=PageID"`
+}
+
+// offset returns the offset of the page selected by page_id
+func (params paginationParams) offset(pageSize int32) int32 {
+	if params.PageID == nil {
+		return 0
+	}
+	return (*params.PageID - 1) * pageSize
+}
+
+// pageCursor points at the last row of a page, the next page starts right after it in (created_at, id) order
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+	// Amount is only set when the list is sorted by amount
+	Amount int64 `json:"a,omitempty"`
+}
+
+func (cursor pageCursor) encode() string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseCursor decodes the cursor of the request. An empty cursor starts from the first page.
+func parseCursor(ctx *gin.Context, params paginationParams) (pageCursor, bool) {
+	var cursor pageCursor
+	if params.Cursor == "" {
+		return cursor, true
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(params.Cursor)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.ID <= 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errInvalidCursor))
+		return cursor, false
+	}
+	return cursor, true
+}
+
+type listPage[T any] struct {
+	Items []T `json:"items"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// newListPage builds the response from a query that fetched one row more than the page size,
+// which tells whether there is a next page without another round trip
+func newListPage[T any](items []T, pageSize int32, cursorOf func(T) pageCursor) listPage[T] {
+	page := listPage[T]{Items: items}
+	if len(items) > int(pageSize) {
+		page.Items = items[:pageSize]
+		page.NextCursor = cursorOf(page.Items[pageSize-1]).encode()
+	}
+	return page
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursorRoundTrip(t *testing.T) {
+	cursor := pageCursor{CreatedAt: time.Date(2022, 3, 1, 10, 30, 0, 123456000, time.UTC), ID: 42}
+
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	got, valid := parseCursor(ctx, paginationParams{Cursor: cursor.encode()})
+	require.True(t, valid)
+	require.True(t, cursor.CreatedAt.Equal(got.CreatedAt))
+	require.Equal(t, cursor.ID, got.ID)
+}
+
+func TestListTransfersCursorApi(t *testing.T) {
+	user, _ := randomUser(t)
+
+	createdAt := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	transfers := make([]db.Transfer, 6)
+	for i := range transfers {
+		transfers[i] = randomTransfer()
+		transfers[i].ID = int64(i + 1)
+		transfers[i].CreatedAt = createdAt.Add(time.Duration(i) * time.Second)
+	}
+	cursor := transferCursor(transfers[4])
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "FirstPage",
+			role:  roleAdmin,
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Eq(db.ListTransfersAfterParams{
+					Limit: 6,
+				})).Times(1).Return(transfers, nil)
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.Transfer]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, transfers[:5], got.Items)
+				require.Equal(t, cursor.encode(), got.NextCursor)
+			},
+		},
+		{
+			name:  "LastPage",
+			role:  roleAdmin,
+			query: "page_size=5&cursor=" + cursor.encode(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Eq(db.ListTransfersAfterParams{
+					Limit:          6,
+					AfterCreatedAt: cursor.CreatedAt,
+					AfterID:        cursor.ID,
+				})).Times(1).Return(transfers[5:], nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got listPage[db.Transfer]
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, transfers[5:], got.Items)
+				require.Empty(t, got.NextCursor)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			role:  roleAdmin,
+			query: "page_size=5&cursor=not-a-cursor",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errInvalidCursor.Error())
+			},
+		},
+		{
+			name:  "CursorWithPageID",
+			role:  roleAdmin,
+			query: "page_size=5&page_id=1&cursor=" + cursor.encode(),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "InternalError",
+			role:  roleAdmin,
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			role:  roleDepositor,
+			query: "page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfersAfter(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/transfers?%s", tc.query), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListAccountsCursorApi(t *testing.T) {
+	user, _ := randomUser(t)
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username)}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListAccountsByOwnerAfter(gomock.Any(), gomock.Eq(db.ListAccountsByOwnerAfterParams{
+		Limit: 6,
+		Owner: user.Username,
+	})).Times(1).Return(accounts, nil)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/accounts?page_size=5", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got listPage[db.Account]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, accounts, got.Items)
+	require.Empty(t, got.NextCursor)
+}
+
+func TestListEntriesForAccountCursorApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	entries := []db.ListEntriesWithBalanceForAccountAfterRow{
+		{ID: 1, AccountID: account.ID, Amount: 100, RunningBalance: 100},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListEntriesWithBalanceForAccountAfter(gomock.Any(), gomock.Eq(db.ListEntriesWithBalanceForAccountAfterParams{
+		Limit:     6,
+		AccountID: account.ID,
+	})).Times(1).Return(entries, nil)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	url := fmt.Sprintf("/entries/%d?page_size=5&running_balance=true", account.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got listPage[db.ListEntriesWithBalanceForAccountAfterRow]
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+	require.Equal(t, entries, got.Items)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+// screenTransfer runs the fraud rules against a transfer before it is made. A blocked transfer is refused
+// and a transfer that needs review is parked as a pending transfer until an admin approves it; in both
+// cases the response has been written and false is returned.
+func (server *Server) screenTransfer(ctx *gin.Context, req transferRequestParams, amount int64, username string) bool {
+	var idempotencyParams *db.IdempotencyParams
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		idempotencyParams = idempotency.params(username, http.StatusAccepted)
+	}
+
+	pending, err := fraud.ScreenTransfer(ctx, server.screener, server.store, fraud.Transfer{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        amount,
+		Currency:      req.Currency,
+	}, username, idempotencyParams)
+	if err != nil {
+		if response, refused := screeningErrorResponse(err); refused {
+			ctx.JSON(http.StatusUnprocessableEntity, response)
+			return false
+		}
+		if errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey {
+			server.replayConcurrentRequest(ctx, idempotency, username)
+			return false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return false
+	}
+	if pending == nil {
+		return true
+	}
+
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
+	ctx.JSON(http.StatusAccepted, pending)
+	return false
+}
+
+// screeningErrorResponse describes a transfer or batch the fraud rules refused, along with the rules that
+// matched. It returns false for other errors.
+func screeningErrorResponse(err error) (gin.H, bool) {
+	var blocked *fraud.BlockedError
+	if errors.As(err, &blocked) {
+		response := errorResponse(fraud.ErrTransferBlocked)
+		response["reasons"] = blocked.Reasons
+		return response, true
+	}
+
+	var review *fraud.BatchReviewError
+	if errors.As(err, &review) {
+		response := errorResponse(fraud.ErrBatchNeedsReview)
+		response["reasons"] = review.Reasons
+		return response, true
+	}
+
+	return nil, false
+}
+
+type pendingTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// getPendingTransfer lets the user who requested a transfer follow its review
+func (server *Server) getPendingTransfer(ctx *gin.Context) {
+	var uri pendingTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pending, valid := server.existingPendingTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if pending.RequestedBy != authPayload.Username && ctx.GetString(authorizationRoleKey) != roleAdmin {
+		err := errors.New("pending transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pending)
+}
+
+type listPendingTransfersParams struct {
+	Status   string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=1,max=10"`
+}
+
+// listPendingTransfers lists the transfers in review, oldest first. Reviewed ones are listed with a status.
+func (server *Server) listPendingTransfers(ctx *gin.Context) {
+	var req listPendingTransfersParams
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	status := req.Status
+	if status == "" {
+		status = db.PendingTransferStatusPending
+	}
+
+	pending, err := server.store.ListPendingTransfers(ctx, db.ListPendingTransfersParams{
+		Status: status,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pending)
+}
+
+// approvePendingTransfer makes a transfer that was held for review. Cross-currency transfers are converted
+// at the rate of the time of approval.
+func (server *Server) approvePendingTransfer(ctx *gin.Context) {
+	var uri pendingTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pending, valid := server.existingPendingTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if pending.Status != db.PendingTransferStatusPending {
+		err := &db.PendingTransferStatusError{PendingTransferID: pending.ID, Status: pending.Status}
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.existingAccount(ctx, pending.ToAccountID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	arg := db.ApprovePendingTransferTxParams{
+		ID:           pending.ID,
+		ReviewedBy:   authPayload.Username,
+		ToAmount:     pending.Amount,
+		ExchangeRate: "1",
+	}
+	if toAccount.Currency != pending.Currency {
+		currency, enabled := server.currencies.Enabled(ctx, pending.Currency)
+		if !enabled {
+			err := fmt.Errorf("currency %s is not supported", pending.Currency)
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+
+		converted, valid := server.convertTransfer(ctx, pending.FromAccountID, toAccount, pending.Amount, currency)
+		if !valid {
+			return
+		}
+		arg.ToAmount = converted.ToAmount
+		arg.ExchangeRate = converted.ExchangeRate
+	}
+
+	result, err := server.store.ApprovePendingTransferTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrPendingTransferReviewed):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		case errors.Is(err, db.ErrTransferLimitExceeded):
+			ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(err))
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// rejectPendingTransfer closes the review of a transfer without making it
+func (server *Server) rejectPendingTransfer(ctx *gin.Context) {
+	var uri pendingTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	pending, valid := server.existingPendingTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if pending.Status != db.PendingTransferStatusPending {
+		err := &db.PendingTransferStatusError{PendingTransferID: pending.ID, Status: pending.Status}
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	rejected, err := server.store.ReviewPendingTransfer(ctx, db.ReviewPendingTransferParams{
+		ID:         pending.ID,
+		Status:     db.PendingTransferStatusRejected,
+		ReviewedBy: sql.NullString{String: authPayload.Username, Valid: true},
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			// approved or rejected by another admin in the meantime
+			ctx.JSON(http.StatusConflict, errorResponse(db.ErrPendingTransferReviewed))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rejected)
+}
+
+func (server *Server) existingPendingTransfer(ctx *gin.Context, id int64) (db.PendingTransfer, bool) {
+	pending, err := server.store.GetPendingTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return pending, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return pending, false
+	}
+	return pending, true
+}
@@ -0,0 +1,656 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// stubScreener returns the same decision for every transfer
+type stubScreener struct {
+	decision fraud.Decision
+	err      error
+}
+
+func (screener stubScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Decision, error) {
+	return screener.decision, screener.err
+}
+
+func (screener stubScreener) ScreenBatch(ctx context.Context, transfers []fraud.Transfer) ([]fraud.Decision, error) {
+	decisions := make([]fraud.Decision, len(transfers))
+	for i := range transfers {
+		decisions[i] = screener.decision
+	}
+	return decisions, screener.err
+}
+
+func randomPendingTransfer(from, to db.Account, requestedBy string) db.PendingTransfer {
+	return db.PendingTransfer{
+		ID:            7,
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        10,
+		Currency:      from.Currency,
+		RequestedBy:   requestedBy,
+		Status:        db.PendingTransferStatusPending,
+		Reasons:       json.RawMessage(`[{"rule":"fan-out","action":"review","message":"matched"}]`),
+	}
+}
+
+func TestCreateTransferScreeningApi(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account1.Currency = "USD"
+	account2 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+	account2.Currency = "USD"
+
+	reasons := []fraud.Reason{{Rule: "fan-out", Action: fraud.ActionReview, Message: "matched"}}
+	pending := randomPendingTransfer(account1, account2, user1.Username)
+
+	testCases := []struct {
+		name          string
+		screener      stubScreener
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Review",
+			screener: stubScreener{decision: fraud.Decision{Action: fraud.ActionReview, Reasons: reasons}},
+			buildStubs: func(store *mockdb.MockStore) {
+				reasonsJSON, err := json.Marshal(reasons)
+				require.NoError(t, err)
+
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Eq(db.CreatePendingTransferTxParams{
+					CreatePendingTransferParams: db.CreatePendingTransferParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        10,
+						Currency:      "USD",
+						RequestedBy:   user1.Username,
+						Reasons:       reasonsJSON,
+					},
+				})).Times(1).Return(pending, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got db.PendingTransfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, pending.ID, got.ID)
+				require.Equal(t, db.PendingTransferStatusPending, got.Status)
+			},
+		},
+		{
+			name:     "Block",
+			screener: stubScreener{decision: fraud.Decision{Action: fraud.ActionBlock, Reasons: reasons}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got struct {
+					Error   string         `json:"error"`
+					Reasons []fraud.Reason `json:"reasons"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, fraud.ErrTransferBlocked.Error(), got.Error)
+				require.Equal(t, reasons, got.Reasons)
+			},
+		},
+		{
+			name:     "Allow",
+			screener: stubScreener{decision: fraud.Decision{Action: fraud.ActionAllow}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "ScreeningError",
+			screener: stubScreener{err: sql.ErrConnDone},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+			server.screener = tc.screener
+
+			recorder := httptest.NewRecorder()
+
+			jsonStr := []byte(fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "currency": "USD", "amount": 10}`, account1.ID, account2.ID))
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBuffer(jsonStr))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestCreateTransferReviewIdempotency(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account1.Currency = "USD"
+	account2 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+	account2.Currency = "USD"
+
+	reasons := []fraud.Reason{{Rule: "fan-out", Action: fraud.ActionReview, Message: "matched"}}
+	pending := randomPendingTransfer(account1, account2, user1.Username)
+
+	key := utils.RandomString(16)
+	body := []byte(fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "currency": "USD", "amount": 10}`, account1.ID, account2.ID))
+	requestHash := hashRequest(http.MethodPost, "/transfers", body)
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "StoresResponse",
+			buildStubs: func(store *mockdb.MockStore) {
+				reasonsJSON, err := json.Marshal(reasons)
+				require.NoError(t, err)
+
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Eq(db.CreatePendingTransferTxParams{
+					CreatePendingTransferParams: db.CreatePendingTransferParams{
+						FromAccountID: account1.ID,
+						ToAccountID:   account2.ID,
+						Amount:        10,
+						Currency:      "USD",
+						RequestedBy:   user1.Username,
+						Reasons:       reasonsJSON,
+					},
+					Idempotency: &db.IdempotencyParams{
+						Username:     user1.Username,
+						Key:          key,
+						RequestHash:  requestHash,
+						ResponseCode: http.StatusAccepted,
+					},
+				})).Times(1).Return(pending, nil)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "ConcurrentRequest",
+			buildStubs: func(store *mockdb.MockStore) {
+				storedBody, err := json.Marshal(pending)
+				require.NoError(t, err)
+
+				gomock.InOrder(
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{}, sql.ErrNoRows),
+					store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{
+						Username:     user1.Username,
+						Key:          key,
+						RequestHash:  requestHash,
+						ResponseCode: http.StatusAccepted,
+						ResponseBody: storedBody,
+					}, nil),
+				)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.PendingTransfer{}, db.ErrDuplicateIdempotencyKey)
+				store.EXPECT().CreateIdempotencyKey(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+				require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+
+				var got db.PendingTransfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, pending.ID, got.ID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+			store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+			store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+			server.screener = stubScreener{decision: fraud.Decision{Action: fraud.ActionReview, Reasons: reasons}}
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewReader(body))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/json")
+			request.Header.Set(idempotencyKeyHeader, key)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetPendingTransferApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account1 := randomAccount(user.Username)
+	account2 := randomAccount("other")
+	pending := randomPendingTransfer(account1, account2, user.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			username: "other",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/pending_transfers/%d", pending.ID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestListPendingTransfersApi(t *testing.T) {
+	user, _ := randomUser(t)
+	pending := []db.PendingTransfer{randomPendingTransfer(randomAccount(user.Username), randomAccount("other"), user.Username)}
+
+	testCases := []struct {
+		name          string
+		role          string
+		query         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			role:  roleAdmin,
+			query: "page_id=2&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Eq(db.ListPendingTransfersParams{
+					Status: db.PendingTransferStatusPending,
+					Limit:  5,
+					Offset: 5,
+				})).Times(1).Return(pending, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "Rejected",
+			role:  roleAdmin,
+			query: "page_id=1&page_size=5&status=rejected",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Eq(db.ListPendingTransfersParams{
+					Status: db.PendingTransferStatusRejected,
+					Limit:  5,
+				})).Times(1).Return([]db.PendingTransfer{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidStatus",
+			role:  roleAdmin,
+			query: "page_id=1&page_size=5&status=blocked",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "NotAdmin",
+			role:  roleDepositor,
+			query: "page_id=1&page_size=5",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListPendingTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/pending_transfers?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestApprovePendingTransferApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account1 := randomAccount(user.Username)
+	account1.Currency = "USD"
+	account2 := randomAccount("other")
+	account2.ID = account1.ID + 1
+	account2.Currency = "USD"
+	cadAccount := account2
+	cadAccount.Currency = "CAD"
+
+	pending := randomPendingTransfer(account1, account2, user.Username)
+	approved := pending
+	approved.Status = db.PendingTransferStatusApproved
+	approved.TransferID = sql.NullInt64{Int64: 1, Valid: true}
+	approved.ReviewedBy = sql.NullString{String: "admin", Valid: true}
+
+	testCases := []struct {
+		name          string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Eq(db.ApprovePendingTransferTxParams{
+					ID:           pending.ID,
+					ReviewedBy:   "admin",
+					ToAmount:     pending.Amount,
+					ExchangeRate: "1",
+				})).Times(1).Return(db.ApprovePendingTransferTxResult{PendingTransfer: approved}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.ApprovePendingTransferTxResult
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, approved.Status, got.PendingTransfer.Status)
+				require.Equal(t, approved.TransferID, got.PendingTransfer.TransferID)
+			},
+		},
+		{
+			name: "CrossCurrency",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(cadAccount, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{
+					BaseCurrency:  "USD",
+					QuoteCurrency: "CAD",
+				})).Times(1).Return(db.ExchangeRate{Rate: "1.3650000000"}, nil)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Eq(db.ApprovePendingTransferTxParams{
+					ID:           pending.ID,
+					ReviewedBy:   "admin",
+					ToAmount:     13,
+					ExchangeRate: "1.3650000000",
+				})).Times(1).Return(db.ApprovePendingTransferTxResult{PendingTransfer: approved}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "AlreadyReviewed",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(approved, nil)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ReviewedConcurrently",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ApprovePendingTransferTxResult{}, &db.PendingTransferStatusError{PendingTransferID: pending.ID, Status: db.PendingTransferStatusRejected})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ApprovePendingTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name: "NotFound",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().ApprovePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/pending_transfers/%d/approve", pending.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestRejectPendingTransferApi(t *testing.T) {
+	user, _ := randomUser(t)
+	pending := randomPendingTransfer(randomAccount(user.Username), randomAccount("other"), user.Username)
+	rejected := pending
+	rejected.Status = db.PendingTransferStatusRejected
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ReviewPendingTransfer(gomock.Any(), gomock.Eq(db.ReviewPendingTransferParams{
+					ID:         pending.ID,
+					Status:     db.PendingTransferStatusRejected,
+					ReviewedBy: sql.NullString{String: "admin", Valid: true},
+				})).Times(1).Return(rejected, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.PendingTransfer
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, db.PendingTransferStatusRejected, got.Status)
+			},
+		},
+		{
+			name: "AlreadyReviewed",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(rejected, nil)
+				store.EXPECT().ReviewPendingTransfer(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "ReviewedConcurrently",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetPendingTransfer(gomock.Any(), gomock.Eq(pending.ID)).Times(1).Return(pending, nil)
+				store.EXPECT().ReviewPendingTransfer(gomock.Any(), gomock.Any()).Times(1).Return(db.PendingTransfer{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, db.ErrPendingTransferReviewed.Error())
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, roleAdmin)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/pending_transfers/%d/reject", pending.ID)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
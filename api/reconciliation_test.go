@@ -0,0 +1,181 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateReconciliationApi(t *testing.T) {
+	report := db.ReconciliationReport{
+		RunID:         1,
+		Full:          true,
+		Discrepancies: 1,
+		AccountDrifts: []db.AccountDrift{
+			{AccountID: 1, Balance: 10, EntriesTotal: 5, Drift: 5},
+		},
+		TransferMismatches: []db.TransferMismatch{},
+		OrphanedEntries:    []db.OrphanedEntry{},
+	}
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Full",
+			role: roleAdmin,
+			body: `{"full": true}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any(), gomock.Eq(db.ReconcileParams{Full: true})).Times(1).Return(report, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got db.ReconciliationReport
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, report, got)
+			},
+		},
+		{
+			name: "Incremental",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any(), gomock.Eq(db.ReconcileParams{})).Times(1).Return(report, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "InternalError",
+			role: roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().Reconcile(gomock.Any(), gomock.Any()).Times(1).Return(db.ReconciliationReport{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/reconciliations", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestGetReconciliationApi(t *testing.T) {
+	run := db.ReconciliationRun{
+		ID:             1,
+		FullScan:       true,
+		LastTransferID: 10,
+		LastEntryID:    20,
+		Report:         json.RawMessage(`{"run_id":1}`),
+	}
+
+	testCases := []struct {
+		name          string
+		id            int64
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id:   run.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return(run, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got db.ReconciliationRun
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, run, got)
+			},
+		},
+		{
+			name: "NotFound",
+			id:   run.ID,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Eq(run.ID)).Times(1).Return(db.ReconciliationRun{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidID",
+			id:   0,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetReconciliationRun(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, roleAdmin)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/reconciliations/%d", tc.id)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
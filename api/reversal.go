@@ -0,0 +1,168 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+const (
+	reversalStatusNone    = "none"
+	reversalStatusPartial = "partial"
+	reversalStatusFull    = "full"
+)
+
+type reversalURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// reversalParams takes the amount in minor units of the original source currency. Without one, whatever
+// is left of the transfer is reversed.
+type reversalParams struct {
+	Amount int64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// createReversal sends a transfer back. Only the owner of the account that received it, or an admin, may do so.
+func (server *Server) createReversal(ctx *gin.Context) {
+	var uri reversalURI
+	var req reversalParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.existingAccount(ctx, transfer.ToAccountID)
+	if !valid {
+		return
+	}
+	fromAccount, valid := server.existingAccount(ctx, transfer.FromAccountID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if toAccount.Owner != authPayload.Username && ctx.GetString(authorizationRoleKey) != roleAdmin {
+		err := errors.New("only the owner of the receiving account can reverse a transfer")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+	if toAccount.Kind != db.AccountKindCustomer || fromAccount.Kind != db.AccountKindCustomer {
+		err := fmt.Errorf("transfer [%d] is a deposit or withdrawal and cannot be reversed", transfer.ID)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	arg := db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.Amount,
+	}
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		arg.Idempotency = idempotency.params(authPayload.Username, http.StatusCreated)
+	}
+
+	result, err := server.store.ReverseTransferTx(ctx, arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrTransferReversed):
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+		case errors.Is(err, db.ErrReverseReversal),
+			errors.Is(err, db.ErrReversalExceedsTransfer),
+			errors.Is(err, db.ErrReversalTooSmall),
+			errors.Is(err, db.ErrInsufficientFunds),
+			errors.Is(err, db.ErrAccountNotActive):
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+		case errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey:
+			server.replayConcurrentRequest(ctx, idempotency, authPayload.Username)
+		default:
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		}
+		return
+	}
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// transferResponse shows a transfer together with what has been reversed of it
+type transferResponse struct {
+	db.Transfer
+	ReversalStatus string        `json:"reversal_status"`
+	Reversals      []db.Transfer `json:"reversals"`
+}
+
+type getAccountTransferURI struct {
+	AccountID  int64 `uri:"id" binding:"required,min=1"`
+	TransferID int64 `uri:"transfer_id" binding:"required,min=1"`
+}
+
+func (server *Server) getAccountTransfer(ctx *gin.Context) {
+	var uri getAccountTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if _, valid := server.authorizedAccount(ctx, uri.AccountID); !valid {
+		return
+	}
+
+	transfer, err := server.store.GetTransfer(ctx, uri.TransferID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if transfer.FromAccountID != uri.AccountID && transfer.ToAccountID != uri.AccountID {
+		err := fmt.Errorf("transfer [%d] does not involve account [%d]", transfer.ID, uri.AccountID)
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+
+	reversals, err := server.store.ListTransferReversals(ctx, sql.NullInt64{Int64: transfer.ID, Valid: true})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	rsp := transferResponse{
+		Transfer:       transfer,
+		ReversalStatus: reversalStatusNone,
+		Reversals:      reversals,
+	}
+	switch {
+	case transfer.ReversedAmount == transfer.Amount:
+		rsp.ReversalStatus = reversalStatusFull
+	case transfer.ReversedAmount > 0:
+		rsp.ReversalStatus = reversalStatusPartial
+	}
+
+	ctx.JSON(http.StatusOK, rsp)
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"github.com/mrityunjaygr8/simplebank/utils"
+)
+
+type createScheduledTransferParams struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	amountParams
+	Currency string `json:"currency" binding:"required,currency"`
+	Schedule string `json:"schedule" binding:"required"`
+	// StartAt defaults to now, the first run is the first occurrence of the schedule after it
+	StartAt *time.Time `json:"start_at"`
+	EndAt   *time.Time `json:"end_at"`
+}
+
+func (server *Server) createScheduledTransfer(ctx *gin.Context) {
+	var req createScheduledTransferParams
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	schedule, err := utils.ParseSchedule(req.Schedule)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	start := time.Now()
+	if req.StartAt != nil && req.StartAt.After(start) {
+		start = *req.StartAt
+	}
+	nextRunAt := schedule.Next(start)
+	if req.EndAt != nil && req.EndAt.Before(nextRunAt) {
+		err := fmt.Errorf("end_at is before the first run at %s", nextRunAt.Format(time.RFC3339))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	_, amount, valid := server.parseAmount(ctx, req.amountParams, req.Currency)
+	if !valid {
+		return
+	}
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.validAccount(ctx, req.ToAccountID, req.Currency)
+	if !valid {
+		return
+	}
+	if toAccount.Kind != db.AccountKindCustomer {
+		err := fmt.Errorf("account [%d] cannot receive transfers", toAccount.ID)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateScheduledTransferTxParams{
+		CreateScheduledTransferParams: db.CreateScheduledTransferParams{
+			Owner:         authPayload.Username,
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+			Schedule:      req.Schedule,
+			NextRunAt:     nextRunAt,
+		},
+	}
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		arg.Idempotency = idempotency.params(authPayload.Username, http.StatusCreated)
+	}
+
+	scheduled, err := server.store.CreateScheduledTransferTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey {
+			server.replayConcurrentRequest(ctx, idempotency, authPayload.Username)
+			return
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "foreign_key_violation" {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
+
+	ctx.JSON(http.StatusCreated, scheduled)
+}
+
+type scheduledTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.authorizedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+type listScheduledTransfersParams struct {
+	paginationParams
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=10"`
+}
+
+func (server *Server) listScheduledTransfers(ctx *gin.Context) {
+	var req listScheduledTransfersParams
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if req.PageID != nil {
+		scheduled, err := server.store.ListScheduledTransfers(ctx, db.ListScheduledTransfersParams{
+			Owner:  authPayload.Username,
+			Limit:  req.PageSize,
+			Offset: req.offset(req.PageSize),
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, scheduled)
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, req.paginationParams)
+	if !valid {
+		return
+	}
+
+	scheduled, err := server.store.ListScheduledTransfersAfter(ctx, db.ListScheduledTransfersAfterParams{
+		Limit:          req.PageSize + 1,
+		Owner:          authPayload.Username,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(scheduled, req.PageSize, func(scheduled db.ScheduledTransfer) pageCursor {
+		return pageCursor{CreatedAt: scheduled.CreatedAt, ID: scheduled.ID}
+	}))
+}
+
+// updateScheduledTransferParams only changes the fields that are present. Amounts are in minor units.
+type updateScheduledTransferParams struct {
+	Amount   int64      `json:"amount" binding:"omitempty,gt=0"`
+	Schedule string     `json:"schedule"`
+	EndAt    *time.Time `json:"end_at"`
+	Paused   *bool      `json:"paused"`
+}
+
+func (server *Server) updateScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	var req updateScheduledTransferParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.authorizedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+	if scheduled.Status == db.ScheduledTransferStatusCompleted {
+		err := fmt.Errorf("scheduled transfer [%d] is completed", scheduled.ID)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		ID:                  scheduled.ID,
+		Amount:              scheduled.Amount,
+		Schedule:            scheduled.Schedule,
+		NextRunAt:           scheduled.NextRunAt,
+		EndAt:               scheduled.EndAt,
+		Status:              scheduled.Status,
+		ConsecutiveFailures: scheduled.ConsecutiveFailures,
+	}
+	if req.Amount != 0 {
+		arg.Amount = req.Amount
+	}
+	if req.EndAt != nil {
+		arg.EndAt = sql.NullTime{Time: *req.EndAt, Valid: true}
+	}
+	if req.Paused != nil {
+		arg.Status = db.ScheduledTransferStatusActive
+		if *req.Paused {
+			arg.Status = db.ScheduledTransferStatusPaused
+		}
+	}
+
+	// a new schedule, or resuming a paused one, starts counting from now
+	resumed := scheduled.Status == db.ScheduledTransferStatusPaused && arg.Status == db.ScheduledTransferStatusActive
+	if req.Schedule != "" || resumed {
+		if req.Schedule != "" {
+			arg.Schedule = req.Schedule
+		}
+		schedule, err := utils.ParseSchedule(arg.Schedule)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(err))
+			return
+		}
+		arg.NextRunAt = schedule.Next(time.Now())
+	}
+	if resumed {
+		arg.ConsecutiveFailures = 0
+	}
+	if arg.EndAt.Valid && arg.EndAt.Time.Before(arg.NextRunAt) {
+		err := fmt.Errorf("end_at is before the next run at %s", arg.NextRunAt.Format(time.RFC3339))
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, err := server.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, scheduled)
+}
+
+func (server *Server) deleteScheduledTransfer(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.authorizedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	err := server.store.DeleteScheduledTransfer(ctx, scheduled.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listScheduledTransferRunsParams struct {
+	paginationParams
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=10"`
+}
+
+func (server *Server) listScheduledTransferRuns(ctx *gin.Context) {
+	var uri scheduledTransferURI
+	var req listScheduledTransferRunsParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	scheduled, valid := server.authorizedScheduledTransfer(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if req.PageID != nil {
+		runs, err := server.store.ListScheduledTransferRuns(ctx, db.ListScheduledTransferRunsParams{
+			ScheduledTransferID: scheduled.ID,
+			Limit:               req.PageSize,
+			Offset:              req.offset(req.PageSize),
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, runs)
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, req.paginationParams)
+	if !valid {
+		return
+	}
+
+	runs, err := server.store.ListScheduledTransferRunsAfter(ctx, db.ListScheduledTransferRunsAfterParams{
+		Limit:               req.PageSize + 1,
+		ScheduledTransferID: scheduled.ID,
+		AfterCreatedAt:      cursor.CreatedAt,
+		AfterID:             cursor.ID,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newListPage(runs, req.PageSize, func(run db.ScheduledTransferRun) pageCursor {
+		return pageCursor{CreatedAt: run.CreatedAt, ID: run.ID}
+	}))
+}
+
+// authorizedScheduledTransfer fetches the scheduled transfer and makes sure it belongs to the authenticated user
+func (server *Server) authorizedScheduledTransfer(ctx *gin.Context, id int64) (db.ScheduledTransfer, bool) {
+	scheduled, err := server.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return scheduled, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return scheduled, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if scheduled.Owner != authPayload.Username {
+		err := errors.New("scheduled transfer doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return scheduled, false
+	}
+
+	return scheduled, true
+}
@@ -1,38 +1,147 @@
 package api
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/exchange"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"github.com/mrityunjaygr8/simplebank/utils"
 )
 
 type Server struct {
-	store  db.Store
-	router *gin.Engine
+	config     utils.Config
+	store      db.Store
+	tokenMaker token.Maker
+	screener   fraud.Screener
+	currencies *exchange.CurrencyRegistry
+	converter  *exchange.Converter
+	router     *gin.Engine
 }
 
-func NewServer(store db.Store) *Server {
-	server := &Server{store: store}
+func NewServer(config utils.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config.TokenType, config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	rates := exchange.NewDBRateProvider(store)
+	if config.ExchangeRatesFile != "" {
+		rates, err = exchange.NewFileRateProvider(config.ExchangeRatesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load exchange rates: %w", err)
+		}
+	}
+
+	var rules []fraud.Rule
+	if config.FraudRulesFile != "" {
+		rules, err = fraud.LoadRules(config.FraudRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load fraud rules: %w", err)
+		}
+	}
+
+	currencies := exchange.NewCurrencyRegistry(store)
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		screener:   fraud.NewEngine(store, rules...),
+		currencies: currencies,
+		converter:  exchange.NewConverter(currencies, rates),
+	}
 	router := gin.Default()
+	// lets the store read the audit context that auditMiddleware puts on the request
+	router.ContextWithFallback = true
+	router.Use(auditMiddleware())
 
+	activeCurrencies.Store(server.currencies)
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("webhook_url", validWebhookURL)
 	}
 
-	router.POST("/accounts", server.createAccount)
-	router.GET("/accounts/:id", server.getAccount)
-	router.GET("/accounts", server.listAccounts)
+	router.POST("/users", server.createUser)
+	router.POST("/users/login", server.loginUser)
+	router.POST("/tokens/renew_access", server.renewAccessToken)
+
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenMaker, server.store))
+	idempotent := idempotencyMiddleware(server.store)
+
+	authRoutes.PATCH("/users/:username/password", server.updateUserPassword)
+	authRoutes.POST("/sessions/:id/block", server.blockSession)
+
+	authRoutes.POST("/accounts", idempotent, server.createAccount)
+	authRoutes.GET("/accounts/:id", server.getAccount)
+	authRoutes.GET("/accounts/:id/balance", server.getAccountBalance)
+	authRoutes.GET("/accounts/:id/limits", server.getAccountLimits)
+	authRoutes.GET("/accounts", server.listAccounts)
+	authRoutes.POST("/accounts/:id/close", server.closeAccount)
+	authRoutes.POST("/accounts/:id/withdrawals", idempotent, server.createWithdrawal)
+	authRoutes.GET("/accounts/:id/transfers/:transfer_id", server.getAccountTransfer)
+
+	authRoutes.GET("/currencies", server.listCurrencies)
+
+	authRoutes.GET("/entries", server.listEntries)
+	authRoutes.GET("/entries/:account_id", server.listEntriesForAccount)
+
+	authRoutes.POST("/transfers", idempotent, server.createTransfer)
+	authRoutes.POST("/transfers/batch", idempotent, server.createBatchTransfer)
+	authRoutes.POST("/transfers/:id/reversal", idempotent, server.createReversal)
+	authRoutes.GET("/transfers", server.listTransfers)
+	authRoutes.GET("/transfers/:account_id", server.listTransfersForAccount)
+
+	authRoutes.GET("/pending_transfers/:id", server.getPendingTransfer)
+
+	authRoutes.POST("/scheduled_transfers", idempotent, server.createScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers", server.listScheduledTransfers)
+	authRoutes.GET("/scheduled_transfers/:id", server.getScheduledTransfer)
+	authRoutes.PATCH("/scheduled_transfers/:id", server.updateScheduledTransfer)
+	authRoutes.DELETE("/scheduled_transfers/:id", server.deleteScheduledTransfer)
+	authRoutes.GET("/scheduled_transfers/:id/runs", server.listScheduledTransferRuns)
+
+	authRoutes.POST("/webhooks", server.createWebhook)
+	authRoutes.GET("/webhooks", server.listWebhooks)
+	authRoutes.GET("/webhooks/:id", server.getWebhook)
+	authRoutes.PATCH("/webhooks/:id", server.updateWebhook)
+	authRoutes.DELETE("/webhooks/:id", server.deleteWebhook)
+	authRoutes.GET("/webhooks/:id/deliveries", server.listWebhookDeliveries)
+	authRoutes.POST("/webhooks/:id/deliveries/:delivery_id/retry", server.retryWebhookDelivery)
+
+	adminRoutes := router.Group("/admin").Use(authMiddleware(server.tokenMaker, server.store), roleMiddleware(roleAdmin))
+
+	adminRoutes.POST("/accounts/:id/freeze", server.freezeAccount)
+	adminRoutes.POST("/accounts/:id/unfreeze", server.unfreezeAccount)
+	adminRoutes.PUT("/accounts/:id/limits", server.setAccountLimits)
+	adminRoutes.DELETE("/accounts/:id/limits", server.deleteAccountLimits)
+	adminRoutes.POST("/accounts/:id/deposits", idempotent, server.createDeposit)
+
+	adminRoutes.GET("/tier_limits", server.listTierLimits)
+	adminRoutes.PUT("/tier_limits/:tier/:currency", server.setTierLimits)
+	adminRoutes.DELETE("/tier_limits/:tier/:currency", server.deleteTierLimits)
+
+	adminRoutes.PUT("/users/:username/tier", server.setUserTier)
+
+	adminRoutes.GET("/pending_transfers", server.listPendingTransfers)
+	adminRoutes.POST("/pending_transfers/:id/approve", server.approvePendingTransfer)
+	adminRoutes.POST("/pending_transfers/:id/reject", server.rejectPendingTransfer)
+
+	adminRoutes.POST("/currencies", server.createCurrency)
+	adminRoutes.PATCH("/currencies/:code", server.updateCurrency)
 
-	router.GET("/entries", server.listEntries)
-	router.GET("/entries/:account_id", server.listEntriesForAccount)
+	adminRoutes.GET("/audit_events", server.listAuditEvents)
+	adminRoutes.GET("/audit_events/verify", server.verifyAuditEvents)
 
-	router.POST("/transfers", server.createTransfer)
-	router.GET("/transfers", server.listTransfers)
-	router.GET("/transfers/:account_id", server.listTransfersForAccount)
+	adminRoutes.POST("/reconciliations", server.createReconciliation)
+	adminRoutes.GET("/reconciliations", server.listReconciliations)
+	adminRoutes.GET("/reconciliations/:id", server.getReconciliation)
 
 	server.router = router
-	return server
+	return server, nil
 }
 
 func (server *Server) Start(address string) error {
@@ -2,18 +2,21 @@ package api
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/exchange"
+	"github.com/mrityunjaygr8/simplebank/token"
 )
 
 type transferRequestParams struct {
-	FromAccountID int64  `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID   int64  `json:"to_account_id" binding:"required,min=1"`
-	Amount        int64  `json:"amount" binding:"required,gt=0"`
-	Currency      string `json:"currency" binding:"required,currency"`
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	ToAccountID   int64 `json:"to_account_id" binding:"required,min=1,nefield=FromAccountID"`
+	amountParams
+	Currency string `json:"currency" binding:"required,currency"`
 }
 
 func (server *Server) createTransfer(ctx *gin.Context) {
@@ -23,31 +26,89 @@ func (server *Server) createTransfer(ctx *gin.Context) {
 		return
 	}
 
-	if !server.validAccount(ctx, req.FromAccountID, req.Currency) {
+	currency, amount, valid := server.parseAmount(ctx, req.amountParams, req.Currency)
+	if !valid {
 		return
 	}
-	if !server.validAccount(ctx, req.ToAccountID, req.Currency) {
+
+	fromAccount, valid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if fromAccount.Owner != authPayload.Username {
+		err := errors.New("from account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	toAccount, valid := server.existingAccount(ctx, req.ToAccountID)
+	if !valid {
+		return
+	}
+	if toAccount.Kind != db.AccountKindCustomer {
+		err := fmt.Errorf("account [%d] cannot receive transfers", toAccount.ID)
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
 		return
 	}
 
-	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID:   req.ToAccountID,
-		Amount:        req.Amount,
+	if !server.screenTransfer(ctx, req, amount, authPayload.Username) {
+		return
+	}
+
+	var idempotencyParams *db.IdempotencyParams
+	idempotency, hasIdempotencyKey := idempotencyFromContext(ctx)
+	if hasIdempotencyKey {
+		idempotencyParams = idempotency.params(authPayload.Username, http.StatusCreated)
 	}
 
-	result, err := server.store.TransferTx(ctx, arg)
+	var result db.TransferTxResult
+	var err error
+	if toAccount.Currency == fromAccount.Currency {
+		result, err = server.store.TransferTx(ctx, db.TransferTxParams{
+			FromAccountID: req.FromAccountID,
+			ToAccountID:   req.ToAccountID,
+			Amount:        amount,
+			Idempotency:   idempotencyParams,
+		})
+	} else {
+		arg, valid := server.convertTransfer(ctx, req.FromAccountID, toAccount, amount, currency)
+		if !valid {
+			return
+		}
+		arg.Idempotency = idempotencyParams
+		result, err = server.store.CrossCurrencyTransferTx(ctx, arg)
+	}
 	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountNotActive) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrTransferLimitExceeded) {
+			ctx.JSON(http.StatusUnprocessableEntity, limitExceededResponse(err))
+			return
+		}
+		if errors.Is(err, db.ErrDuplicateIdempotencyKey) && hasIdempotencyKey {
+			server.replayConcurrentRequest(ctx, idempotency, authPayload.Username)
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
+	if hasIdempotencyKey {
+		idempotency.stored = true
+	}
 
 	ctx.JSON(http.StatusCreated, result)
 }
 
 type listTransfersParams struct {
+	paginationParams
+	listFilterParams
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	// AccountID limits the list to the transfers of one account, a direction only makes sense with it
+	AccountID int64 `form:"account_id" binding:"required_with=Direction,omitempty,min=1"`
 }
 
 func (server *Server) listTransfers(ctx *gin.Context) {
@@ -58,23 +119,62 @@ func (server *Server) listTransfers(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListTransfersParams{
-		Limit:  req.PageSize,
-		Offset: (req.PageID - 1) * req.PageSize,
+	filtered := req.AccountID != 0 || req.listFilterParams.active()
+	if req.PageID != nil && filtered {
+		ctx.JSON(http.StatusBadRequest, errorResponse(errFiltersWithPageID))
+		return
+	}
+	if !server.authorizedSearch(ctx, req.AccountID) {
+		return
 	}
 
-	transfers, err := server.store.ListTransfers(ctx, arg)
+	if req.PageID != nil {
+		arg := db.ListTransfersParams{
+			Limit:  req.PageSize,
+			Offset: req.offset(req.PageSize),
+		}
+
+		transfers, err := server.store.ListTransfers(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, transfers)
+		return
+	}
+
+	if filtered {
+		server.searchTransfers(ctx, req.listFilterParams, req.AccountID, req.paginationParams, req.PageSize)
+		return
+	}
+
+	cursor, valid := parseCursor(ctx, req.paginationParams)
+	if !valid {
+		return
+	}
+
+	transfers, err := server.store.ListTransfersAfter(ctx, db.ListTransfersAfterParams{
+		Limit:          req.PageSize + 1,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transfers)
+	ctx.JSON(http.StatusOK, newListPage(transfers, req.PageSize, transferCursor))
+}
+
+func transferCursor(transfer db.Transfer) pageCursor {
+	return pageCursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
 }
 
 type listTransfersForAccountParams struct {
+	paginationParams
+	listFilterParams
 	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
-	PageID   int32 `form:"page_id" binding:"required,min=1"`
 }
 type listTransfersForAccountURI struct {
 	AccountID int64 `uri:"account_id" binding:"required,min=1"`
@@ -93,37 +193,103 @@ func (server *Server) listTransfersForAccount(ctx *gin.Context) {
 		return
 	}
 
-	arg := db.ListTransfersForAccountParams{
-		Limit:     qp.PageSize,
-		Offset:    (qp.PageID - 1) * qp.PageSize,
-		AccountID: req.AccountID,
+	if _, valid := server.authorizedAccount(ctx, req.AccountID); !valid {
+		return
+	}
+
+	if qp.PageID != nil {
+		if qp.listFilterParams.active() {
+			ctx.JSON(http.StatusBadRequest, errorResponse(errFiltersWithPageID))
+			return
+		}
+
+		arg := db.ListTransfersForAccountParams{
+			Limit:     qp.PageSize,
+			Offset:    qp.offset(qp.PageSize),
+			AccountID: req.AccountID,
+		}
+
+		transfers, err := server.store.ListTransfersForAccount(ctx, arg)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, transfers)
+		return
+	}
+
+	if qp.listFilterParams.active() {
+		server.searchTransfers(ctx, qp.listFilterParams, req.AccountID, qp.paginationParams, qp.PageSize)
+		return
 	}
 
-	transfers, err := server.store.ListTransfersForAccount(ctx, arg)
+	cursor, valid := parseCursor(ctx, qp.paginationParams)
+	if !valid {
+		return
+	}
+
+	transfers, err := server.store.ListTransfersForAccountAfter(ctx, db.ListTransfersForAccountAfterParams{
+		Limit:          qp.PageSize + 1,
+		AccountID:      req.AccountID,
+		AfterCreatedAt: cursor.CreatedAt,
+		AfterID:        cursor.ID,
+	})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	ctx.JSON(http.StatusOK, transfers)
+	ctx.JSON(http.StatusOK, newListPage(transfers, qp.PageSize, transferCursor))
 }
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) bool {
+
+// convertTransfer works out a transfer to an account holding another currency. When it cannot be made the
+// response has been written and false is returned.
+func (server *Server) convertTransfer(ctx *gin.Context, fromAccountID int64, toAccount db.Account, amount int64, fromCurrency db.Currency) (db.CrossCurrencyTransferTxParams, bool) {
+	arg, err := server.converter.Transfer(ctx, fromAccountID, toAccount, amount, fromCurrency)
+	if err != nil {
+		if errors.Is(err, exchange.ErrCannotConvert) {
+			ctx.JSON(http.StatusUnprocessableEntity, errorResponse(err))
+			return arg, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return arg, false
+	}
+	return arg, true
+}
+
+func (server *Server) existingAccount(ctx *gin.Context, accountID int64) (db.Account, bool) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return account, false
+		}
+
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return account, false
+	}
+
+	return account, true
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return false
+			return account, false
 		}
 
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return false
+		return account, false
 	}
 
 	if account.Currency != currency {
 		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", accountID, account.Currency, currency)
 		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return false
+		return account, false
 	}
 
-	return true
+	return account, true
 }
@@ -0,0 +1,317 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+// limitExceededResponse adds the limit a transfer ran into to the error, so clients can tell how much is left
+func limitExceededResponse(err error) gin.H {
+	response := errorResponse(err)
+
+	var limitErr *db.TransferLimitError
+	if errors.As(err, &limitErr) {
+		response["limit"] = limitErr
+	}
+	return response
+}
+
+// transferLimits are the velocity limits of an account or a tier, a limit that is left out is not enforced.
+// Amounts are in minor units of the account currency.
+type transferLimits struct {
+	MaxAmount        *int64 `json:"max_amount" binding:"omitempty,min=1"`
+	MaxDailyAmount   *int64 `json:"max_daily_amount" binding:"omitempty,min=1"`
+	MaxMonthlyAmount *int64 `json:"max_monthly_amount" binding:"omitempty,min=1"`
+	MaxDailyCount    *int32 `json:"max_daily_count" binding:"omitempty,min=1"`
+}
+
+func newTransferLimits(maxAmount, maxDailyAmount, maxMonthlyAmount sql.NullInt64, maxDailyCount sql.NullInt32) transferLimits {
+	var limits transferLimits
+	if maxAmount.Valid {
+		limits.MaxAmount = &maxAmount.Int64
+	}
+	if maxDailyAmount.Valid {
+		limits.MaxDailyAmount = &maxDailyAmount.Int64
+	}
+	if maxMonthlyAmount.Valid {
+		limits.MaxMonthlyAmount = &maxMonthlyAmount.Int64
+	}
+	if maxDailyCount.Valid {
+		limits.MaxDailyCount = &maxDailyCount.Int32
+	}
+	return limits
+}
+
+func (limits transferLimits) maxAmount() sql.NullInt64 {
+	return nullInt64Ptr(limits.MaxAmount)
+}
+
+func (limits transferLimits) maxDailyAmount() sql.NullInt64 {
+	return nullInt64Ptr(limits.MaxDailyAmount)
+}
+
+func (limits transferLimits) maxMonthlyAmount() sql.NullInt64 {
+	return nullInt64Ptr(limits.MaxMonthlyAmount)
+}
+
+func (limits transferLimits) maxDailyCount() sql.NullInt32 {
+	if limits.MaxDailyCount == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *limits.MaxDailyCount, Valid: true}
+}
+
+func nullInt64Ptr(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+type accountLimitsResponse struct {
+	AccountID int64  `json:"account_id"`
+	Currency  string `json:"currency"`
+	transferLimits
+	// what the account has used up since midnight UTC and since the start of the month
+	DailyAmount   int64 `json:"daily_amount"`
+	DailyCount    int64 `json:"daily_count"`
+	MonthlyAmount int64 `json:"monthly_amount"`
+}
+
+// getAccountLimits returns the limits that apply to an account, its own where set and its owner's tier
+// otherwise, together with what it has used of them. It is open to the owner and to admins.
+func (server *Server) getAccountLimits(ctx *gin.Context) {
+	var req getAccountParams
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.existingAccount(ctx, req.ID)
+	if !valid {
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if account.Owner != authPayload.Username && ctx.GetString(authorizationRoleKey) != roleAdmin {
+		err := errors.New("account doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return
+	}
+
+	limit, err := server.store.GetEffectiveTransferLimit(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	usage, err := server.store.GetTransferLimitUsage(ctx, account.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountLimitsResponse{
+		AccountID:      account.ID,
+		Currency:       account.Currency,
+		transferLimits: newTransferLimits(limit.MaxAmount, limit.MaxDailyAmount, limit.MaxMonthlyAmount, limit.MaxDailyCount),
+		DailyAmount:    usage.DailyAmount,
+		DailyCount:     usage.DailyCount,
+		MonthlyAmount:  usage.MonthlyAmount,
+	})
+}
+
+type accountTransferLimitResponse struct {
+	AccountID int64 `json:"account_id"`
+	transferLimits
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// setAccountLimits replaces the limits of an account. They take precedence over the limits of the owner's tier.
+func (server *Server) setAccountLimits(ctx *gin.Context) {
+	var uri getAccountParams
+	var req transferLimits
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, valid := server.existingAccount(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	limit, err := server.store.UpsertAccountTransferLimit(ctx, db.UpsertAccountTransferLimitParams{
+		AccountID:        account.ID,
+		MaxAmount:        req.maxAmount(),
+		MaxDailyAmount:   req.maxDailyAmount(),
+		MaxMonthlyAmount: req.maxMonthlyAmount(),
+		MaxDailyCount:    req.maxDailyCount(),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accountTransferLimitResponse{
+		AccountID:      limit.AccountID,
+		transferLimits: newTransferLimits(limit.MaxAmount, limit.MaxDailyAmount, limit.MaxMonthlyAmount, limit.MaxDailyCount),
+		UpdatedAt:      limit.UpdatedAt,
+	})
+}
+
+// deleteAccountLimits drops the limits of an account, so only those of the owner's tier apply
+func (server *Server) deleteAccountLimits(ctx *gin.Context) {
+	var req getAccountParams
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteAccountTransferLimit(ctx, req.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type tierTransferLimitResponse struct {
+	Tier     string `json:"tier"`
+	Currency string `json:"currency"`
+	transferLimits
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newTierTransferLimitResponse(limit db.TierTransferLimit) tierTransferLimitResponse {
+	return tierTransferLimitResponse{
+		Tier:           limit.Tier,
+		Currency:       limit.Currency,
+		transferLimits: newTransferLimits(limit.MaxAmount, limit.MaxDailyAmount, limit.MaxMonthlyAmount, limit.MaxDailyCount),
+		UpdatedAt:      limit.UpdatedAt,
+	}
+}
+
+func (server *Server) listTierLimits(ctx *gin.Context) {
+	limits, err := server.store.ListTierTransferLimits(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]tierTransferLimitResponse, len(limits))
+	for i, limit := range limits {
+		response[i] = newTierTransferLimitResponse(limit)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+type tierLimitURI struct {
+	Tier     string `uri:"tier" binding:"required,max=32"`
+	Currency string `uri:"currency" binding:"required,len=3"`
+}
+
+// setTierLimits replaces the limits of the accounts in a currency of every user in a tier
+func (server *Server) setTierLimits(ctx *gin.Context) {
+	var uri tierLimitURI
+	var req transferLimits
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	limit, err := server.store.UpsertTierTransferLimit(ctx, db.UpsertTierTransferLimitParams{
+		Tier:             uri.Tier,
+		Currency:         uri.Currency,
+		MaxAmount:        req.maxAmount(),
+		MaxDailyAmount:   req.maxDailyAmount(),
+		MaxMonthlyAmount: req.maxMonthlyAmount(),
+		MaxDailyCount:    req.maxDailyCount(),
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				ctx.JSON(http.StatusNotFound, errorResponse(err))
+				return
+			}
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTierTransferLimitResponse(limit))
+}
+
+func (server *Server) deleteTierLimits(ctx *gin.Context) {
+	var uri tierLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	err := server.store.DeleteTierTransferLimit(ctx, db.DeleteTierTransferLimitParams{
+		Tier:     uri.Tier,
+		Currency: uri.Currency,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type userTierURI struct {
+	Username string `uri:"username" binding:"required,alphanum"`
+}
+
+type userTierParams struct {
+	Tier string `json:"tier" binding:"required,max=32"`
+}
+
+// setUserTier moves a user to another tier, which changes the limits of all of their accounts
+func (server *Server) setUserTier(ctx *gin.Context) {
+	var uri userTierURI
+	var req userTierParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	user, err := server.store.UpdateUserTier(ctx, db.UpdateUserTierParams{
+		Username: uri.Username,
+		Tier:     req.Tier,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
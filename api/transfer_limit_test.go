@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountLimitsApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	limit := db.GetEffectiveTransferLimitRow{
+		MaxAmount:     sql.NullInt64{Int64: 500, Valid: true},
+		MaxDailyCount: sql.NullInt32{Int32: 10, Valid: true},
+	}
+	usage := db.GetTransferLimitUsageRow{DailyAmount: 200, DailyCount: 2, MonthlyAmount: 900}
+
+	testCases := []struct {
+		name          string
+		username      string
+		role          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "Owner",
+			username: user.Username,
+			role:     roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetEffectiveTransferLimit(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(limit, nil)
+				store.EXPECT().GetTransferLimitUsage(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(usage, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, float64(500), got["max_amount"])
+				require.Nil(t, got["max_daily_amount"])
+				require.Equal(t, float64(10), got["max_daily_count"])
+				require.Equal(t, float64(200), got["daily_amount"])
+				require.Equal(t, float64(900), got["monthly_amount"])
+			},
+		},
+		{
+			name:     "Admin",
+			username: "admin",
+			role:     roleAdmin,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetEffectiveTransferLimit(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(limit, nil)
+				store.EXPECT().GetTransferLimitUsage(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(usage, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "OtherUser",
+			username: "other",
+			role:     roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetEffectiveTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "InternalError",
+			username: user.Username,
+			role:     roleDepositor,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetEffectiveTransferLimit(gomock.Any(), gomock.Any()).Times(1).Return(db.GetEffectiveTransferLimitRow{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/limits", account.ID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetAccountLimitsApi(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		role          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			role: roleAdmin,
+			body: `{"max_daily_amount": 100000, "max_daily_count": 20}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertAccountTransferLimitParams{
+					AccountID:      account.ID,
+					MaxDailyAmount: sql.NullInt64{Int64: 100000, Valid: true},
+					MaxDailyCount:  sql.NullInt32{Int32: 20, Valid: true},
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.AccountTransferLimit{
+					AccountID:      arg.AccountID,
+					MaxDailyAmount: arg.MaxDailyAmount,
+					MaxDailyCount:  arg.MaxDailyCount,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, float64(account.ID), got["account_id"])
+				require.Equal(t, float64(100000), got["max_daily_amount"])
+				require.Nil(t, got["max_amount"])
+			},
+		},
+		{
+			name: "InvalidLimit",
+			role: roleAdmin,
+			body: `{"max_amount": 0}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotAdmin",
+			role: roleDepositor,
+			body: `{"max_amount": 100}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			role: roleAdmin,
+			body: `{"max_amount": 100}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/accounts/%d/limits", account.ID)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetTierLimitsApi(t *testing.T) {
+	testCases := []struct {
+		name          string
+		url           string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			url:  "/admin/tier_limits/premium/USD",
+			body: `{"max_monthly_amount": 5000000}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.UpsertTierTransferLimitParams{
+					Tier:             "premium",
+					Currency:         "USD",
+					MaxMonthlyAmount: sql.NullInt64{Int64: 5000000, Valid: true},
+				}
+				store.EXPECT().UpsertTierTransferLimit(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TierTransferLimit{
+					Tier:             arg.Tier,
+					Currency:         arg.Currency,
+					MaxMonthlyAmount: arg.MaxMonthlyAmount,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got map[string]interface{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "premium", got["tier"])
+				require.Equal(t, float64(5000000), got["max_monthly_amount"])
+			},
+		},
+		{
+			name: "UnknownCurrency",
+			url:  "/admin/tier_limits/premium/XYZ",
+			body: `{"max_amount": 100}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierTransferLimit(gomock.Any(), gomock.Any()).Times(1).Return(db.TierTransferLimit{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "InvalidCurrency",
+			url:  "/admin/tier_limits/premium/DOLLARS",
+			body: `{"max_amount": 100}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpsertTierTransferLimit(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, roleAdmin)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPut, tc.url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetUserTierApi(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: `{"tier": "premium"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := user
+				updated.Tier = "premium"
+				store.EXPECT().UpdateUserTier(gomock.Any(), gomock.Eq(db.UpdateUserTierParams{
+					Username: user.Username,
+					Tier:     "premium",
+				})).Times(1).Return(updated, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got userResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Equal(t, "premium", got.Tier)
+			},
+		},
+		{
+			name: "MissingTier",
+			body: `{}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTier(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "UserNotFound",
+			body: `{"tier": "premium"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTier(gomock.Any(), gomock.Any()).Times(1).Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUserWithRole(store, roleAdmin)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/users/%s/tier", user.Username)
+			request, err := http.NewRequest(http.MethodPut, url, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "admin", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
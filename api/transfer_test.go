@@ -9,10 +9,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
 	"github.com/mrityunjaygr8/simplebank/utils"
 	"github.com/stretchr/testify/require"
 )
@@ -27,9 +29,13 @@ func randomTransfer() db.Transfer {
 
 }
 func TestCreateTransferApi(t *testing.T) {
-	account1 := randomAccount()
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
 	account1.Currency = "USD"
-	account2 := randomAccount()
+	account2 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
 	account2.Currency = "USD"
 	amount := int64(10)
 
@@ -66,6 +72,15 @@ func TestCreateTransferApi(t *testing.T) {
 		},
 	}
 
+	cadAccount := account2
+	cadAccount.Currency = "CAD"
+	crossCurrencyResponse := transferResponse
+	crossCurrencyResponse.Transfer.ToAmount = 13
+	crossCurrencyResponse.Transfer.ExchangeRate = "1.3650000000"
+	crossCurrencyResponse.ToAccount.Currency = "CAD"
+	crossCurrencyResponse.ToAccount.Balance = account2.Balance + 13
+	crossCurrencyResponse.ToEntry.Amount = 13
+
 	testCases := []struct {
 		name             string
 		account1         db.Account
@@ -74,6 +89,7 @@ func TestCreateTransferApi(t *testing.T) {
 		currency         string
 		transferParams   db.TransferTxParams
 		transferResponse db.TransferTxResult
+		setupAuth        func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		buildStubs       func(store *mockdb.MockStore)
 		checkResponse    func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -84,6 +100,9 @@ func TestCreateTransferApi(t *testing.T) {
 			amount:           amount,
 			currency:         "USD",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
@@ -105,6 +124,9 @@ func TestCreateTransferApi(t *testing.T) {
 			amount:           amount,
 			currency:         "CAD",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -122,6 +144,9 @@ func TestCreateTransferApi(t *testing.T) {
 			amount:           amount,
 			currency:         "USD",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
@@ -139,18 +164,96 @@ func TestCreateTransferApi(t *testing.T) {
 		{
 			name:             "crossed-currency",
 			account1:         account1,
-			account2:         db.Account{ID: account2.ID, Owner: account2.Owner, Balance: account2.Balance, CreatedAt: account2.CreatedAt, Currency: "CAD"},
+			account2:         cadAccount,
 			amount:           amount,
 			currency:         "USD",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
-				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(db.Account{ID: account2.ID, Owner: account2.Owner, Balance: account2.Balance, CreatedAt: account2.CreatedAt, Currency: "CAD"}, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(cadAccount, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{
+					BaseCurrency:  "USD",
+					QuoteCurrency: "CAD",
+				})).Times(1).Return(db.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "CAD", Rate: "1.3650000000"}, nil)
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Eq(db.CrossCurrencyTransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+					ToAmount:      13,
+					ExchangeRate:  "1.3650000000",
+				})).Times(1).Return(crossCurrencyResponse, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
-				requireBodyMatchError(t, recorder.Body, fmt.Sprintf("account [%d] currency mismatch: %s vs %s", account2.ID, "CAD", "USD"))
+				require.Equal(t, http.StatusCreated, recorder.Code)
+				requireBodyMatchCreateTransfer(t, recorder.Body, crossCurrencyResponse)
+			},
+		},
+		{
+			name:             "crossed-currency-no-rate",
+			account1:         account1,
+			account2:         cadAccount,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(cadAccount, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, "no exchange rate from USD to CAD")
+			},
+		},
+		{
+			name:             "crossed-currency-too-small",
+			account1:         account1,
+			account2:         cadAccount,
+			amount:           1,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(cadAccount, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).Times(1).Return(db.ExchangeRate{Rate: "0.5000000000"}, nil)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+			},
+		},
+		{
+			name:             "ToCashAccount",
+			account1:         account1,
+			account2:         account2,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				cashAccount := account2
+				cashAccount.Owner = db.SystemUsername
+				cashAccount.Kind = db.AccountKindCash
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(cashAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
 			},
 		},
 		{
@@ -160,6 +263,9 @@ func TestCreateTransferApi(t *testing.T) {
 			amount:           amount,
 			currency:         "USD",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -176,6 +282,9 @@ func TestCreateTransferApi(t *testing.T) {
 			amount:           amount,
 			currency:         "USD",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrConnDone)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -192,6 +301,9 @@ func TestCreateTransferApi(t *testing.T) {
 			amount:           amount,
 			currency:         "INR",
 			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
 				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
@@ -202,6 +314,116 @@ func TestCreateTransferApi(t *testing.T) {
 				requireBodyMatchError(t, recorder.Body, fmt.Sprintf("Key: 'transferRequestParams.Currency' Error:Field validation for 'Currency' failed on the 'currency' tag"))
 			},
 		},
+		{
+			name:             "InsufficientFunds",
+			account1:         account1,
+			account2:         account2,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, db.ErrInsufficientFunds.Error())
+			},
+		},
+		{
+			name:             "TransferLimitExceeded",
+			account1:         account1,
+			account2:         account2,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				limitErr := &db.TransferLimitError{
+					AccountID: account1.ID,
+					Limit:     db.TransferLimitDailyAmount,
+					Max:       1000,
+					Used:      990,
+					Requested: amount,
+				}
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, limitErr)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+				var got struct {
+					Error string                `json:"error"`
+					Limit db.TransferLimitError `json:"limit"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &got))
+				require.Contains(t, got.Error, db.TransferLimitDailyAmount)
+				require.Equal(t, db.TransferLimitDailyAmount, got.Limit.Limit)
+				require.Equal(t, int64(1000), got.Limit.Max)
+				require.Equal(t, int64(990), got.Limit.Used)
+			},
+		},
+		{
+			name:             "SameAccount",
+			account1:         account1,
+			account2:         account1,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:             "UnauthorizedUser",
+			account1:         account1,
+			account2:         account2,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+				addAuthorization(t, request, tokenMaker, authorizationTypeBearer, user2.Username, time.Minute)
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:             "NoAuthorization",
+			account1:         account1,
+			account2:         account2,
+			amount:           amount,
+			currency:         "USD",
+			transferResponse: transferResponse,
+			setupAuth: func(t *testing.T, request *http.Request, tokenMaker token.Maker) {
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
 	}
 
 	for i := range testCases {
@@ -212,7 +434,8 @@ func TestCreateTransferApi(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -221,11 +444,111 @@ func TestCreateTransferApi(t *testing.T) {
 			request, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonStr))
 			require.NoError(t, err)
 
+			tc.setupAuth(t, request, server.tokenMaker)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
 	}
 }
+func TestCreateTransferDecimalAmount(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account1.Currency = "USD"
+	account2 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+	account2.Currency = "USD"
+
+	testCases := []struct {
+		name          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: `"decimal_amount": "12.30"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        1230,
+				})).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "TooPrecise",
+			body: `"decimal_amount": "12.345"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NotANumber",
+			body: `"decimal_amount": "12,30"`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BothAmounts",
+			body: `"decimal_amount": "12.30", "amount": 1230`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "NoAmount",
+			body: `"decimal_amount": ""`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
+
+			recorder := httptest.NewRecorder()
+
+			jsonStr := []byte(fmt.Sprintf(`{"from_account_id": %d, "to_account_id": %d, "currency": "USD", %s}`, account1.ID, account2.ID, tc.body))
+			request, err := http.NewRequest(http.MethodPost, "/transfers", bytes.NewBuffer(jsonStr))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user1.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func requireBodyMatchCreateTransfer(t *testing.T, body *bytes.Buffer, transferResult db.TransferTxResult) {
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
@@ -251,6 +574,8 @@ func requireBodyMatchError(t *testing.T, body *bytes.Buffer, expected string) {
 	require.Equal(t, expected, gotError.Error)
 }
 func TestListTransfers(t *testing.T) {
+	user, _ := randomUser(t)
+
 	var transfers []db.Transfer
 	for x := 0; x < 10; x++ {
 		transfers = append(transfers, randomTransfer())
@@ -259,6 +584,7 @@ func TestListTransfers(t *testing.T) {
 
 	testCases := []struct {
 		name          string
+		role          string
 		page_size     int32
 		page_id       int32
 		buildStubs    func(store *mockdb.MockStore)
@@ -266,6 +592,7 @@ func TestListTransfers(t *testing.T) {
 	}{
 		{
 			name:      "OK",
+			role:      roleAdmin,
 			page_size: 5,
 			page_id:   1,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -281,6 +608,7 @@ func TestListTransfers(t *testing.T) {
 		},
 		{
 			name:      "Zero-Page",
+			role:      roleAdmin,
 			page_size: 5,
 			page_id:   0,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -292,6 +620,7 @@ func TestListTransfers(t *testing.T) {
 		},
 		{
 			name:      "Big-Page-Size",
+			role:      roleAdmin,
 			page_size: 50,
 			page_id:   0,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -303,6 +632,7 @@ func TestListTransfers(t *testing.T) {
 		},
 		{
 			name:      "Internal-error",
+			role:      roleAdmin,
 			page_size: 5,
 			page_id:   1,
 			buildStubs: func(store *mockdb.MockStore) {
@@ -315,6 +645,19 @@ func TestListTransfers(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name:      "NotAdmin",
+			role:      roleDepositor,
+			page_size: 5,
+			page_id:   1,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireBodyMatchError(t, recorder.Body, errSearchAllAccounts.Error())
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
@@ -324,7 +667,8 @@ func TestListTransfers(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUserWithRole(store, tc.role)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -332,6 +676,7 @@ func TestListTransfers(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -350,6 +695,10 @@ func requireBodyMatchListTransfer(t *testing.T, body *bytes.Buffer, transfers []
 	require.Equal(t, transfers, gotTransfers)
 }
 func TestListTransfersForAccount(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.ID = 1
+
 	var transfers []db.Transfer
 	for x := 0; x < 10; x++ {
 		transfer := randomTransfer()
@@ -371,6 +720,7 @@ func TestListTransfersForAccount(t *testing.T) {
 			page_id:    1,
 			account_id: 1,
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 				store.EXPECT().ListTransfersForAccount(gomock.Any(), gomock.Eq(db.ListTransfersForAccountParams{
 					Limit:     5,
 					Offset:    0,
@@ -412,6 +762,7 @@ func TestListTransfersForAccount(t *testing.T) {
 			page_id:    1,
 			account_id: 1,
 			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
 				store.EXPECT().ListTransfersForAccount(gomock.Any(), gomock.Eq(db.ListTransfersForAccountParams{
 					Limit:     5,
 					Offset:    0,
@@ -434,6 +785,21 @@ func TestListTransfersForAccount(t *testing.T) {
 				require.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:       "Unauthorized-User",
+			page_size:  5,
+			page_id:    1,
+			account_id: 1,
+			buildStubs: func(store *mockdb.MockStore) {
+				otherAccount := randomAccount("other_user")
+				otherAccount.ID = 1
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(otherAccount.ID)).Times(1).Return(otherAccount, nil)
+				store.EXPECT().ListTransfersForAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 	}
 	for i := range testCases {
 		tc := testCases[i]
@@ -443,7 +809,8 @@ func TestListTransfersForAccount(t *testing.T) {
 
 			store := mockdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
-			server := NewServer(store)
+			stubAuthUser(store)
+			server := newTestServer(t, store)
 
 			recorder := httptest.NewRecorder()
 
@@ -451,6 +818,7 @@ func TestListTransfersForAccount(t *testing.T) {
 			request, err := http.NewRequest(http.MethodGet, url, nil)
 			require.NoError(t, err)
 
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
 			server.router.ServeHTTP(recorder, request)
 			tc.checkResponse(t, recorder)
 		})
@@ -1,14 +1,57 @@
 package api
 
 import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
 	"github.com/go-playground/validator/v10"
-	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/mrityunjaygr8/simplebank/exchange"
+	"github.com/mrityunjaygr8/simplebank/webhook"
 )
 
+// activeCurrencies is the registry consulted by the currency validator. Gin's validator is process wide
+// and caches the functions of a struct once it has been validated, so it cannot be bound to one server.
+var activeCurrencies atomic.Pointer[exchange.CurrencyRegistry]
+
+// validCurrency accepts the codes of currencies that are enabled in the registry
 var validCurrency validator.Func = func(fl validator.FieldLevel) bool {
+	registry := activeCurrencies.Load()
+	if registry == nil {
+		return false
+	}
+
 	if currency, ok := fl.Field().Interface().(string); ok {
-		return utils.IsSupportedCurrency(currency)
+		_, enabled := registry.Enabled(context.Background(), currency)
+		return enabled
+	}
+
+	return false
+}
+
+// validWebhookURL accepts absolute http and https URLs whose host is not on the server's own network
+var validWebhookURL validator.Func = func(fl validator.FieldLevel) bool {
+	if raw, ok := fl.Field().Interface().(string); ok {
+		parsed, err := url.Parse(raw)
+		return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && publicWebhookHost(parsed.Hostname())
 	}
 
 	return false
 }
+
+// publicWebhookHost refuses localhost and the addresses webhook.PublicIP refuses. Host names are checked
+// again by the sender once they have been resolved.
+func publicWebhookHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "" || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return true
+	}
+	return webhook.PublicIP(ip)
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+)
+
+const webhookSecretBytes = 32
+
+// webhookResponse leaves out the secret, which is only returned when the webhook is created
+type webhookResponse struct {
+	ID         int64     `json:"id"`
+	Url        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	return webhookResponse{
+		ID:         subscription.ID,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		Active:     subscription.Active,
+		CreatedAt:  subscription.CreatedAt,
+		UpdatedAt:  subscription.UpdatedAt,
+	}
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	Secret string `json:"secret"`
+}
+
+// createWebhookParams subscribes a URL to events on the accounts of the authenticated user. A secret is
+// generated when none is given.
+type createWebhookParams struct {
+	Url        string   `json:"url" binding:"required,max=2048,webhook_url"`
+	EventTypes []string `json:"event_types" binding:"required,min=1,dive,oneof=AccountCreated TransferCompleted EntryPosted"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=256"`
+}
+
+func (server *Server) createWebhook(ctx *gin.Context) {
+	var req createWebhookParams
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		secret, err = newWebhookSecret()
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+			return
+		}
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	subscription, err := server.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      authPayload.Username,
+		Url:        req.Url,
+		EventTypes: req.EventTypes,
+		Secret:     secret,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, createWebhookResponse{
+		webhookResponse: newWebhookResponse(subscription),
+		Secret:          subscription.Secret,
+	})
+}
+
+func newWebhookSecret() (string, error) {
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("cannot generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+type webhookURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, valid := server.authorizedWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+type listWebhooksParams struct {
+	PageID   int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=1,max=10"`
+}
+
+func (server *Server) listWebhooks(ctx *gin.Context) {
+	var req listWebhooksParams
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	subscriptions, err := server.store.ListWebhookSubscriptions(ctx, db.ListWebhookSubscriptionsParams{
+		Owner:  authPayload.Username,
+		Limit:  req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := make([]webhookResponse, len(subscriptions))
+	for i, subscription := range subscriptions {
+		response[i] = newWebhookResponse(subscription)
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// updateWebhookParams only changes the fields that are present. A new secret takes effect with the next
+// delivery attempt.
+type updateWebhookParams struct {
+	Url        string   `json:"url" binding:"omitempty,max=2048,webhook_url"`
+	EventTypes []string `json:"event_types" binding:"omitempty,min=1,dive,oneof=AccountCreated TransferCompleted EntryPosted"`
+	Secret     string   `json:"secret" binding:"omitempty,min=16,max=256"`
+	Active     *bool    `json:"active"`
+}
+
+func (server *Server) updateWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	var req updateWebhookParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, valid := server.authorizedWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	arg := db.UpdateWebhookSubscriptionParams{
+		ID:         subscription.ID,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		Secret:     subscription.Secret,
+		Active:     subscription.Active,
+	}
+	if req.Url != "" {
+		arg.Url = req.Url
+	}
+	if req.EventTypes != nil {
+		arg.EventTypes = req.EventTypes
+	}
+	if req.Secret != "" {
+		arg.Secret = req.Secret
+	}
+	if req.Active != nil {
+		arg.Active = *req.Active
+	}
+
+	subscription, err := server.store.UpdateWebhookSubscription(ctx, arg)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+// deleteWebhook removes the webhook together with its delivery history
+func (server *Server) deleteWebhook(ctx *gin.Context) {
+	var uri webhookURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, valid := server.authorizedWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	if err := server.store.DeleteWebhookSubscription(ctx, subscription.ID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+type listWebhookDeliveriesParams struct {
+	PageID   int32  `form:"page_id" binding:"required,min=1"`
+	PageSize int32  `form:"page_size" binding:"required,min=1,max=10"`
+	Status   string `form:"status" binding:"omitempty,oneof=pending delivered dead"`
+}
+
+// listWebhookDeliveries returns the delivery history of a webhook, newest first
+func (server *Server) listWebhookDeliveries(ctx *gin.Context) {
+	var uri webhookURI
+	var req listWebhookDeliveriesParams
+
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, valid := server.authorizedWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	deliveries, err := server.store.ListWebhookDeliveries(ctx, db.ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          req.PageSize,
+		Offset:         (req.PageID - 1) * req.PageSize,
+		Status:         nullString(req.Status),
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+type webhookDeliveryURI struct {
+	ID         int64 `uri:"id" binding:"required,min=1"`
+	DeliveryID int64 `uri:"delivery_id" binding:"required,min=1"`
+}
+
+// retryWebhookDelivery sends a dead delivery again, starting over with its attempts
+func (server *Server) retryWebhookDelivery(ctx *gin.Context) {
+	var uri webhookDeliveryURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, valid := server.authorizedWebhook(ctx, uri.ID)
+	if !valid {
+		return
+	}
+
+	delivery, err := server.store.GetWebhookDelivery(ctx, uri.DeliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+	if delivery.SubscriptionID != subscription.ID {
+		err := fmt.Errorf("delivery [%d] does not belong to webhook [%d]", delivery.ID, subscription.ID)
+		ctx.JSON(http.StatusNotFound, errorResponse(err))
+		return
+	}
+	if delivery.Status != db.WebhookDeliveryStatusDead {
+		err := fmt.Errorf("delivery [%d] is %s, only dead deliveries can be retried", delivery.ID, delivery.Status)
+		ctx.JSON(http.StatusConflict, errorResponse(err))
+		return
+	}
+
+	delivery, err = server.store.RetryWebhookDelivery(ctx, delivery.ID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err := fmt.Errorf("delivery [%d] is no longer dead", uri.DeliveryID)
+			ctx.JSON(http.StatusConflict, errorResponse(err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, delivery)
+}
+
+// authorizedWebhook fetches the webhook and makes sure it belongs to the authenticated user
+func (server *Server) authorizedWebhook(ctx *gin.Context, id int64) (db.WebhookSubscription, bool) {
+	subscription, err := server.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusNotFound, errorResponse(err))
+			return subscription, false
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		return subscription, false
+	}
+
+	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	if subscription.Owner != authPayload.Username {
+		err := errors.New("webhook doesn't belong to the authenticated user")
+		ctx.JSON(http.StatusForbidden, errorResponse(err))
+		return subscription, false
+	}
+
+	return subscription, true
+}
@@ -0,0 +1,7 @@
+BEGIN;
+  ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "transfers_accounts_check";
+  ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "transfers_amount_check";
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_balance_check";
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_overdraft_limit_check";
+  ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "overdraft_limit";
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+  ALTER TABLE IF EXISTS "transfers" DROP CONSTRAINT IF EXISTS "transfers_to_amount_check";
+  ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "exchange_rate";
+  ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "to_amount";
+  DROP TABLE IF EXISTS "exchange_rates";
+COMMIT;
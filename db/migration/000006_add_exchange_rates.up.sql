@@ -0,0 +1,23 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "exchange_rates" (
+  "base_currency" varchar NOT NULL,
+  "quote_currency" varchar NOT NULL,
+  "rate" numeric(20,10) NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("base_currency", "quote_currency")
+);
+
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "exchange_rates_rate_check" CHECK ("rate" > 0);
+ALTER TABLE "exchange_rates" ADD CONSTRAINT "exchange_rates_currencies_check" CHECK ("base_currency" <> "quote_currency");
+
+ALTER TABLE "transfers" ADD COLUMN "to_amount" bigint;
+UPDATE "transfers" SET "to_amount" = "amount";
+ALTER TABLE "transfers" ALTER COLUMN "to_amount" SET NOT NULL;
+ALTER TABLE "transfers" ADD COLUMN "exchange_rate" numeric(20,10) NOT NULL DEFAULT 1;
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_to_amount_check" CHECK ("to_amount" > 0);
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'units of quote_currency bought by one unit of base_currency';
+COMMENT ON COLUMN "transfers"."to_amount" IS 'credited to the destination account, in its currency';
+COMMENT ON COLUMN "transfers"."exchange_rate" IS 'rate applied to convert amount into to_amount';
+COMMIT;
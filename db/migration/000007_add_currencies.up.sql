@@ -0,0 +1,24 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "name" varchar NOT NULL,
+  "minor_units" int NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "currencies" ADD CONSTRAINT "currencies_code_check" CHECK ("code" ~ '^[A-Z]{3}$');
+ALTER TABLE "currencies" ADD CONSTRAINT "currencies_minor_units_check" CHECK ("minor_units" BETWEEN 0 AND 4);
+
+INSERT INTO "currencies" ("code", "name", "minor_units") VALUES
+  ('USD', 'US Dollar', 2),
+  ('EUR', 'Euro', 2),
+  ('CAD', 'Canadian Dollar', 2)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("base_currency") REFERENCES "currencies" ("code");
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("quote_currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "currencies"."minor_units" IS 'ISO 4217 exponent, the number of decimal places of the currency';
+COMMIT;
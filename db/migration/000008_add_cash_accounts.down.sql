@@ -0,0 +1,7 @@
+BEGIN;
+  -- cash accounts are referenced by entries and transfers, so they are kept as ordinary accounts
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_balance_check";
+  ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("balance" >= -"overdraft_limit") NOT VALID;
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_kind_check";
+  ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "kind";
+COMMIT;
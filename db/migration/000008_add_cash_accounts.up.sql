@@ -0,0 +1,17 @@
+BEGIN;
+ALTER TABLE "accounts" ADD COLUMN "kind" varchar NOT NULL DEFAULT 'customer';
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_kind_check" CHECK ("kind" IN ('customer', 'cash'));
+
+-- cash accounts mirror the money held outside the system, so they go negative as customers deposit
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_balance_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("kind" = 'cash' OR "balance" >= -"overdraft_limit");
+
+-- the system user owns the cash accounts; its name cannot be registered through the API and it cannot log in
+INSERT INTO "users" ("username", "full_name", "hashed_password", "email") VALUES
+  ('simplebank_system', 'Simple Bank', '', 'system@simplebank.invalid');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "kind")
+SELECT 'simplebank_system', 0, "code", 'cash' FROM "currencies";
+
+COMMENT ON COLUMN "accounts"."kind" IS 'customer accounts belong to users, cash accounts balance deposits and withdrawals';
+COMMIT;
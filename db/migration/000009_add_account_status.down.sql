@@ -0,0 +1,15 @@
+BEGIN;
+  -- closed accounts are kept for their history, so an owner may have several in one currency by now.
+  -- They cannot be dropped without their entries, which makes this migration irreversible once that happens.
+  DO $$
+  BEGIN
+    IF EXISTS (SELECT 1 FROM "accounts" GROUP BY "owner", "currency" HAVING COUNT(*) > 1) THEN
+      RAISE EXCEPTION 'cannot restore owner_currency_key: an owner has more than one account in a currency';
+    END IF;
+  END $$;
+
+  DROP INDEX IF EXISTS "owner_currency_key";
+  ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "owner_currency_key" UNIQUE ("owner", "currency");
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_status_check";
+  ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_status_check" CHECK ("status" IN ('active', 'frozen', 'closed'));
+
+-- closed accounts are kept for their history, so they must not stop the owner opening a new one
+ALTER TABLE "accounts" DROP CONSTRAINT "owner_currency_key";
+CREATE UNIQUE INDEX "owner_currency_key" ON "accounts" ("owner", "currency") WHERE "status" <> 'closed';
+
+COMMENT ON COLUMN "accounts"."status" IS 'active accounts can move money, frozen ones can be reactivated, closed ones are final';
+COMMIT;
@@ -0,0 +1,42 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "schedule" varchar NOT NULL,
+  "next_run_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "consecutive_failures" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "scheduled_transfers_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "scheduled_transfers_status_check" CHECK ("status" IN ('active', 'paused', 'completed'))
+);
+
+CREATE TABLE IF NOT EXISTS "scheduled_transfer_runs" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "transfer_id" bigint,
+  "scheduled_for" timestamptz NOT NULL,
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "scheduled_transfer_runs_status_check" CHECK ("status" IN ('succeeded', 'failed'))
+);
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id") ON DELETE CASCADE;
+ALTER TABLE "scheduled_transfer_runs" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+CREATE INDEX ON "scheduled_transfers" ("next_run_at") WHERE "status" = 'active';
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfers"."schedule" IS 'cron expression or interval such as @every 24h';
+COMMENT ON COLUMN "scheduled_transfers"."consecutive_failures" IS 'runs that failed since the last successful one';
+COMMENT ON COLUMN "scheduled_transfer_runs"."transfer_id" IS 'set when the run moved money';
+COMMIT;
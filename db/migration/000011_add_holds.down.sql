@@ -0,0 +1,6 @@
+BEGIN;
+  DROP TABLE IF EXISTS "holds";
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_balance_check";
+  ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("kind" = 'cash' OR "balance" >= -"overdraft_limit");
+  ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "available_balance";
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+ALTER TABLE "accounts" ADD COLUMN "available_balance" bigint NOT NULL DEFAULT 0;
+UPDATE "accounts" SET "available_balance" = "balance";
+
+-- funds reserved by holds cannot be spent, so the overdraft limit applies to what is still available
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_balance_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("kind" = 'cash' OR "available_balance" >= -"overdraft_limit");
+
+CREATE TABLE IF NOT EXISTS "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "captured_amount" bigint NOT NULL DEFAULT 0,
+  "transfer_id" bigint,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "holds_amount_check" CHECK ("amount" > 0),
+  CONSTRAINT "holds_captured_amount_check" CHECK ("captured_amount" >= 0 AND "captured_amount" <= "amount"),
+  CONSTRAINT "holds_status_check" CHECK ("status" IN ('active', 'captured', 'released', 'expired'))
+);
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "holds" ("account_id");
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'active';
+
+COMMENT ON COLUMN "accounts"."available_balance" IS 'balance minus the amount reserved by active holds';
+COMMENT ON COLUMN "holds"."to_account_id" IS 'receives the captured amount';
+COMMENT ON COLUMN "holds"."transfer_id" IS 'set once the hold is captured';
+COMMIT;
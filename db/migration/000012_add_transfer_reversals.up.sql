@@ -0,0 +1,12 @@
+BEGIN;
+ALTER TABLE "transfers" ADD COLUMN "reversal_of" bigint;
+ALTER TABLE "transfers" ADD COLUMN "reversed_amount" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of") REFERENCES "transfers" ("id");
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_reversed_amount_check" CHECK ("reversed_amount" >= 0 AND "reversed_amount" <= "amount");
+
+CREATE INDEX ON "transfers" ("reversal_of");
+
+COMMENT ON COLUMN "transfers"."reversal_of" IS 'the transfer this one reverses';
+COMMENT ON COLUMN "transfers"."reversed_amount" IS 'part of amount already sent back by reversals';
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+  ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "batch_id";
+  DROP TABLE IF EXISTS "transfer_batches";
+COMMIT;
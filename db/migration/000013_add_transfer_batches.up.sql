@@ -0,0 +1,13 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "transfer_batches" (
+  "id" bigserial PRIMARY KEY,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfers" ADD COLUMN "batch_id" bigint;
+ALTER TABLE "transfers" ADD FOREIGN KEY ("batch_id") REFERENCES "transfer_batches" ("id");
+
+CREATE INDEX ON "transfers" ("batch_id");
+
+COMMENT ON COLUMN "transfers"."batch_id" IS 'set on every leg of a batch transfer';
+COMMIT;
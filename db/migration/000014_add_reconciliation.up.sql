@@ -0,0 +1,29 @@
+BEGIN;
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- a transfer and its entries are written in one transaction and share created_at, which links
+-- the entries posted before this column existed; whatever cannot be matched is left for reconciliation to report
+UPDATE "entries" AS e SET "transfer_id" = t."id"
+FROM "transfers" AS t
+WHERE e."created_at" = t."created_at"
+  AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount")
+    OR (e."account_id" = t."to_account_id" AND e."amount" = t."to_amount"));
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'the transfer that posted this entry';
+
+CREATE TABLE IF NOT EXISTS "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY,
+  "full_scan" boolean NOT NULL,
+  "last_transfer_id" bigint NOT NULL,
+  "last_entry_id" bigint NOT NULL,
+  "discrepancies" bigint NOT NULL,
+  "report" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "reconciliation_runs"."last_transfer_id" IS 'checkpoint the next incremental run continues from';
+COMMENT ON COLUMN "reconciliation_runs"."last_entry_id" IS 'checkpoint the next incremental run continues from';
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+  DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+  DROP TABLE IF EXISTS "balance_snapshots";
+COMMIT;
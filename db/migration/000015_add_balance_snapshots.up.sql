@@ -0,0 +1,14 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "balance_snapshots" (
+  "account_id" bigint NOT NULL,
+  "balance" bigint NOT NULL,
+  "taken_at" timestamptz NOT NULL,
+  PRIMARY KEY ("account_id", "taken_at")
+);
+
+ALTER TABLE "balance_snapshots" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+COMMENT ON COLUMN "balance_snapshots"."balance" IS 'sum of the entries of the account created up to taken_at';
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+CREATE INDEX ON "accounts" ("owner", "created_at", "id");
+
+CREATE INDEX ON "entries" ("created_at", "id");
+CREATE INDEX ON "entries" ("account_id", "created_at", "id");
+DROP INDEX IF EXISTS "entries_account_id_created_at_idx";
+
+CREATE INDEX ON "transfers" ("created_at", "id");
+CREATE INDEX ON "transfers" ("from_account_id", "created_at", "id");
+CREATE INDEX ON "transfers" ("to_account_id", "created_at", "id");
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+  DROP INDEX IF EXISTS "entries_account_id_abs_amount_created_at_id_idx";
+  DROP INDEX IF EXISTS "transfers_to_account_id_amount_created_at_id_idx";
+  DROP INDEX IF EXISTS "transfers_from_account_id_amount_created_at_id_idx";
+  DROP INDEX IF EXISTS "transfers_amount_created_at_id_idx";
+COMMIT;
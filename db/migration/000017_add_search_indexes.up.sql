@@ -0,0 +1,7 @@
+BEGIN;
+CREATE INDEX ON "transfers" ("amount", "created_at", "id");
+CREATE INDEX ON "transfers" ("from_account_id", "amount", "created_at", "id");
+CREATE INDEX ON "transfers" ("to_account_id", "amount", "created_at", "id");
+
+CREATE INDEX "entries_account_id_abs_amount_created_at_id_idx" ON "entries" ("account_id", abs("amount"), "created_at", "id");
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+  DROP TABLE IF EXISTS "account_transfer_limits";
+  DROP TABLE IF EXISTS "tier_transfer_limits";
+  ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "tier";
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+ALTER TABLE "users" ADD COLUMN "tier" varchar NOT NULL DEFAULT 'standard';
+
+CREATE TABLE IF NOT EXISTS "tier_transfer_limits" (
+  "tier" varchar NOT NULL,
+  "currency" varchar(3) NOT NULL REFERENCES "currencies" ("code"),
+  "max_amount" bigint,
+  "max_daily_amount" bigint,
+  "max_monthly_amount" bigint,
+  "max_daily_count" int,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("tier", "currency")
+);
+
+CREATE TABLE IF NOT EXISTS "account_transfer_limits" (
+  "account_id" bigint PRIMARY KEY REFERENCES "accounts" ("id"),
+  "max_amount" bigint,
+  "max_daily_amount" bigint,
+  "max_monthly_amount" bigint,
+  "max_daily_count" int,
+  "updated_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "tier_transfer_limits" ADD CONSTRAINT "tier_transfer_limits_check" CHECK (
+  "max_amount" > 0 AND "max_daily_amount" > 0 AND "max_monthly_amount" > 0 AND "max_daily_count" > 0
+);
+ALTER TABLE "account_transfer_limits" ADD CONSTRAINT "account_transfer_limits_check" CHECK (
+  "max_amount" > 0 AND "max_daily_amount" > 0 AND "max_monthly_amount" > 0 AND "max_daily_count" > 0
+);
+
+COMMENT ON COLUMN "users"."tier" IS 'selects the tier_transfer_limits that apply to the accounts of the user';
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+  DROP INDEX IF EXISTS "transfers_from_account_id_to_account_id_idx";
+  DROP TABLE IF EXISTS "pending_transfers";
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "pending_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+  "amount" bigint NOT NULL,
+  "currency" varchar(3) NOT NULL REFERENCES "currencies" ("code"),
+  "requested_by" varchar NOT NULL REFERENCES "users" ("username"),
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reasons" jsonb NOT NULL,
+  "transfer_id" bigint REFERENCES "transfers" ("id"),
+  "reviewed_by" varchar REFERENCES "users" ("username"),
+  "reviewed_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "pending_transfers" ADD CONSTRAINT "pending_transfers_amount_check" CHECK ("amount" > 0);
+ALTER TABLE "pending_transfers" ADD CONSTRAINT "pending_transfers_status_check" CHECK ("status" IN ('pending', 'approved', 'rejected'));
+
+CREATE INDEX ON "pending_transfers" ("status", "created_at", "id");
+CREATE INDEX ON "pending_transfers" ("from_account_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
+
+COMMENT ON COLUMN "pending_transfers"."reasons" IS 'the fraud rules that sent the transfer to review';
+COMMENT ON COLUMN "pending_transfers"."transfer_id" IS 'the transfer made once an admin approved it';
+COMMIT;
@@ -0,0 +1,36 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "resource_type" varchar NOT NULL,
+  "resource_id" varchar NOT NULL,
+  "before" json NOT NULL,
+  "after" json NOT NULL,
+  "request_id" varchar NOT NULL,
+  "client_ip" varchar NOT NULL,
+  "prev_hash" varchar NOT NULL,
+  "hash" varchar UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "audit_events" ("actor", "id");
+CREATE INDEX ON "audit_events" ("resource_type", "resource_id", "id");
+CREATE INDEX ON "audit_events" ("created_at");
+
+CREATE OR REPLACE FUNCTION "audit_events_append_only"() RETURNS trigger AS $$
+BEGIN
+  RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "audit_events_no_update" BEFORE UPDATE OR DELETE ON "audit_events"
+  FOR EACH ROW EXECUTE FUNCTION "audit_events_append_only"();
+CREATE TRIGGER "audit_events_no_truncate" BEFORE TRUNCATE ON "audit_events"
+  FOR EACH STATEMENT EXECUTE FUNCTION "audit_events_append_only"();
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username, anonymous for requests without a login or system for background jobs';
+COMMENT ON COLUMN "audit_events"."before" IS 'json rather than jsonb keeps the text the hash was computed over';
+COMMENT ON COLUMN "audit_events"."prev_hash" IS 'hash of the previous event, empty for the first one';
+COMMENT ON COLUMN "audit_events"."hash" IS 'sha256 over prev_hash and the other columns except id';
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "event_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "published_at" timestamptz,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT ''
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "published_at" IS NULL;
+
+COMMENT ON COLUMN "outbox"."account_id" IS 'the events of an account are published in id order';
+COMMENT ON COLUMN "outbox"."published_at" IS 'null until the relay published the event';
+COMMENT ON COLUMN "outbox"."attempts" IS 'publish attempts that failed';
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+-- databases migrated before the role moved out of 000007_add_currencies already have the column
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "role" varchar NOT NULL DEFAULT 'depositor';
+ALTER TABLE "users" DROP CONSTRAINT IF EXISTS "users_role_check";
+ALTER TABLE "users" ADD CONSTRAINT "users_role_check" CHECK ("role" IN ('depositor', 'admin'));
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+  -- fx accounts are referenced by entries, so they are kept as cash accounts of the fx user
+  UPDATE "accounts" SET "kind" = 'cash' WHERE "kind" = 'fx';
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_balance_check";
+  ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("kind" = 'cash' OR "balance" >= -"overdraft_limit");
+  ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_kind_check";
+  ALTER TABLE IF EXISTS "accounts" ADD CONSTRAINT "accounts_kind_check" CHECK ("kind" IN ('customer', 'cash'));
+  COMMENT ON COLUMN "accounts"."kind" IS 'customer accounts belong to users, cash accounts balance deposits and withdrawals';
+COMMIT;
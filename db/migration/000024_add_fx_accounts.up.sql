@@ -0,0 +1,17 @@
+BEGIN;
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_kind_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_kind_check" CHECK ("kind" IN ('customer', 'cash', 'fx'));
+
+-- fx accounts pay out the destination side of cross-currency transfers, so they go negative like cash accounts
+ALTER TABLE "accounts" DROP CONSTRAINT "accounts_balance_check";
+ALTER TABLE "accounts" ADD CONSTRAINT "accounts_balance_check" CHECK ("kind" IN ('cash', 'fx') OR "balance" >= -"overdraft_limit");
+
+-- the fx user owns the fx accounts; like the system user its name cannot be registered and it cannot log in
+INSERT INTO "users" ("username", "full_name", "hashed_password", "email") VALUES
+  ('simplebank_fx', 'Simple Bank FX', '', 'fx@simplebank.invalid');
+
+INSERT INTO "accounts" ("owner", "balance", "currency", "kind")
+SELECT 'simplebank_fx', 0, "code", 'fx' FROM "currencies";
+
+COMMENT ON COLUMN "accounts"."kind" IS 'customer accounts belong to users, cash accounts balance deposits and withdrawals, fx accounts balance currency conversions';
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+  CREATE INDEX IF NOT EXISTS "scheduled_transfer_runs_scheduled_transfer_id_idx" ON "scheduled_transfer_runs" ("scheduled_transfer_id");
+  DROP INDEX IF EXISTS "scheduled_transfer_runs_scheduled_transfer_id_created_at_id_idx";
+  CREATE INDEX IF NOT EXISTS "scheduled_transfers_owner_idx" ON "scheduled_transfers" ("owner");
+  DROP INDEX IF EXISTS "scheduled_transfers_owner_created_at_id_idx";
+COMMIT;
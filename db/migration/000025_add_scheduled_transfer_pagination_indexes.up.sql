@@ -0,0 +1,7 @@
+BEGIN;
+CREATE INDEX ON "scheduled_transfers" ("owner", "created_at", "id");
+DROP INDEX IF EXISTS "scheduled_transfers_owner_idx";
+
+CREATE INDEX ON "scheduled_transfer_runs" ("scheduled_transfer_id", "created_at", "id");
+DROP INDEX IF EXISTS "scheduled_transfer_runs_scheduled_transfer_id_idx";
+COMMIT;
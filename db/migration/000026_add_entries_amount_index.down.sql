@@ -0,0 +1,3 @@
+BEGIN;
+  DROP INDEX IF EXISTS "entries_abs_amount_created_at_id_idx";
+COMMIT;
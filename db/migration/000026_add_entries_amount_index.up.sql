@@ -0,0 +1,4 @@
+BEGIN;
+-- searching entries across all accounts by amount, the per-account searches use the 000017 indexes
+CREATE INDEX "entries_abs_amount_created_at_id_idx" ON "entries" (abs("amount"), "created_at", "id");
+COMMIT;
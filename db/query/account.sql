@@ -1,8 +1,8 @@
 -- name: CreateAccount :one
 INSERT INTO accounts (
-  owner, balance, currency
+  owner, balance, available_balance, currency
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3
 )
 RETURNING *;
 
@@ -10,8 +10,14 @@ RETURNING *;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountForUpdate :one
+SELECT * FROM accounts
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2;
 
@@ -22,12 +28,57 @@ WHERE id = $1;
 
 -- name: UpdateAccount :one
 UPDATE accounts
-set balance = $2
+set balance = $2, available_balance = available_balance + $2 - balance
 WHERE id = $1
 RETURNING *;
 
 -- name: AddAccountBalance :one
 UPDATE accounts
-set balance = balance + sqlc.arg(amount)
+set balance = balance + sqlc.arg(amount), available_balance = available_balance + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+set available_balance = available_balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
+
+-- name: ListAccountsByOwner :many
+SELECT * FROM accounts
+WHERE owner = $1
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = sqlc.arg(to_status)
+WHERE id = sqlc.arg(id) AND status = sqlc.arg(from_status)
+RETURNING *;
+
+-- name: UpsertCashAccount :one
+INSERT INTO accounts (
+  owner, balance, currency, kind
+) VALUES (
+  sqlc.arg(owner), 0, sqlc.arg(currency), 'cash'
+)
+ON CONFLICT (owner, currency) WHERE status <> 'closed' DO UPDATE
+SET kind = EXCLUDED.kind
+RETURNING *;
+
+-- name: UpsertFXAccount :one
+INSERT INTO accounts (
+  owner, balance, currency, kind
+) VALUES (
+  sqlc.arg(owner), 0, sqlc.arg(currency), 'fx'
+)
+ON CONFLICT (owner, currency) WHERE status <> 'closed' DO UPDATE
+SET kind = EXCLUDED.kind
+RETURNING *;
+
+-- name: ListAccountsByOwnerAfter :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner) AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
@@ -0,0 +1,30 @@
+-- name: CreateBalanceSnapshots :execrows
+INSERT INTO balance_snapshots (account_id, balance, taken_at)
+SELECT a.id, (COALESCE(s.balance, 0) + COALESCE(SUM(e.amount), 0))::bigint, sqlc.arg(taken_at)::timestamptz
+FROM accounts a
+LEFT JOIN LATERAL (
+  SELECT balance, taken_at FROM balance_snapshots
+  WHERE account_id = a.id AND taken_at < sqlc.arg(taken_at)
+  ORDER BY taken_at DESC
+  LIMIT 1
+) s ON true
+LEFT JOIN entries e ON e.account_id = a.id
+  AND e.created_at > COALESCE(s.taken_at, '-infinity')
+  AND e.created_at <= sqlc.arg(taken_at)
+GROUP BY a.id, s.balance
+ON CONFLICT (account_id, taken_at) DO NOTHING;
+
+-- name: GetAccountBalanceAsOf :one
+SELECT (COALESCE(s.balance, 0) + COALESCE(SUM(e.amount), 0))::bigint AS balance
+FROM accounts a
+LEFT JOIN LATERAL (
+  SELECT balance, taken_at FROM balance_snapshots
+  WHERE account_id = a.id AND taken_at <= sqlc.arg(as_of)
+  ORDER BY taken_at DESC
+  LIMIT 1
+) s ON true
+LEFT JOIN entries e ON e.account_id = a.id
+  AND e.created_at > COALESCE(s.taken_at, '-infinity')
+  AND e.created_at <= sqlc.arg(as_of)
+WHERE a.id = sqlc.arg(account_id)
+GROUP BY s.balance;
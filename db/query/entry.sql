@@ -1,8 +1,8 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 )
 RETURNING *;
 
@@ -12,11 +12,187 @@ WHERE id = $1 LIMIT 1;
 
 -- name: ListEntries :many
 SELECT * FROM entries
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2;
 
 -- name: ListEntriesForAccount :many
 SELECT * FROM entries
 WHERE account_id = $3
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2;
+
+-- name: ListEntriesWithBalanceForAccount :many
+SELECT * FROM (
+  SELECT id, account_id, amount, created_at, transfer_id,
+    SUM(amount) OVER (ORDER BY created_at, id)::bigint AS running_balance
+  FROM entries
+  WHERE account_id = $1
+) AS e
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListEntriesAfter :many
+SELECT * FROM entries
+WHERE (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: ListEntriesForAccountAfter :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id) AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: ListEntriesWithBalanceForAccountAfter :many
+SELECT * FROM (
+  SELECT id, account_id, amount, created_at, transfer_id,
+    SUM(amount) OVER (ORDER BY created_at, id)::bigint AS running_balance
+  FROM entries
+  WHERE account_id = sqlc.arg(account_id)
+) AS e
+WHERE (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: SearchEntriesByCreatedAt :many
+SELECT * FROM entries
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) > (COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: SearchEntriesByCreatedAtDesc :many
+SELECT * FROM entries
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) < (COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchEntriesByAmount :many
+SELECT * FROM entries
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) > (COALESCE(sqlc.narg(after_amount)::bigint, 0), COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY abs(amount), created_at, id
+LIMIT $1;
+
+-- name: SearchEntriesByAmountDesc :many
+SELECT * FROM entries
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) < (COALESCE(sqlc.narg(after_amount)::bigint, 9223372036854775807), COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY abs(amount) DESC, created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchEntriesForAccountByCreatedAt :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) > (COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: SearchEntriesForAccountByCreatedAtDesc :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) < (COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchEntriesForAccountByAmount :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) > (COALESCE(sqlc.narg(after_amount)::bigint, 0), COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY abs(amount), created_at, id
+LIMIT $1;
+
+-- name: SearchEntriesForAccountByAmountDesc :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND abs(amount) <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE(sqlc.narg(sign)::bigint, sign(amount))
+  AND (sqlc.narg(counterparty_id)::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = sqlc.narg(counterparty_id)
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) < (COALESCE(sqlc.narg(after_amount)::bigint, 9223372036854775807), COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY abs(amount) DESC, created_at DESC, id DESC
+LIMIT $1;
@@ -0,0 +1,26 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+  account_id, event_type, payload
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListUnpublishedOutboxEvents :many
+SELECT * FROM outbox
+WHERE published_at IS NULL
+ORDER BY id
+LIMIT $1;
+
+-- name: MarkOutboxEventFailed :exec
+UPDATE outbox
+SET attempts = attempts + 1, last_error = $2
+WHERE id = $1;
+
+-- name: MarkOutboxEventPublished :exec
+UPDATE outbox
+SET published_at = now()
+WHERE id = $1;
+
+-- name: TryLockOutbox :one
+SELECT pg_try_advisory_xact_lock(hashtext('outbox'));
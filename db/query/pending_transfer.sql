@@ -0,0 +1,32 @@
+-- name: CreatePendingTransfer :one
+INSERT INTO pending_transfers (
+  from_account_id, to_account_id, amount, currency, requested_by, reasons
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetPendingTransfer :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPendingTransferForUpdate :one
+SELECT * FROM pending_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingTransfers :many
+SELECT * FROM pending_transfers
+WHERE status = $1
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3;
+
+-- name: ReviewPendingTransfer :one
+UPDATE pending_transfers
+SET status = sqlc.arg(status),
+  transfer_id = sqlc.arg(transfer_id),
+  reviewed_by = sqlc.arg(reviewed_by),
+  reviewed_at = now()
+WHERE id = sqlc.arg(id) AND status = 'pending'
+RETURNING *;
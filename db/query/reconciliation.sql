@@ -0,0 +1,77 @@
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+  full_scan, last_transfer_id, last_entry_id, discrepancies, report
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: GetEntryCutoffID :one
+SELECT COALESCE(MAX(id), sqlc.arg(after_id)::bigint)::bigint AS cutoff_id
+FROM entries
+WHERE id > sqlc.arg(after_id)::bigint AND created_at < sqlc.arg(before);
+
+-- name: GetTransferCutoffID :one
+SELECT COALESCE(MAX(id), sqlc.arg(after_id)::bigint)::bigint AS cutoff_id
+FROM transfers
+WHERE id > sqlc.arg(after_id)::bigint AND created_at < sqlc.arg(before);
+
+-- name: GetLatestReconciliationRun :one
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetReconciliationRun :one
+SELECT * FROM reconciliation_runs
+WHERE id = $1 LIMIT 1;
+
+-- name: ListAccountEntryTotals :many
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id > $1
+GROUP BY a.id
+ORDER BY a.id
+LIMIT $2;
+
+-- name: ListAccountEntryTotalsByID :many
+SELECT a.id, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+WHERE a.id = ANY(sqlc.arg(ids)::bigint[])
+GROUP BY a.id
+ORDER BY a.id;
+
+-- name: ListEntryTransfers :many
+SELECT e.id, e.account_id, a.kind AS account_kind, e.amount, e.transfer_id, t.from_account_id, t.to_account_id
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.id > sqlc.arg(after_id) AND e.id <= sqlc.arg(cutoff_id)
+ORDER BY e.id
+LIMIT $1;
+
+-- name: ListReconciliationRuns :many
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
+
+-- name: ListTransferEntryTotals :many
+SELECT
+  t.id, t.from_account_id, t.to_account_id, t.amount, t.to_amount,
+  fa.currency AS from_currency, ta.currency AS to_currency,
+  COUNT(e.id) AS entry_count,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0)::bigint AS from_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0)::bigint AS to_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE a.currency = fa.currency), 0)::bigint AS from_currency_total,
+  COALESCE(SUM(e.amount) FILTER (WHERE a.currency = ta.currency), 0)::bigint AS to_currency_total
+FROM transfers t
+JOIN accounts fa ON fa.id = t.from_account_id
+JOIN accounts ta ON ta.id = t.to_account_id
+LEFT JOIN entries e ON e.transfer_id = t.id
+LEFT JOIN accounts a ON a.id = e.account_id
+WHERE t.id > sqlc.arg(after_id) AND t.id <= sqlc.arg(cutoff_id)
+GROUP BY t.id, fa.currency, ta.currency
+ORDER BY t.id
+LIMIT $1;
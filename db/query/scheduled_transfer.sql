@@ -0,0 +1,84 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+  owner,
+  from_account_id,
+  to_account_id,
+  amount,
+  schedule,
+  next_run_at,
+  end_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListScheduledTransfersAfter :many
+SELECT * FROM scheduled_transfers
+WHERE owner = sqlc.arg(owner) AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: ListDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE status = 'active' AND next_run_at <= $1
+ORDER BY next_run_at
+LIMIT $2
+FOR UPDATE SKIP LOCKED;
+
+-- name: LeaseScheduledTransfers :exec
+UPDATE scheduled_transfers
+SET next_run_at = sqlc.arg(leased_until)
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET amount = $2, schedule = $3, next_run_at = $4, end_at = $5, status = $6, consecutive_failures = $7
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateClaimedScheduledTransfer :one
+UPDATE scheduled_transfers
+SET next_run_at = $2, status = $3, consecutive_failures = $4
+WHERE id = $1 AND status = 'active' AND next_run_at = sqlc.arg(leased_until)
+RETURNING *;
+
+-- name: DeleteScheduledTransfer :exec
+DELETE FROM scheduled_transfers
+WHERE id = $1;
+
+-- name: CreateScheduledTransferRun :one
+INSERT INTO scheduled_transfer_runs (
+  scheduled_transfer_id,
+  transfer_id,
+  scheduled_for,
+  status,
+  error
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: ListScheduledTransferRuns :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListScheduledTransferRunsAfter :many
+SELECT * FROM scheduled_transfer_runs
+WHERE scheduled_transfer_id = sqlc.arg(scheduled_transfer_id)
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
@@ -1,9 +1,9 @@
 
 -- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, reversal_of, batch_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7
 )
 RETURNING *;
 
@@ -11,13 +11,209 @@ RETURNING *;
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2;
 
 -- name: ListTransfersForAccount :many
 SELECT * FROM transfers
 WHERE from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id)
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2;
+
+-- name: ListTransferReversals :many
+SELECT * FROM transfers
+WHERE reversal_of = $1
+ORDER BY id;
+
+-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + sqlc.arg(amount)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListTransfersAfter :many
+SELECT * FROM transfers
+WHERE (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: ListTransfersForAccountAfter :many
+SELECT * FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND (created_at, id) > (sqlc.arg(after_created_at)::timestamptz, sqlc.arg(after_id)::bigint)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: SearchTransfersByCreatedAt :many
+SELECT * FROM transfers
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (created_at, id) > (COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: SearchTransfersByCreatedAtDesc :many
+SELECT * FROM transfers
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (created_at, id) < (COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchTransfersByAmount :many
+SELECT * FROM transfers
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (amount, created_at, id) > (COALESCE(sqlc.narg(after_amount)::bigint, 0), COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY amount, created_at, id
+LIMIT $1;
+
+-- name: SearchTransfersByAmountDesc :many
+SELECT * FROM transfers
+WHERE created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (amount, created_at, id) < (COALESCE(sqlc.narg(after_amount)::bigint, 9223372036854775807), COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchTransfersForAccountByCreatedAt :many
+-- each side of the account is read in order from its own index and the two are merged,
+-- an account id of 0 leaves that side out
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(outgoing_account_id)
+  AND to_account_id = COALESCE(sqlc.narg(counterparty_id)::bigint, to_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (created_at, id) > (COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1)
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(incoming_account_id)
+  AND from_account_id = COALESCE(sqlc.narg(counterparty_id), from_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time), '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time), 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount), 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount), 9223372036854775807)
+  AND (created_at, id) > (COALESCE(sqlc.narg(after_created_at), '-infinity'), COALESCE(sqlc.narg(after_id), 0))
+ORDER BY created_at, id
+LIMIT $1)
+ORDER BY created_at, id
+LIMIT $1;
+
+-- name: SearchTransfersForAccountByCreatedAtDesc :many
+-- each side of the account is read in order from its own index and the two are merged,
+-- an account id of 0 leaves that side out
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(outgoing_account_id)
+  AND to_account_id = COALESCE(sqlc.narg(counterparty_id)::bigint, to_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (created_at, id) < (COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1)
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(incoming_account_id)
+  AND from_account_id = COALESCE(sqlc.narg(counterparty_id), from_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time), '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time), 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount), 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount), 9223372036854775807)
+  AND (created_at, id) < (COALESCE(sqlc.narg(after_created_at), 'infinity'), COALESCE(sqlc.narg(after_id), 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1)
+ORDER BY created_at DESC, id DESC
+LIMIT $1;
+
+-- name: SearchTransfersForAccountByAmount :many
+-- each side of the account is read in order from its own index and the two are merged,
+-- an account id of 0 leaves that side out
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(outgoing_account_id)
+  AND to_account_id = COALESCE(sqlc.narg(counterparty_id)::bigint, to_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (amount, created_at, id) > (COALESCE(sqlc.narg(after_amount)::bigint, 0), COALESCE(sqlc.narg(after_created_at)::timestamptz, '-infinity'), COALESCE(sqlc.narg(after_id)::bigint, 0))
+ORDER BY amount, created_at, id
+LIMIT $1)
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(incoming_account_id)
+  AND from_account_id = COALESCE(sqlc.narg(counterparty_id), from_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time), '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time), 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount), 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount), 9223372036854775807)
+  AND (amount, created_at, id) > (COALESCE(sqlc.narg(after_amount), 0), COALESCE(sqlc.narg(after_created_at), '-infinity'), COALESCE(sqlc.narg(after_id), 0))
+ORDER BY amount, created_at, id
+LIMIT $1)
+ORDER BY amount, created_at, id
+LIMIT $1;
+
+-- name: SearchTransfersForAccountByAmountDesc :many
+-- each side of the account is read in order from its own index and the two are merged,
+-- an account id of 0 leaves that side out
+(SELECT * FROM transfers
+WHERE from_account_id = sqlc.arg(outgoing_account_id)
+  AND to_account_id = COALESCE(sqlc.narg(counterparty_id)::bigint, to_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time)::timestamptz, '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time)::timestamptz, 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount)::bigint, 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount)::bigint, 9223372036854775807)
+  AND (amount, created_at, id) < (COALESCE(sqlc.narg(after_amount)::bigint, 9223372036854775807), COALESCE(sqlc.narg(after_created_at)::timestamptz, 'infinity'), COALESCE(sqlc.narg(after_id)::bigint, 9223372036854775807))
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1)
+UNION ALL
+(SELECT * FROM transfers
+WHERE to_account_id = sqlc.arg(incoming_account_id)
+  AND from_account_id = COALESCE(sqlc.narg(counterparty_id), from_account_id)
+  AND created_at >= COALESCE(sqlc.narg(start_time), '-infinity')
+  AND created_at < COALESCE(sqlc.narg(end_time), 'infinity')
+  AND amount >= COALESCE(sqlc.narg(min_amount), 0)
+  AND amount <= COALESCE(sqlc.narg(max_amount), 9223372036854775807)
+  AND (amount, created_at, id) < (COALESCE(sqlc.narg(after_amount), 9223372036854775807), COALESCE(sqlc.narg(after_created_at), 'infinity'), COALESCE(sqlc.narg(after_id), 9223372036854775807))
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1)
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1;
+
+-- name: CountTransfersToPayee :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2;
+
+-- name: CountNewPayeesSince :one
+SELECT COUNT(*) FROM (
+  SELECT to_account_id FROM transfers
+  WHERE from_account_id = $1
+  GROUP BY to_account_id
+  HAVING MIN(created_at) >= sqlc.arg(since)::timestamptz
+) AS new_payees;
+
+-- name: ListTransferAmountsSince :many
+SELECT amount FROM transfers
+WHERE from_account_id = $1 AND created_at >= sqlc.arg(since)::timestamptz
+ORDER BY created_at, id;
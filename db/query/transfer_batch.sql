@@ -0,0 +1,3 @@
+-- name: CreateTransferBatch :one
+INSERT INTO transfer_batches DEFAULT VALUES
+RETURNING *;
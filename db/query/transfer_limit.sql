@@ -0,0 +1,69 @@
+-- name: DeleteAccountTransferLimit :exec
+DELETE FROM account_transfer_limits
+WHERE account_id = $1;
+
+-- name: DeleteTierTransferLimit :exec
+DELETE FROM tier_transfer_limits
+WHERE tier = $1 AND currency = $2;
+
+-- name: GetAccountTransferLimit :one
+SELECT * FROM account_transfer_limits
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetEffectiveTransferLimit :one
+SELECT
+  COALESCE(account_limit.max_amount, tier_limit.max_amount) AS max_amount,
+  COALESCE(account_limit.max_daily_amount, tier_limit.max_daily_amount) AS max_daily_amount,
+  COALESCE(account_limit.max_monthly_amount, tier_limit.max_monthly_amount) AS max_monthly_amount,
+  COALESCE(account_limit.max_daily_count, tier_limit.max_daily_count) AS max_daily_count
+FROM accounts
+JOIN users ON users.username = accounts.owner
+LEFT JOIN account_transfer_limits AS account_limit ON account_limit.account_id = accounts.id
+LEFT JOIN tier_transfer_limits AS tier_limit ON tier_limit.tier = users.tier AND tier_limit.currency = accounts.currency
+WHERE accounts.id = $1;
+
+-- name: GetTierTransferLimit :one
+SELECT * FROM tier_transfer_limits
+WHERE tier = $1 AND currency = $2 LIMIT 1;
+
+-- name: GetTransferLimitUsage :one
+SELECT
+  COALESCE(SUM(amount) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'), 0)::bigint AS daily_amount,
+  COUNT(*) FILTER (WHERE created_at >= date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC') AS daily_count,
+  COALESCE(SUM(amount), 0)::bigint AS monthly_amount
+FROM transfers
+WHERE from_account_id = $1
+  AND reversal_of IS NULL
+  AND created_at >= date_trunc('month', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+
+-- name: ListTierTransferLimits :many
+SELECT * FROM tier_transfer_limits
+ORDER BY tier, currency;
+
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO account_transfer_limits (
+  account_id, max_amount, max_daily_amount, max_monthly_amount, max_daily_count
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (account_id) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+  max_daily_amount = EXCLUDED.max_daily_amount,
+  max_monthly_amount = EXCLUDED.max_monthly_amount,
+  max_daily_count = EXCLUDED.max_daily_count,
+  updated_at = now()
+RETURNING *;
+
+-- name: UpsertTierTransferLimit :one
+INSERT INTO tier_transfer_limits (
+  tier, currency, max_amount, max_daily_amount, max_monthly_amount, max_daily_count
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (tier, currency) DO UPDATE
+SET max_amount = EXCLUDED.max_amount,
+  max_daily_amount = EXCLUDED.max_daily_amount,
+  max_monthly_amount = EXCLUDED.max_monthly_amount,
+  max_daily_count = EXCLUDED.max_daily_count,
+  updated_at = now()
+RETURNING *;
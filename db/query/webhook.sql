@@ -0,0 +1,76 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+  owner, url, event_types, secret
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET url = $2, event_types = $3, secret = $4, active = $5, updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions
+WHERE id = $1;
+
+-- name: CreateWebhookDeliveries :exec
+INSERT INTO webhook_deliveries (subscription_id, event_id, event_type)
+SELECT s.id, o.id, o.event_type
+FROM outbox o
+JOIN accounts a ON a.id = o.account_id
+JOIN webhook_subscriptions s ON s.owner = a.owner
+WHERE o.id = $1 AND s.active AND o.event_type = ANY(s.event_types);
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+  AND (sqlc.narg(status)::varchar IS NULL OR status = sqlc.narg(status))
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueWebhookDeliveries :many
+SELECT d.id, d.subscription_id, d.event_id, d.event_type, d.attempts, s.url, s.secret,
+  o.account_id, o.payload, o.created_at AS event_created_at
+FROM webhook_deliveries d
+JOIN webhook_subscriptions s ON s.id = d.subscription_id
+JOIN outbox o ON o.id = d.event_id
+WHERE d.status = 'pending' AND s.active AND d.next_attempt_at <= $1
+ORDER BY d.next_attempt_at
+LIMIT $2
+FOR UPDATE OF d SKIP LOCKED;
+
+-- name: LeaseWebhookDeliveries :exec
+UPDATE webhook_deliveries
+SET next_attempt_at = sqlc.arg(leased_until)
+WHERE id = ANY(sqlc.arg(ids)::bigint[]);
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6, delivered_at = $7
+WHERE id = $1 AND status = 'pending' AND attempts = sqlc.arg(claimed_attempts)
+RETURNING *;
+
+-- name: RetryWebhookDelivery :one
+UPDATE webhook_deliveries
+SET status = 'pending', attempts = 0, next_attempt_at = now()
+WHERE id = $1 AND status = 'dead'
+RETURNING *;
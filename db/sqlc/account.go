@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	AccountKindCustomer = "customer"
+	AccountKindCash     = "cash"
+	AccountKindFX       = "fx"
+)
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	// Idempotency, when set, stores the new account under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// CreateAccountTx opens an account together with its outbox event and audit record
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+		if err := addOutboxEvent(ctx, q, EventAccountCreated, account.ID, account); err != nil {
+			return err
+		}
+
+		if arg.Idempotency != nil {
+			if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, account); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.create",
+			ResourceType: "account",
+			ResourceID:   auditID(account.ID),
+			After:        account,
+		})
+	})
+
+	// a concurrent retry with the same key waits on the winner's account row and then fails on the
+	// owner and currency index before it gets to claim the key, so it is reported as a duplicate key
+	if isConstraintViolation(err, accountsOwnerCurrencyKey) && arg.Idempotency != nil {
+		_, keyErr := store.GetIdempotencyKey(ctx, GetIdempotencyKeyParams{
+			Username: arg.Idempotency.Username,
+			Key:      arg.Idempotency.Key,
+		})
+		if keyErr == nil {
+			return account, ErrDuplicateIdempotencyKey
+		}
+	}
+
+	return account, err
+}
+
+// CloseAccountTxParams identifies the account to close. A remaining positive balance is moved to
+// SweepToAccountID, which must hold the same currency; without one the balance has to be zero.
+type CloseAccountTxParams struct {
+	AccountID        int64 `json:"account_id"`
+	SweepToAccountID int64 `json:"sweep_to_account_id"`
+}
+
+type CloseAccountTxResult struct {
+	Account Account `json:"account"`
+	// Sweep is only set when a remaining balance was moved out of the account
+	Sweep *TransferTxResult `json:"sweep,omitempty"`
+}
+
+// CloseAccountTx closes an active account, sweeping what is left of its balance in the same transaction
+func (store *SQLStore) CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error) {
+	var result CloseAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		// lock the sweep account together with the closing one, in lockAccounts order, so the sweep
+		// below cannot deadlock with a transfer between the two accounts
+		accountIDs := []int64{arg.AccountID}
+		if arg.SweepToAccountID != 0 {
+			accountIDs = append(accountIDs, arg.SweepToAccountID)
+		}
+		locked, err := lockAccounts(ctx, q, accountIDs...)
+		if err != nil {
+			return err
+		}
+
+		account := locked[arg.AccountID]
+		if account.Status != AccountStatusActive {
+			return &AccountStatusError{AccountID: account.ID, Status: account.Status}
+		}
+
+		if account.AvailableBalance != account.Balance {
+			return ErrActiveHolds
+		}
+
+		if account.Balance != 0 {
+			if account.Balance < 0 || arg.SweepToAccountID == 0 {
+				return ErrNonZeroBalance
+			}
+
+			sweepAccount := locked[arg.SweepToAccountID]
+			if sweepAccount.Currency != account.Currency {
+				return ErrSweepCurrencyMismatch
+			}
+
+			sweep, err := postTransfer(ctx, q, CrossCurrencyTransferTxParams{
+				FromAccountID: account.ID,
+				ToAccountID:   sweepAccount.ID,
+				Amount:        account.Balance,
+				ToAmount:      account.Balance,
+				ExchangeRate:  "1",
+			})
+			if err != nil {
+				return err
+			}
+			result.Sweep = &sweep
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:         account.ID,
+			FromStatus: AccountStatusActive,
+			ToStatus:   AccountStatusClosed,
+		})
+		if err == sql.ErrNoRows {
+			return &AccountStatusError{AccountID: account.ID, Status: account.Status}
+		}
+		if err != nil {
+			return err
+		}
+
+		var events []auditEvent
+		if result.Sweep != nil {
+			events = append(events, transferAuditEvent("account.sweep", *result.Sweep))
+		}
+		events = append(events, auditEvent{
+			Action:       "account.close",
+			ResourceType: "account",
+			ResourceID:   auditID(account.ID),
+			Before:       account,
+			After:        result.Account,
+		})
+		return recordAudit(ctx, q, events...)
+	})
+
+	return result, err
+}
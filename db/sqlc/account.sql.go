@@ -7,13 +7,43 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
+const addAccountAvailableBalance = `-- name: AddAccountAvailableBalance :one
+UPDATE accounts
+set available_balance = available_balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
+`
+
+type AddAccountAvailableBalanceParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, addAccountAvailableBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
-set balance = balance + $1
+set balance = balance + $1, available_balance = available_balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
 `
 
 type AddAccountBalanceParams struct {
@@ -30,17 +60,21 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
-  owner, balance, currency
+  owner, balance, available_balance, currency
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $2, $3
 )
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
 `
 
 type CreateAccountParams struct {
@@ -58,6 +92,10 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -73,7 +111,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -86,12 +124,40 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const getAccountForUpdate = `-- name: GetAccountForUpdate :one
+SELECT id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance FROM accounts
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getAccountForUpdate, id)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance FROM accounts
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2
 `
@@ -116,6 +182,109 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Kind,
+			&i.Status,
+			&i.AvailableBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwner = `-- name: ListAccountsByOwner :many
+SELECT id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance FROM accounts
+WHERE owner = $1
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3
+`
+
+type ListAccountsByOwnerParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByOwner, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Kind,
+			&i.Status,
+			&i.AvailableBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwnerAfter = `-- name: ListAccountsByOwnerAfter :many
+SELECT id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance FROM accounts
+WHERE owner = $2 AND (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type ListAccountsByOwnerAfterParams struct {
+	Limit          int32     `json:"limit"`
+	Owner          string    `json:"owner"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+func (q *Queries) ListAccountsByOwnerAfter(ctx context.Context, arg ListAccountsByOwnerAfterParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByOwnerAfter,
+		arg.Limit,
+		arg.Owner,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.OverdraftLimit,
+			&i.Kind,
+			&i.Status,
+			&i.AvailableBalance,
 		); err != nil {
 			return nil, err
 		}
@@ -132,9 +301,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 
 const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
-set balance = $2
+set balance = $2, available_balance = available_balance + $2 - balance
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
 `
 
 type UpdateAccountParams struct {
@@ -151,6 +320,106 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const updateAccountStatus = `-- name: UpdateAccountStatus :one
+UPDATE accounts
+SET status = $1
+WHERE id = $2 AND status = $3
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
+`
+
+type UpdateAccountStatusParams struct {
+	ToStatus   string `json:"to_status"`
+	ID         int64  `json:"id"`
+	FromStatus string `json:"from_status"`
+}
+
+func (q *Queries) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, updateAccountStatus, arg.ToStatus, arg.ID, arg.FromStatus)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const upsertCashAccount = `-- name: UpsertCashAccount :one
+INSERT INTO accounts (
+  owner, balance, currency, kind
+) VALUES (
+  $1, 0, $2, 'cash'
+)
+ON CONFLICT (owner, currency) WHERE status <> 'closed' DO UPDATE
+SET kind = EXCLUDED.kind
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
+`
+
+type UpsertCashAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) UpsertCashAccount(ctx context.Context, arg UpsertCashAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, upsertCashAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
+	)
+	return i, err
+}
+
+const upsertFXAccount = `-- name: UpsertFXAccount :one
+INSERT INTO accounts (
+  owner, balance, currency, kind
+) VALUES (
+  $1, 0, $2, 'fx'
+)
+ON CONFLICT (owner, currency) WHERE status <> 'closed' DO UPDATE
+SET kind = EXCLUDED.kind
+RETURNING id, owner, balance, currency, created_at, overdraft_limit, kind, status, available_balance
+`
+
+type UpsertFXAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) UpsertFXAccount(ctx context.Context, arg UpsertFXAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, upsertFXAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.OverdraftLimit,
+		&i.Kind,
+		&i.Status,
+		&i.AvailableBalance,
 	)
 	return i, err
 }
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"testing"
 	"time"
 
@@ -12,10 +13,12 @@ import (
 )
 
 func createRandomAccount(t *testing.T) Account {
+	user := createRandomUser(t)
+
 	arg := CreateAccountParams{
-		Owner:    utils.RandomOwner(),
+		Owner:    user.Username,
 		Balance:  utils.RandomMoney(),
-		Currency: utils.RandomCurrency(),
+		Currency: randomCurrency(t),
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -23,7 +26,10 @@ func createRandomAccount(t *testing.T) Account {
 	require.NotEmpty(t, account)
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
+	require.Equal(t, arg.Balance, account.AvailableBalance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, AccountKindCustomer, account.Kind)
+	require.Equal(t, AccountStatusActive, account.Status)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
@@ -35,6 +41,46 @@ func TestCreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
 
+func TestCreateAccountTxIdempotency(t *testing.T) {
+	user := createRandomUser(t)
+	store := NewStore(testDb)
+
+	arg := CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: randomCurrency(t),
+		},
+		Idempotency: &IdempotencyParams{
+			Username:     user.Username,
+			Key:          utils.RandomString(16),
+			RequestHash:  utils.RandomString(64),
+			ResponseCode: 201,
+		},
+	}
+
+	account, err := store.CreateAccountTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: arg.Idempotency.Username,
+		Key:      arg.Idempotency.Key,
+	})
+	require.NoError(t, err)
+
+	var storedAccount Account
+	require.NoError(t, json.Unmarshal(stored.ResponseBody, &storedAccount))
+	require.Equal(t, account.ID, storedAccount.ID)
+
+	// the retry loses to the account the first request opened and is told the key was used
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+
+	// without a key the same account is a plain conflict
+	arg.Idempotency = nil
+	_, err = store.CreateAccountTx(context.Background(), arg)
+	require.True(t, isConstraintViolation(err, accountsOwnerCurrencyKey))
+}
+
 func TestGetAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 
@@ -118,3 +164,136 @@ func TestAddAccountBalance(t *testing.T) {
 
 	require.Equal(t, account1.Balance+arg.Amount, account2.Balance)
 }
+
+func TestListAccountsByOwner(t *testing.T) {
+	var lastAccount Account
+	for x := 0; x < 10; x++ {
+		lastAccount = createRandomAccount(t)
+	}
+
+	arg := ListAccountsByOwnerParams{
+		Owner:  lastAccount.Owner,
+		Limit:  5,
+		Offset: 0,
+	}
+
+	accounts, err := testQueries.ListAccountsByOwner(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, accounts)
+
+	for _, account := range accounts {
+		require.NotEmpty(t, account)
+		require.Equal(t, lastAccount.Owner, account.Owner)
+	}
+}
+
+func TestGetAccountForUpdate(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2, err := testQueries.GetAccountForUpdate(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1, account2)
+}
+
+func TestUpdateAccountStatus(t *testing.T) {
+	account := createRandomAccount(t)
+
+	frozen, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:         account.ID,
+		FromStatus: AccountStatusActive,
+		ToStatus:   AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, frozen.Status)
+
+	_, err = testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:         account.ID,
+		FromStatus: AccountStatusActive,
+		ToStatus:   AccountStatusClosed,
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestCloseAccountTx(t *testing.T) {
+	store := NewStore(testDb)
+	account := fundAccount(t, createRandomAccount(t), 0)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Nil(t, result.Sweep)
+
+	_, err = store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	// the owner can open a new account in the same currency once the old one is closed
+	_, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+}
+
+func TestCloseAccountTxSweep(t *testing.T) {
+	store := NewStore(testDb)
+	account := fundAccount(t, createRandomAccount(t), 100)
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{AccountID: account.ID})
+	require.ErrorIs(t, err, ErrNonZeroBalance)
+
+	sweepAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	result, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: sweepAccount.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+	require.Zero(t, result.Account.Balance)
+	require.NotNil(t, result.Sweep)
+	require.Equal(t, int64(100), result.Sweep.Transfer.Amount)
+	require.Equal(t, sweepAccount.Balance+100, result.Sweep.ToAccount.Balance)
+}
+
+func TestCloseAccountTxSweepDeadlock(t *testing.T) {
+	n := 10
+	store := NewStore(testDb)
+	account := fundAccount(t, createRandomAccount(t), 100)
+	// the sweep account has the higher id, the one transfers between the two lock first
+	sweepAccount := fundAccount(t, createAccountInCurrency(t, account.Currency), 100)
+
+	errs := make(chan error)
+	go func() {
+		_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+			AccountID:        account.ID,
+			SweepToAccountID: sweepAccount.ID,
+		})
+		errs <- err
+	}()
+	for x := 0; x < n; x++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: sweepAccount.ID,
+				ToAccountID:   account.ID,
+				Amount:        1,
+			})
+			errs <- err
+		}()
+	}
+
+	for x := 0; x <= n; x++ {
+		err := <-errs
+		// transfers that come after the close find the account closed
+		if err != nil {
+			require.ErrorIs(t, err, ErrAccountNotActive)
+		}
+	}
+
+	closed, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, closed.Status)
+	require.Zero(t, closed.Balance)
+}
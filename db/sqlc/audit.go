@@ -0,0 +1,223 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	// AuditActorSystem is recorded for changes made without an AuditContext, such as those of the workers
+	AuditActorSystem = "system"
+	// AuditActorAnonymous is recorded for requests made without logging in
+	AuditActorAnonymous = "anonymous"
+)
+
+const defaultAuditVerifyBatchSize = 1000
+
+// AuditContext identifies who made a change and through which request. Every audit event written under a
+// context carrying one records it.
+type AuditContext struct {
+	Actor     string
+	RequestID string
+	ClientIP  string
+}
+
+type auditContextKey struct{}
+
+func WithAuditContext(ctx context.Context, audit AuditContext) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, audit)
+}
+
+// AuditContextFrom returns the AuditContext of ctx, or one for the system actor when there is none
+func AuditContextFrom(ctx context.Context) AuditContext {
+	if audit, ok := ctx.Value(auditContextKey{}).(AuditContext); ok {
+		return audit
+	}
+	return AuditContext{Actor: AuditActorSystem}
+}
+
+// auditEvent is a change to be recorded. Before is nil when the resource was created and After when it
+// was deleted.
+type auditEvent struct {
+	Action       string
+	ResourceType string
+	ResourceID   string
+	Before       interface{}
+	After        interface{}
+}
+
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
+
+// transferAuditEvent records a posted transfer with its entries and the accounts it left behind
+func transferAuditEvent(action string, result TransferTxResult) auditEvent {
+	return auditEvent{
+		Action:       action,
+		ResourceType: "transfer",
+		ResourceID:   auditID(result.Transfer.ID),
+		After:        result,
+	}
+}
+
+// recordAudit appends events to the audit chain. It has to be the last write of a transaction: the chain
+// lock it takes is held until commit, so row locks taken after it could deadlock with other writers. It
+// relies on read committed isolation to see the event the previous holder of the lock appended.
+func recordAudit(ctx context.Context, q *Queries, events ...auditEvent) error {
+	if err := q.LockAuditChain(ctx); err != nil {
+		return err
+	}
+
+	var prevHash string
+	last, err := q.GetLastAuditEvent(ctx)
+	switch {
+	case err == nil:
+		prevHash = last.Hash
+	case err != sql.ErrNoRows:
+		return err
+	}
+
+	audit := AuditContextFrom(ctx)
+	// truncated to what postgres stores, so the hash can be recomputed from the row
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	for _, event := range events {
+		before, err := json.Marshal(event.Before)
+		if err != nil {
+			return err
+		}
+		after, err := json.Marshal(event.After)
+		if err != nil {
+			return err
+		}
+
+		arg := CreateAuditEventParams{
+			Actor:        audit.Actor,
+			Action:       event.Action,
+			ResourceType: event.ResourceType,
+			ResourceID:   event.ResourceID,
+			Before:       before,
+			After:        after,
+			RequestID:    audit.RequestID,
+			ClientIp:     audit.ClientIP,
+			PrevHash:     prevHash,
+			CreatedAt:    createdAt,
+		}
+		arg.Hash = auditHash(arg)
+
+		if _, err := q.CreateAuditEvent(ctx, arg); err != nil {
+			return err
+		}
+		prevHash = arg.Hash
+	}
+	return nil
+}
+
+// auditHash covers the previous hash and every column of an event except id and the hash itself. Fields are
+// length prefixed so that moving text from one field into the next changes the hash.
+func auditHash(arg CreateAuditEventParams) string {
+	hash := sha256.New()
+	for _, field := range []string{
+		arg.PrevHash,
+		arg.Actor,
+		arg.Action,
+		arg.ResourceType,
+		arg.ResourceID,
+		string(arg.Before),
+		string(arg.After),
+		arg.RequestID,
+		arg.ClientIp,
+		arg.CreatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		fmt.Fprintf(hash, "%d:%s", len(field), field)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// AuditVerification is the outcome of checking the audit chain
+type AuditVerification struct {
+	Valid   bool  `json:"valid"`
+	Checked int64 `json:"checked"`
+	// LastID and LastHash identify the last event that checked out. Keeping LastHash elsewhere allows to
+	// detect events removed from the end of the chain later on.
+	LastID   int64  `json:"last_id"`
+	LastHash string `json:"last_hash"`
+	// BrokenAt is the first event that does not match its hash or does not link to the one before it
+	BrokenAt int64  `json:"broken_at,omitempty"`
+	Problem  string `json:"problem,omitempty"`
+}
+
+// VerifyAuditChain recomputes the hash of every audit event and checks that each one links to the event
+// before it. An event that was changed, removed or inserted breaks the chain from that event on. Everything
+// is read from one snapshot, so events appended meanwhile are left for the next check.
+func (store *SQLStore) VerifyAuditChain(ctx context.Context, batchSize int32) (AuditVerification, error) {
+	verification := AuditVerification{Valid: true}
+	if batchSize <= 0 {
+		batchSize = defaultAuditVerifyBatchSize
+	}
+
+	opts := &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+	err := store.execTxOptions(ctx, opts, func(q *Queries) error {
+		for verification.Valid {
+			events, err := q.ListAuditEventsAfter(ctx, ListAuditEventsAfterParams{
+				ID:    verification.LastID,
+				Limit: batchSize,
+			})
+			if err != nil {
+				return err
+			}
+
+			verifyAuditEvents(&verification, events)
+			if len(events) < int(batchSize) {
+				return nil
+			}
+		}
+		return nil
+	})
+
+	return verification, err
+}
+
+// verifyAuditEvents continues a verification with the next events of the chain, stopping at the first
+// one that does not check out
+func verifyAuditEvents(verification *AuditVerification, events []AuditEvent) {
+	for _, event := range events {
+		switch {
+		case event.PrevHash != verification.LastHash:
+			verification.Problem = "event does not link to the previous event"
+		case event.Hash != auditHash(auditEventParams(event)):
+			verification.Problem = "event does not match its hash"
+		default:
+			verification.Checked++
+			verification.LastID = event.ID
+			verification.LastHash = event.Hash
+			continue
+		}
+
+		verification.Valid = false
+		verification.BrokenAt = event.ID
+		return
+	}
+}
+
+func auditEventParams(event AuditEvent) CreateAuditEventParams {
+	return CreateAuditEventParams{
+		Actor:        event.Actor,
+		Action:       event.Action,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Before:       event.Before,
+		After:        event.After,
+		RequestID:    event.RequestID,
+		ClientIp:     event.ClientIp,
+		PrevHash:     event.PrevHash,
+		Hash:         event.Hash,
+		CreatedAt:    event.CreatedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func listResourceAuditEvents(t *testing.T, resourceType, resourceID string) []AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		Limit:        10,
+		ResourceType: sql.NullString{String: resourceType, Valid: true},
+		ResourceID:   sql.NullString{String: resourceID, Valid: true},
+	})
+	require.NoError(t, err)
+	return events
+}
+
+func TestAuditedUpdateAccount(t *testing.T) {
+	store := NewStore(testDb)
+	account := createRandomAccount(t)
+
+	audit := AuditContext{Actor: account.Owner, RequestID: uuid.NewString(), ClientIP: "192.0.2.1"}
+	ctx := WithAuditContext(context.Background(), audit)
+
+	updated, err := store.UpdateAccount(ctx, UpdateAccountParams{ID: account.ID, Balance: 42})
+	require.NoError(t, err)
+
+	events := listResourceAuditEvents(t, "account", auditID(account.ID))
+	require.Len(t, events, 1)
+	event := events[0]
+	require.Equal(t, audit.Actor, event.Actor)
+	require.Equal(t, audit.RequestID, event.RequestID)
+	require.Equal(t, audit.ClientIP, event.ClientIp)
+	require.Equal(t, "account.update", event.Action)
+
+	var before, after Account
+	require.NoError(t, json.Unmarshal(event.Before, &before))
+	require.NoError(t, json.Unmarshal(event.After, &after))
+	require.Equal(t, account.Balance, before.Balance)
+	require.Equal(t, updated.Balance, after.Balance)
+
+	require.Equal(t, auditHash(auditEventParams(event)), event.Hash)
+}
+
+func TestAuditedCreateUserOmitsPassword(t *testing.T) {
+	store := NewStore(testDb)
+
+	user, err := store.CreateUser(context.Background(), CreateUserParams{
+		Username:       utils.RandomOwner(),
+		HashedPassword: "secret-hash",
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	})
+	require.NoError(t, err)
+
+	events := listResourceAuditEvents(t, "user", user.Username)
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+	require.JSONEq(t, "null", string(events[0].Before))
+	require.NotContains(t, string(events[0].After), "secret-hash")
+}
+
+func TestTransferTxAudit(t *testing.T) {
+	store := NewStore(testDb)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := listResourceAuditEvents(t, "transfer", auditID(result.Transfer.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, "transfer.create", events[0].Action)
+
+	var after TransferTxResult
+	require.NoError(t, json.Unmarshal(events[0].After, &after))
+	require.Equal(t, result.FromAccount.Balance, after.FromAccount.Balance)
+	require.Equal(t, result.ToAccount.Balance, after.ToAccount.Balance)
+}
+
+func TestAuditEventsAppendOnly(t *testing.T) {
+	store := NewStore(testDb)
+	account := createRandomAccount(t)
+	_, err := store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 1})
+	require.NoError(t, err)
+
+	event := listResourceAuditEvents(t, "account", auditID(account.ID))[0]
+
+	_, err = testDb.Exec("UPDATE audit_events SET actor = 'someone' WHERE id = $1", event.ID)
+	require.Error(t, err)
+	_, err = testDb.Exec("DELETE FROM audit_events WHERE id = $1", event.ID)
+	require.Error(t, err)
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	store := NewStore(testDb)
+	account := createRandomAccount(t)
+	_, err := store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 1})
+	require.NoError(t, err)
+	event := listResourceAuditEvents(t, "account", auditID(account.ID))[0]
+
+	verification, err := store.VerifyAuditChain(context.Background(), 2)
+	require.NoError(t, err)
+	require.True(t, verification.Valid)
+	require.GreaterOrEqual(t, verification.LastID, event.ID)
+	require.NotEmpty(t, verification.LastHash)
+	require.Zero(t, verification.BrokenAt)
+}
+
+func TestVerifyAuditEventsTampered(t *testing.T) {
+	store := NewStore(testDb)
+	account := createRandomAccount(t)
+	for balance := int64(1); balance <= 3; balance++ {
+		_, err := store.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: balance})
+		require.NoError(t, err)
+	}
+
+	events := listResourceAuditEvents(t, "account", auditID(account.ID))
+	require.Len(t, events, 3)
+	events = []AuditEvent{events[2], events[1], events[0]}
+
+	// picks up the chain right before the first event, as if everything up to it had checked out
+	start := AuditVerification{Valid: true, LastHash: events[0].PrevHash}
+
+	verification := start
+	verifyAuditEvents(&verification, events[:1])
+	require.True(t, verification.Valid)
+
+	tampered := make([]AuditEvent, len(events))
+	copy(tampered, events)
+	tampered[0].After = json.RawMessage(`{"balance":1000000}`)
+
+	verification = start
+	verifyAuditEvents(&verification, tampered)
+	require.False(t, verification.Valid)
+	require.Equal(t, events[0].ID, verification.BrokenAt)
+	require.Zero(t, verification.Checked)
+
+	// the first event went missing
+	verification = start
+	verifyAuditEvents(&verification, events[1:])
+	require.False(t, verification.Valid)
+	require.Equal(t, events[1].ID, verification.BrokenAt)
+}
@@ -0,0 +1,584 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/google/uuid"
+)
+
+// The methods below shadow the queries of the same name that change data the audit log covers: each runs
+// the query and records an audit event in one transaction. Transactions such as CrossCurrencyTransferTx
+// record their own events and keep using the plain queries.
+
+// auditedUser is a user as it goes into the audit log, without the password hash
+type auditedUser struct {
+	Username          string `json:"username"`
+	FullName          string `json:"full_name"`
+	Email             string `json:"email"`
+	Role              string `json:"role"`
+	Tier              string `json:"tier"`
+	PasswordChangedAt string `json:"password_changed_at"`
+}
+
+func auditUser(user User) auditedUser {
+	return auditedUser{
+		Username:          user.Username,
+		FullName:          user.FullName,
+		Email:             user.Email,
+		Role:              user.Role,
+		Tier:              user.Tier,
+		PasswordChangedAt: user.PasswordChangedAt.UTC().String(),
+	}
+}
+
+// auditSession leaves the refresh token out of the audit log
+func auditSession(session Session) Session {
+	session.RefreshToken = ""
+	return session
+}
+
+// auditBefore turns the state of a resource read before it changes into the before side of an event. A
+// resource that does not exist yet has none.
+func auditBefore[T any](row T, err error) (interface{}, error) {
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+func (store *SQLStore) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	return store.CreateAccountTx(ctx, CreateAccountTxParams{CreateAccountParams: arg})
+}
+
+func (store *SQLStore) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		account, err = q.UpdateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.update",
+			ResourceType: "account",
+			ResourceID:   auditID(account.ID),
+			Before:       before,
+			After:        account,
+		})
+	})
+
+	return account, err
+}
+
+func (store *SQLStore) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		account, err = q.AddAccountBalance(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.add_balance",
+			ResourceType: "account",
+			ResourceID:   auditID(account.ID),
+			Before:       before,
+			After:        account,
+		})
+	})
+
+	return account, err
+}
+
+func (store *SQLStore) UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		account, err = q.UpdateAccountStatus(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.update_status",
+			ResourceType: "account",
+			ResourceID:   auditID(account.ID),
+			Before:       before,
+			After:        account,
+		})
+	})
+
+	return account, err
+}
+
+func (store *SQLStore) DeleteAccount(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetAccountForUpdate(ctx, id))
+		if err != nil || before == nil {
+			return err
+		}
+		if err := q.DeleteAccount(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account.delete",
+			ResourceType: "account",
+			ResourceID:   auditID(id),
+			Before:       before,
+		})
+	})
+}
+
+func (store *SQLStore) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		user, err = q.CreateUser(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "user.create",
+			ResourceType: "user",
+			ResourceID:   user.Username,
+			After:        auditUser(user),
+		})
+	})
+
+	return user, err
+}
+
+func (store *SQLStore) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUserPassword(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "user.update_password",
+			ResourceType: "user",
+			ResourceID:   user.Username,
+			Before:       auditUser(before),
+			After:        auditUser(user),
+		})
+	})
+
+	return user, err
+}
+
+func (store *SQLStore) UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+		user, err = q.UpdateUserTier(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "user.update_tier",
+			ResourceType: "user",
+			ResourceID:   user.Username,
+			Before:       auditUser(before),
+			After:        auditUser(user),
+		})
+	})
+
+	return user, err
+}
+
+func (store *SQLStore) CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		session, err = q.CreateSession(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "session.create",
+			ResourceType: "session",
+			ResourceID:   session.ID.String(),
+			After:        auditSession(session),
+		})
+	})
+
+	return session, err
+}
+
+func (store *SQLStore) BlockSession(ctx context.Context, id uuid.UUID) (Session, error) {
+	var session Session
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetSession(ctx, id)
+		if err != nil {
+			return err
+		}
+		session, err = q.BlockSession(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "session.block",
+			ResourceType: "session",
+			ResourceID:   session.ID.String(),
+			Before:       auditSession(before),
+			After:        auditSession(session),
+		})
+	})
+
+	return session, err
+}
+
+func (store *SQLStore) CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error) {
+	var currency Currency
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		currency, err = q.CreateCurrency(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "currency.create",
+			ResourceType: "currency",
+			ResourceID:   currency.Code,
+			After:        currency,
+		})
+	})
+
+	return currency, err
+}
+
+func (store *SQLStore) SetCurrencyEnabled(ctx context.Context, arg SetCurrencyEnabledParams) (Currency, error) {
+	var currency Currency
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetCurrency(ctx, arg.Code)
+		if err != nil {
+			return err
+		}
+		currency, err = q.SetCurrencyEnabled(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "currency.set_enabled",
+			ResourceType: "currency",
+			ResourceID:   currency.Code,
+			Before:       before,
+			After:        currency,
+		})
+	})
+
+	return currency, err
+}
+
+func (store *SQLStore) UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error) {
+	var rate ExchangeRate
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetExchangeRate(ctx, GetExchangeRateParams{
+			BaseCurrency:  arg.BaseCurrency,
+			QuoteCurrency: arg.QuoteCurrency,
+		}))
+		if err != nil {
+			return err
+		}
+		rate, err = q.UpsertExchangeRate(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "exchange_rate.upsert",
+			ResourceType: "exchange_rate",
+			ResourceID:   rate.BaseCurrency + "/" + rate.QuoteCurrency,
+			Before:       before,
+			After:        rate,
+		})
+	})
+
+	return rate, err
+}
+
+func (store *SQLStore) CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error) {
+	return store.CreateScheduledTransferTx(ctx, CreateScheduledTransferTxParams{CreateScheduledTransferParams: arg})
+}
+
+func (store *SQLStore) UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetScheduledTransfer(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		scheduled, err = q.UpdateScheduledTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "scheduled_transfer.update",
+			ResourceType: "scheduled_transfer",
+			ResourceID:   auditID(scheduled.ID),
+			Before:       before,
+			After:        scheduled,
+		})
+	})
+
+	return scheduled, err
+}
+
+func (store *SQLStore) DeleteScheduledTransfer(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetScheduledTransfer(ctx, id))
+		if err != nil || before == nil {
+			return err
+		}
+		if err := q.DeleteScheduledTransfer(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "scheduled_transfer.delete",
+			ResourceType: "scheduled_transfer",
+			ResourceID:   auditID(id),
+			Before:       before,
+		})
+	})
+}
+
+func (store *SQLStore) UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (AccountTransferLimit, error) {
+	var limit AccountTransferLimit
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetAccountTransferLimit(ctx, arg.AccountID))
+		if err != nil {
+			return err
+		}
+		limit, err = q.UpsertAccountTransferLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account_transfer_limit.upsert",
+			ResourceType: "account_transfer_limit",
+			ResourceID:   auditID(limit.AccountID),
+			Before:       before,
+			After:        limit,
+		})
+	})
+
+	return limit, err
+}
+
+func (store *SQLStore) DeleteAccountTransferLimit(ctx context.Context, accountID int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetAccountTransferLimit(ctx, accountID))
+		if err != nil || before == nil {
+			return err
+		}
+		if err := q.DeleteAccountTransferLimit(ctx, accountID); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "account_transfer_limit.delete",
+			ResourceType: "account_transfer_limit",
+			ResourceID:   auditID(accountID),
+			Before:       before,
+		})
+	})
+}
+
+func (store *SQLStore) UpsertTierTransferLimit(ctx context.Context, arg UpsertTierTransferLimitParams) (TierTransferLimit, error) {
+	var limit TierTransferLimit
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetTierTransferLimit(ctx, GetTierTransferLimitParams{
+			Tier:     arg.Tier,
+			Currency: arg.Currency,
+		}))
+		if err != nil {
+			return err
+		}
+		limit, err = q.UpsertTierTransferLimit(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "tier_transfer_limit.upsert",
+			ResourceType: "tier_transfer_limit",
+			ResourceID:   limit.Tier + "/" + limit.Currency,
+			Before:       before,
+			After:        limit,
+		})
+	})
+
+	return limit, err
+}
+
+func (store *SQLStore) DeleteTierTransferLimit(ctx context.Context, arg DeleteTierTransferLimitParams) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := auditBefore(q.GetTierTransferLimit(ctx, GetTierTransferLimitParams(arg)))
+		if err != nil || before == nil {
+			return err
+		}
+		if err := q.DeleteTierTransferLimit(ctx, arg); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "tier_transfer_limit.delete",
+			ResourceType: "tier_transfer_limit",
+			ResourceID:   arg.Tier + "/" + arg.Currency,
+			Before:       before,
+		})
+	})
+}
+
+func (store *SQLStore) CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error) {
+	return store.CreatePendingTransferTx(ctx, CreatePendingTransferTxParams{CreatePendingTransferParams: arg})
+}
+
+func (store *SQLStore) ReviewPendingTransfer(ctx context.Context, arg ReviewPendingTransferParams) (PendingTransfer, error) {
+	var pending PendingTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetPendingTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		pending, err = q.ReviewPendingTransfer(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "pending_transfer.review",
+			ResourceType: "pending_transfer",
+			ResourceID:   auditID(pending.ID),
+			Before:       before,
+			After:        pending,
+		})
+	})
+
+	return pending, err
+}
+
+// auditWebhookSubscription leaves the signing secret out of the audit log
+func auditWebhookSubscription(subscription WebhookSubscription) WebhookSubscription {
+	subscription.Secret = ""
+	return subscription
+}
+
+func (store *SQLStore) CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		subscription, err = q.CreateWebhookSubscription(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "webhook_subscription.create",
+			ResourceType: "webhook_subscription",
+			ResourceID:   auditID(subscription.ID),
+			After:        auditWebhookSubscription(subscription),
+		})
+	})
+
+	return subscription, err
+}
+
+func (store *SQLStore) UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error) {
+	var subscription WebhookSubscription
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetWebhookSubscription(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		subscription, err = q.UpdateWebhookSubscription(ctx, arg)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "webhook_subscription.update",
+			ResourceType: "webhook_subscription",
+			ResourceID:   auditID(subscription.ID),
+			Before:       auditWebhookSubscription(before),
+			After:        auditWebhookSubscription(subscription),
+		})
+	})
+
+	return subscription, err
+}
+
+func (store *SQLStore) DeleteWebhookSubscription(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetWebhookSubscription(ctx, id)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := q.DeleteWebhookSubscription(ctx, id); err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "webhook_subscription.delete",
+			ResourceType: "webhook_subscription",
+			ResourceID:   auditID(id),
+			Before:       auditWebhookSubscription(before),
+		})
+	})
+}
+
+func (store *SQLStore) RetryWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetWebhookDelivery(ctx, id)
+		if err != nil {
+			return err
+		}
+		delivery, err = q.RetryWebhookDelivery(ctx, id)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "webhook_delivery.retry",
+			ResourceType: "webhook_delivery",
+			ResourceID:   auditID(delivery.ID),
+			Before:       before,
+			After:        delivery,
+		})
+	})
+
+	return delivery, err
+}
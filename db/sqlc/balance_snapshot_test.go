@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetAccountBalanceAsOf(t *testing.T) {
+	store := NewStore(testDb)
+	account, _ := createBatchAccounts(t, 0, 0)
+	created := time.Now()
+
+	_, err := store.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+	mid := time.Now()
+	_, err = store.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 50})
+	require.NoError(t, err)
+
+	balanceAt := func(asOf time.Time) int64 {
+		balance, err := testQueries.GetAccountBalanceAsOf(context.Background(), GetAccountBalanceAsOfParams{
+			AsOf:      asOf,
+			AccountID: account.ID,
+		})
+		require.NoError(t, err)
+		return balance
+	}
+
+	require.Zero(t, balanceAt(created.Add(-time.Second)))
+	require.Equal(t, int64(100), balanceAt(mid))
+	require.Equal(t, int64(150), balanceAt(time.Now()))
+
+	// the same balances come out once a snapshot covers part of the history
+	rows, err := testQueries.CreateBalanceSnapshots(context.Background(), mid)
+	require.NoError(t, err)
+	require.NotZero(t, rows)
+
+	require.Zero(t, balanceAt(created.Add(-time.Second)))
+	require.Equal(t, int64(100), balanceAt(mid))
+	require.Equal(t, int64(150), balanceAt(time.Now()))
+}
+
+func TestListEntriesWithBalanceForAccount(t *testing.T) {
+	store := NewStore(testDb)
+	account, _ := createBatchAccounts(t, 0, 0)
+
+	for _, amount := range []int64{100, 50, 25} {
+		_, err := store.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: amount})
+		require.NoError(t, err)
+	}
+	_, err := store.WithdrawTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 75})
+	require.NoError(t, err)
+
+	entries, err := testQueries.ListEntriesWithBalanceForAccount(context.Background(), ListEntriesWithBalanceForAccountParams{
+		AccountID: account.ID,
+		Limit:     2,
+		Offset:    2,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, int64(25), entries[0].Amount)
+	require.Equal(t, int64(175), entries[0].RunningBalance)
+	require.Equal(t, int64(-75), entries[1].Amount)
+	require.Equal(t, int64(100), entries[1].RunningBalance)
+}
@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type BatchTransferLeg struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+}
+
+type BatchTransferTxParams struct {
+	Legs []BatchTransferLeg `json:"legs"`
+	// Idempotency, when set, stores the result under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+type BatchTransferTxResult struct {
+	Batch TransferBatch `json:"batch"`
+	// Transfers holds the result of every leg, in the order the legs were given
+	Transfers []TransferTxResult `json:"transfers"`
+}
+
+// BatchTransferTx posts every leg as its own transfer tagged with a shared batch id. Either all legs are
+// posted or none: a leg that fails rolls back the whole batch, and the error names the leg that failed.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		ids := make([]int64, 0, 2*len(arg.Legs))
+		for _, leg := range arg.Legs {
+			ids = append(ids, leg.FromAccountID, leg.ToAccountID)
+		}
+
+		// take every lock up front so the legs below cannot deadlock with a transfer or another batch
+		accounts, err := lockAccounts(ctx, q, ids...)
+		if err != nil {
+			return err
+		}
+		for i, leg := range arg.Legs {
+			if accounts[leg.FromAccountID].Currency != accounts[leg.ToAccountID].Currency {
+				return fmt.Errorf("leg %d: %w", i, ErrBatchCurrencyMismatch)
+			}
+		}
+
+		result.Batch, err = q.CreateTransferBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		result.Transfers = make([]TransferTxResult, 0, len(arg.Legs))
+		for i, leg := range arg.Legs {
+			legArg := CrossCurrencyTransferTxParams{
+				FromAccountID: leg.FromAccountID,
+				ToAccountID:   leg.ToAccountID,
+				Amount:        leg.Amount,
+				ToAmount:      leg.Amount,
+				ExchangeRate:  "1",
+				BatchID:       sql.NullInt64{Int64: result.Batch.ID, Valid: true},
+			}
+			// earlier legs are already posted, so they count against the limits of later ones
+			if err := checkTransferLimits(ctx, q, legArg); err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+
+			transfer, err := postTransfer(ctx, q, legArg)
+			if err != nil {
+				return fmt.Errorf("leg %d: %w", i, err)
+			}
+			result.Transfers = append(result.Transfers, transfer)
+		}
+
+		if arg.Idempotency != nil {
+			if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, result); err != nil {
+				return err
+			}
+		}
+
+		events := make([]auditEvent, len(result.Transfers))
+		for i, transfer := range result.Transfers {
+			events[i] = transferAuditEvent("batch_transfer.leg", transfer)
+		}
+		return recordAudit(ctx, q, events...)
+	})
+
+	return result, err
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createBatchAccounts creates a funded source account and n payee accounts in the same currency
+func createBatchAccounts(t *testing.T, balance int64, n int) (Account, []Account) {
+	source := fundAccount(t, createRandomAccount(t), balance)
+
+	payees := make([]Account, n)
+	for i := range payees {
+		payee, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    createRandomUser(t).Username,
+			Currency: source.Currency,
+		})
+		require.NoError(t, err)
+		payees[i] = payee
+	}
+	return source, payees
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	source, payees := createBatchAccounts(t, 100, 3)
+
+	legs := make([]BatchTransferLeg, len(payees))
+	for i, payee := range payees {
+		legs[i] = BatchTransferLeg{
+			FromAccountID: source.ID,
+			ToAccountID:   payee.ID,
+			Amount:        int64(10 * (i + 1)),
+		}
+	}
+
+	result, err := NewStore(testDb).BatchTransferTx(context.Background(), BatchTransferTxParams{Legs: legs})
+	require.NoError(t, err)
+	require.NotZero(t, result.Batch.ID)
+	require.Len(t, result.Transfers, len(legs))
+
+	for i, transfer := range result.Transfers {
+		require.True(t, transfer.Transfer.BatchID.Valid)
+		require.Equal(t, result.Batch.ID, transfer.Transfer.BatchID.Int64)
+		require.Equal(t, legs[i].ToAccountID, transfer.Transfer.ToAccountID)
+		require.Equal(t, legs[i].Amount, transfer.Transfer.Amount)
+		require.Equal(t, -legs[i].Amount, transfer.FromEntry.Amount)
+		require.Equal(t, legs[i].Amount, transfer.ToEntry.Amount)
+		require.Equal(t, legs[i].Amount, transfer.ToAccount.Balance)
+	}
+
+	updatedSource, err := testQueries.GetAccount(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), updatedSource.Balance)
+}
+
+func TestBatchTransferTxRollback(t *testing.T) {
+	source, payees := createBatchAccounts(t, 50, 2)
+
+	_, err := NewStore(testDb).BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Legs: []BatchTransferLeg{
+			{FromAccountID: source.ID, ToAccountID: payees[0].ID, Amount: 30},
+			{FromAccountID: source.ID, ToAccountID: payees[1].ID, Amount: 30},
+		},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedSource, err := testQueries.GetAccount(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Equal(t, source.Balance, updatedSource.Balance)
+
+	updatedPayee, err := testQueries.GetAccount(context.Background(), payees[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, updatedPayee.Balance)
+}
+
+func TestBatchTransferTxCurrencyMismatch(t *testing.T) {
+	source, _ := createBatchAccounts(t, 50, 0)
+
+	currency := "USD"
+	if source.Currency == currency {
+		currency = "EUR"
+	}
+	payee, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+
+	_, err = NewStore(testDb).BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Legs: []BatchTransferLeg{{FromAccountID: source.ID, ToAccountID: payee.ID, Amount: 10}},
+	})
+	require.ErrorIs(t, err, ErrBatchCurrencyMismatch)
+}
+
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	n := 10
+	amount := int64(10)
+
+	account1, others := createBatchAccounts(t, int64(n)*amount, 2)
+	account2 := fundAccount(t, others[0], int64(n)*amount)
+	account3 := fundAccount(t, others[1], int64(n)*amount)
+
+	store := NewStore(testDb)
+	errs := make(chan error)
+
+	for x := 0; x < n; x++ {
+		accounts := []Account{account1, account2, account3}
+		if x%2 == 0 {
+			accounts = []Account{account3, account2, account1}
+		}
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+				Legs: []BatchTransferLeg{
+					{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: amount},
+					{FromAccountID: accounts[1].ID, ToAccountID: accounts[2].ID, Amount: amount},
+				},
+			})
+			errs <- err
+		}()
+	}
+
+	for x := 0; x < n; x++ {
+		require.NoError(t, <-errs)
+	}
+
+	for _, account := range []Account{account1, account2, account3} {
+		updated, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updated.Balance)
+	}
+}
@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+)
+
+// SystemUsername owns the cash account of every currency
+const SystemUsername = "simplebank_system"
+
+// CashTxParams describes money entering or leaving an account through the bank's cash account
+type CashTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Idempotency, when set, stores the result under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// CashTxResult is the outcome of a deposit or withdrawal. The cash account side is left out on purpose,
+// its balance is internal to the bank.
+type CashTxResult struct {
+	Transfer Transfer `json:"transfer"`
+	Account  Account  `json:"account"`
+	Entry    Entry    `json:"entry"`
+}
+
+// DepositTx credits the account and debits the cash account of its currency
+func (store *SQLStore) DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, arg, true)
+}
+
+// WithdrawTx debits the account and credits the cash account of its currency
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error) {
+	return store.cashTx(ctx, arg, false)
+}
+
+func (store *SQLStore) cashTx(ctx context.Context, arg CashTxParams, deposit bool) (CashTxResult, error) {
+	var result CashTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		cashAccount, err := q.UpsertCashAccount(ctx, UpsertCashAccountParams{
+			Owner:    SystemUsername,
+			Currency: account.Currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		transfer := CrossCurrencyTransferTxParams{
+			FromAccountID: cashAccount.ID,
+			ToAccountID:   account.ID,
+			Amount:        arg.Amount,
+			ToAmount:      arg.Amount,
+			ExchangeRate:  "1",
+		}
+		if !deposit {
+			// a withdrawal moves money out of the account like a transfer and counts against the same limits
+			transfer.FromAccountID, transfer.ToAccountID = account.ID, cashAccount.ID
+			if err := checkTransferLimits(ctx, q, transfer); err != nil {
+				return err
+			}
+		}
+
+		posted, err := postTransfer(ctx, q, transfer)
+		if err != nil {
+			return err
+		}
+
+		result.Transfer = posted.Transfer
+		if deposit {
+			result.Account, result.Entry = posted.ToAccount, posted.ToEntry
+		} else {
+			result.Account, result.Entry = posted.FromAccount, posted.FromEntry
+		}
+
+		if arg.Idempotency != nil {
+			if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, result); err != nil {
+				return err
+			}
+		}
+
+		action := "withdrawal.create"
+		if deposit {
+			action = "deposit.create"
+		}
+		return recordAudit(ctx, q, transferAuditEvent(action, posted))
+	})
+
+	return result, err
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertCashAccount(t *testing.T) {
+	arg := UpsertCashAccountParams{
+		Owner:    SystemUsername,
+		Currency: "USD",
+	}
+
+	account1, err := testQueries.UpsertCashAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, SystemUsername, account1.Owner)
+	require.Equal(t, AccountKindCash, account1.Kind)
+
+	account2, err := testQueries.UpsertCashAccount(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+}
+
+func TestDepositTx(t *testing.T) {
+	account := createRandomAccount(t)
+	store := NewStore(testDb)
+
+	result, err := store.DepositTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    100,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, account.Balance+100, result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(100), result.Entry.Amount)
+	require.Equal(t, account.ID, result.Transfer.ToAccountID)
+
+	cashAccount, err := testQueries.GetAccount(context.Background(), result.Transfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, AccountKindCash, cashAccount.Kind)
+	require.Equal(t, account.Currency, cashAccount.Currency)
+
+	entries, err := testQueries.ListEntriesForAccount(context.Background(), ListEntriesForAccountParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, result.Entry.ID, entries[0].ID)
+}
+
+func TestWithdrawTx(t *testing.T) {
+	account := fundAccount(t, createRandomAccount(t), 100)
+	store := NewStore(testDb)
+
+	result, err := store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    60,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(40), result.Account.Balance)
+	require.Equal(t, int64(-60), result.Entry.Amount)
+	require.Equal(t, account.ID, result.Transfer.FromAccountID)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{
+		AccountID: account.ID,
+		Amount:    60,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(40), updatedAccount.Balance)
+}
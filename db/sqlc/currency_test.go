@@ -0,0 +1,89 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCurrency(t *testing.T) Currency {
+	arg := CreateCurrencyParams{
+		Code:       strings.ToUpper(utils.RandomString(3)),
+		Name:       utils.RandomString(10),
+		MinorUnits: int32(utils.RandomInt(0, 4)),
+	}
+
+	currency, err := testQueries.CreateCurrency(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, currency)
+
+	require.Equal(t, arg.Code, currency.Code)
+	require.Equal(t, arg.Name, currency.Name)
+	require.Equal(t, arg.MinorUnits, currency.MinorUnits)
+	require.True(t, currency.Enabled)
+	require.NotZero(t, currency.CreatedAt)
+
+	return currency
+}
+
+// randomCurrency returns the code of one of the enabled currencies in the registry
+func randomCurrency(t *testing.T) string {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	var codes []string
+	for _, currency := range currencies {
+		if currency.Enabled {
+			codes = append(codes, currency.Code)
+		}
+	}
+	require.NotEmpty(t, codes)
+
+	return utils.RandomCurrency(codes)
+}
+
+func TestCreateCurrency(t *testing.T) {
+	createRandomCurrency(t)
+}
+
+func TestCreateCurrencyInvalidCode(t *testing.T) {
+	_, err := testQueries.CreateCurrency(context.Background(), CreateCurrencyParams{
+		Code:       "us1",
+		Name:       utils.RandomString(10),
+		MinorUnits: 2,
+	})
+	require.Error(t, err)
+}
+
+func TestGetCurrency(t *testing.T) {
+	currency, err := testQueries.GetCurrency(context.Background(), "USD")
+	require.NoError(t, err)
+	require.Equal(t, "USD", currency.Code)
+	require.Equal(t, int32(2), currency.MinorUnits)
+}
+
+func TestListCurrencies(t *testing.T) {
+	currencies, err := testQueries.ListCurrencies(context.Background())
+	require.NoError(t, err)
+
+	codes := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		codes = append(codes, currency.Code)
+	}
+	require.Subset(t, codes, []string{"CAD", "EUR", "USD"})
+}
+
+func TestSetCurrencyEnabled(t *testing.T) {
+	currency1 := createRandomCurrency(t)
+
+	currency2, err := testQueries.SetCurrencyEnabled(context.Background(), SetCurrencyEnabledParams{
+		Code:    currency1.Code,
+		Enabled: false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, currency1.Code, currency2.Code)
+	require.False(t, currency2.Enabled)
+}
@@ -7,36 +7,40 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +52,14 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2
 `
@@ -77,6 +83,49 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesAfter = `-- name: ListEntriesAfter :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type ListEntriesAfterParams struct {
+	Limit          int32     `json:"limit"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+func (q *Queries) ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesAfter, arg.Limit, arg.AfterCreatedAt, arg.AfterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -92,8 +141,9 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 }
 
 const listEntriesForAccount = `-- name: ListEntriesForAccount :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE account_id = $3
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2
 `
@@ -118,6 +168,747 @@ func (q *Queries) ListEntriesForAccount(ctx context.Context, arg ListEntriesForA
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesForAccountAfter = `-- name: ListEntriesForAccountAfter :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $2 AND (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type ListEntriesForAccountAfterParams struct {
+	Limit          int32     `json:"limit"`
+	AccountID      int64     `json:"account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+func (q *Queries) ListEntriesForAccountAfter(ctx context.Context, arg ListEntriesForAccountAfterParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesForAccountAfter,
+		arg.Limit,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesWithBalanceForAccount = `-- name: ListEntriesWithBalanceForAccount :many
+SELECT id, account_id, amount, created_at, transfer_id, running_balance FROM (
+  SELECT id, account_id, amount, created_at, transfer_id,
+    SUM(amount) OVER (ORDER BY created_at, id)::bigint AS running_balance
+  FROM entries
+  WHERE account_id = $1
+) AS e
+ORDER BY created_at, id
+LIMIT $2
+OFFSET $3
+`
+
+type ListEntriesWithBalanceForAccountParams struct {
+	AccountID int64 `json:"account_id"`
+	Limit     int32 `json:"limit"`
+	Offset    int32 `json:"offset"`
+}
+
+type ListEntriesWithBalanceForAccountRow struct {
+	ID             int64         `json:"id"`
+	AccountID      int64         `json:"account_id"`
+	Amount         int64         `json:"amount"`
+	CreatedAt      time.Time     `json:"created_at"`
+	TransferID     sql.NullInt64 `json:"transfer_id"`
+	RunningBalance int64         `json:"running_balance"`
+}
+
+func (q *Queries) ListEntriesWithBalanceForAccount(ctx context.Context, arg ListEntriesWithBalanceForAccountParams) ([]ListEntriesWithBalanceForAccountRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesWithBalanceForAccount, arg.AccountID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEntriesWithBalanceForAccountRow{}
+	for rows.Next() {
+		var i ListEntriesWithBalanceForAccountRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesWithBalanceForAccountAfter = `-- name: ListEntriesWithBalanceForAccountAfter :many
+SELECT id, account_id, amount, created_at, transfer_id, running_balance FROM (
+  SELECT id, account_id, amount, created_at, transfer_id,
+    SUM(amount) OVER (ORDER BY created_at, id)::bigint AS running_balance
+  FROM entries
+  WHERE account_id = $2
+) AS e
+WHERE (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type ListEntriesWithBalanceForAccountAfterRow struct {
+	ID             int64         `json:"id"`
+	AccountID      int64         `json:"account_id"`
+	Amount         int64         `json:"amount"`
+	CreatedAt      time.Time     `json:"created_at"`
+	TransferID     sql.NullInt64 `json:"transfer_id"`
+	RunningBalance int64         `json:"running_balance"`
+}
+
+type ListEntriesWithBalanceForAccountAfterParams struct {
+	Limit          int32     `json:"limit"`
+	AccountID      int64     `json:"account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+func (q *Queries) ListEntriesWithBalanceForAccountAfter(ctx context.Context, arg ListEntriesWithBalanceForAccountAfterParams) ([]ListEntriesWithBalanceForAccountAfterRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesWithBalanceForAccountAfter,
+		arg.Limit,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListEntriesWithBalanceForAccountAfterRow{}
+	for rows.Next() {
+		var i ListEntriesWithBalanceForAccountAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.RunningBalance,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesByAmount = `-- name: SearchEntriesByAmount :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($4::bigint, 0)
+  AND abs(amount) <= COALESCE($5::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($6::bigint, sign(amount))
+  AND ($7::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $7
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) > (COALESCE($8::bigint, 0), COALESCE($9::timestamptz, '-infinity'), COALESCE($10::bigint, 0))
+ORDER BY abs(amount), created_at, id
+LIMIT $1
+`
+
+type SearchEntriesByAmountParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesByAmount(ctx context.Context, arg SearchEntriesByAmountParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesByAmount,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesByAmountDesc = `-- name: SearchEntriesByAmountDesc :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($4::bigint, 0)
+  AND abs(amount) <= COALESCE($5::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($6::bigint, sign(amount))
+  AND ($7::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $7
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) < (COALESCE($8::bigint, 9223372036854775807), COALESCE($9::timestamptz, 'infinity'), COALESCE($10::bigint, 9223372036854775807))
+ORDER BY abs(amount) DESC, created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchEntriesByAmountDescParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesByAmountDesc(ctx context.Context, arg SearchEntriesByAmountDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesByAmountDesc,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesByCreatedAt = `-- name: SearchEntriesByCreatedAt :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($4::bigint, 0)
+  AND abs(amount) <= COALESCE($5::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($6::bigint, sign(amount))
+  AND ($7::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $7
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) > (COALESCE($8::timestamptz, '-infinity'), COALESCE($9::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type SearchEntriesByCreatedAtParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesByCreatedAt(ctx context.Context, arg SearchEntriesByCreatedAtParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesByCreatedAt,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesByCreatedAtDesc = `-- name: SearchEntriesByCreatedAtDesc :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($4::bigint, 0)
+  AND abs(amount) <= COALESCE($5::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($6::bigint, sign(amount))
+  AND ($7::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $7
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) < (COALESCE($8::timestamptz, 'infinity'), COALESCE($9::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchEntriesByCreatedAtDescParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesByCreatedAtDesc(ctx context.Context, arg SearchEntriesByCreatedAtDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesByCreatedAtDesc,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesForAccountByAmount = `-- name: SearchEntriesForAccountByAmount :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $2
+  AND created_at >= COALESCE($3::timestamptz, '-infinity')
+  AND created_at < COALESCE($4::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($5::bigint, 0)
+  AND abs(amount) <= COALESCE($6::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($7::bigint, sign(amount))
+  AND ($8::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $8
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) > (COALESCE($9::bigint, 0), COALESCE($10::timestamptz, '-infinity'), COALESCE($11::bigint, 0))
+ORDER BY abs(amount), created_at, id
+LIMIT $1
+`
+
+type SearchEntriesForAccountByAmountParams struct {
+	Limit          int32         `json:"limit"`
+	AccountID      int64         `json:"account_id"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesForAccountByAmount(ctx context.Context, arg SearchEntriesForAccountByAmountParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesForAccountByAmount,
+		arg.Limit,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesForAccountByAmountDesc = `-- name: SearchEntriesForAccountByAmountDesc :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $2
+  AND created_at >= COALESCE($3::timestamptz, '-infinity')
+  AND created_at < COALESCE($4::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($5::bigint, 0)
+  AND abs(amount) <= COALESCE($6::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($7::bigint, sign(amount))
+  AND ($8::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $8
+      AND counterparty.id <> entries.id
+  ))
+  AND (abs(amount), created_at, id) < (COALESCE($9::bigint, 9223372036854775807), COALESCE($10::timestamptz, 'infinity'), COALESCE($11::bigint, 9223372036854775807))
+ORDER BY abs(amount) DESC, created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchEntriesForAccountByAmountDescParams struct {
+	Limit          int32         `json:"limit"`
+	AccountID      int64         `json:"account_id"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesForAccountByAmountDesc(ctx context.Context, arg SearchEntriesForAccountByAmountDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesForAccountByAmountDesc,
+		arg.Limit,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesForAccountByCreatedAt = `-- name: SearchEntriesForAccountByCreatedAt :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $2
+  AND created_at >= COALESCE($3::timestamptz, '-infinity')
+  AND created_at < COALESCE($4::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($5::bigint, 0)
+  AND abs(amount) <= COALESCE($6::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($7::bigint, sign(amount))
+  AND ($8::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $8
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) > (COALESCE($9::timestamptz, '-infinity'), COALESCE($10::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type SearchEntriesForAccountByCreatedAtParams struct {
+	Limit          int32         `json:"limit"`
+	AccountID      int64         `json:"account_id"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesForAccountByCreatedAt(ctx context.Context, arg SearchEntriesForAccountByCreatedAtParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesForAccountByCreatedAt,
+		arg.Limit,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchEntriesForAccountByCreatedAtDesc = `-- name: SearchEntriesForAccountByCreatedAtDesc :many
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
+WHERE account_id = $2
+  AND created_at >= COALESCE($3::timestamptz, '-infinity')
+  AND created_at < COALESCE($4::timestamptz, 'infinity')
+  AND abs(amount) >= COALESCE($5::bigint, 0)
+  AND abs(amount) <= COALESCE($6::bigint, 9223372036854775807)
+  AND sign(amount) = COALESCE($7::bigint, sign(amount))
+  AND ($8::bigint IS NULL OR EXISTS (
+    SELECT 1 FROM entries AS counterparty
+    WHERE counterparty.transfer_id = entries.transfer_id
+      AND counterparty.account_id = $8
+      AND counterparty.id <> entries.id
+  ))
+  AND (created_at, id) < (COALESCE($9::timestamptz, 'infinity'), COALESCE($10::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchEntriesForAccountByCreatedAtDescParams struct {
+	Limit          int32         `json:"limit"`
+	AccountID      int64         `json:"account_id"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	Sign           sql.NullInt64 `json:"sign"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchEntriesForAccountByCreatedAtDesc(ctx context.Context, arg SearchEntriesForAccountByCreatedAtDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, searchEntriesForAccountByCreatedAtDesc,
+		arg.Limit,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.Sign,
+		arg.CounterpartyID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Entry{}
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
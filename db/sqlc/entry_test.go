@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -97,3 +98,52 @@ func TestListEntryForAccount(t *testing.T) {
 		require.NotEmpty(t, entry)
 	}
 }
+
+func TestSearchEntries(t *testing.T) {
+	store := NewStore(testDb)
+	account, payees := createBatchAccounts(t, 1000, 2)
+
+	for _, leg := range []BatchTransferLeg{
+		{ToAccountID: payees[0].ID, Amount: 100},
+		{ToAccountID: payees[1].ID, Amount: 300},
+		{ToAccountID: payees[0].ID, Amount: 200},
+	} {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        leg.Amount,
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := store.SearchEntries(context.Background(), SearchEntriesParams{
+		Limit:          10,
+		AccountID:      sql.NullInt64{Int64: account.ID, Valid: true},
+		Direction:      sql.NullString{String: "outgoing", Valid: true},
+		CounterpartyID: sql.NullInt64{Int64: payees[0].ID, Valid: true},
+		SortBy:         "amount",
+		Descending:     true,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, int64(-200), entries[0].Amount)
+	require.Equal(t, int64(-100), entries[1].Amount)
+
+	entries, err = store.SearchEntries(context.Background(), SearchEntriesParams{
+		Limit:     10,
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		Direction: sql.NullString{String: "incoming", Valid: true},
+	})
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	entries, err = store.SearchEntries(context.Background(), SearchEntriesParams{
+		Limit:     10,
+		AccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		MinAmount: sql.NullInt64{Int64: 150, Valid: true},
+		MaxAmount: sql.NullInt64{Int64: 250, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, int64(-200), entries[0].Amount)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrInsufficientFunds is returned when a transaction would take an account below its overdraft limit
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// ErrDuplicateIdempotencyKey is returned when another request has already stored a result under the same idempotency key
+var ErrDuplicateIdempotencyKey = errors.New("idempotency key has already been used")
+
+var (
+	// ErrAccountNotActive matches every AccountStatusError
+	ErrAccountNotActive = errors.New("account is not active")
+	// ErrNonZeroBalance is returned when closing an account whose balance cannot be swept
+	ErrNonZeroBalance = errors.New("account balance must be zero or swept to another account")
+	// ErrSweepCurrencyMismatch is returned when the sweep account holds a different currency
+	ErrSweepCurrencyMismatch = errors.New("sweep account must hold the same currency")
+	// ErrActiveHolds is returned when closing an account that still has funds reserved
+	ErrActiveHolds = errors.New("account has active holds")
+
+	// ErrHoldNotActive matches every HoldStatusError
+	ErrHoldNotActive = errors.New("hold is not active")
+	// ErrHoldCurrencyMismatch is returned when a hold is placed for an account in another currency
+	ErrHoldCurrencyMismatch = errors.New("hold accounts must hold the same currency")
+	// ErrCaptureExceedsHold is returned when capturing more than the hold reserved
+	ErrCaptureExceedsHold = errors.New("capture amount exceeds the hold")
+
+	// ErrTransferReversed is returned when reversing a transfer that was already reversed in full
+	ErrTransferReversed = errors.New("transfer has already been reversed")
+	// ErrReversalExceedsTransfer is returned when reversing more than is left of a transfer
+	ErrReversalExceedsTransfer = errors.New("reversal amount exceeds the unreversed amount of the transfer")
+	// ErrReverseReversal is returned when reversing a transfer that is itself a reversal
+	ErrReverseReversal = errors.New("a reversal cannot be reversed")
+	// ErrReversalTooSmall is returned when a partial reversal converts to nothing in the destination currency
+	ErrReversalTooSmall = errors.New("reversal amount is too small to convert")
+
+	// ErrBatchCurrencyMismatch is returned when a leg of a batch transfer moves money between currencies
+	ErrBatchCurrencyMismatch = errors.New("batch transfer legs must stay within one currency")
+
+	// ErrTransferLimitExceeded matches every TransferLimitError
+	ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+	// ErrPendingTransferReviewed matches every PendingTransferStatusError
+	ErrPendingTransferReviewed = errors.New("pending transfer has already been reviewed")
+)
+
+// AccountStatusError is returned when money would move in or out of an account that is frozen or closed
+type AccountStatusError struct {
+	AccountID int64
+	Status    string
+}
+
+func (err *AccountStatusError) Error() string {
+	return fmt.Sprintf("account [%d] is %s", err.AccountID, err.Status)
+}
+
+func (err *AccountStatusError) Is(target error) bool {
+	return target == ErrAccountNotActive
+}
+
+// HoldStatusError is returned when capturing or releasing a hold that was already settled or has expired
+type HoldStatusError struct {
+	HoldID int64
+	Status string
+}
+
+func (err *HoldStatusError) Error() string {
+	return fmt.Sprintf("hold [%d] is %s", err.HoldID, err.Status)
+}
+
+func (err *HoldStatusError) Is(target error) bool {
+	return target == ErrHoldNotActive
+}
+
+// TransferLimitError is returned when a transfer would take its source account over one of its velocity limits
+type TransferLimitError struct {
+	AccountID int64 `json:"account_id"`
+	// Limit is one of the TransferLimit constants
+	Limit string `json:"limit"`
+	Max   int64  `json:"max"`
+	// Used is what the account had already used up of the limit before this transfer
+	Used int64 `json:"used"`
+	// Requested is what this transfer adds, its amount or one transfer for the count
+	Requested int64 `json:"requested"`
+}
+
+func (err *TransferLimitError) Error() string {
+	if err.Limit == TransferLimitAmount {
+		return fmt.Sprintf("amount %d exceeds the limit of %d per transfer of account [%d]", err.Requested, err.Max, err.AccountID)
+	}
+	return fmt.Sprintf("transfer exceeds the %s limit of account [%d]: %d already used of %d", err.Limit, err.AccountID, err.Used, err.Max)
+}
+
+func (err *TransferLimitError) Is(target error) bool {
+	return target == ErrTransferLimitExceeded
+}
+
+// PendingTransferStatusError is returned when approving a pending transfer that was already approved or rejected
+type PendingTransferStatusError struct {
+	PendingTransferID int64
+	Status            string
+}
+
+func (err *PendingTransferStatusError) Error() string {
+	return fmt.Sprintf("pending transfer [%d] is %s", err.PendingTransferID, err.Status)
+}
+
+func (err *PendingTransferStatusError) Is(target error) bool {
+	return target == ErrPendingTransferReviewed
+}
+
+const (
+	accountsBalanceCheck     = "accounts_balance_check"
+	accountsOwnerCurrencyKey = "owner_currency_key"
+	idempotencyKeysPkey      = "idempotency_keys_pkey"
+)
+
+// isConstraintViolation reports whether err was raised by the named database constraint
+func isConstraintViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Constraint == constraint
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpsertExchangeRate(t *testing.T) {
+	rate1, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "EUR",
+		Rate:          "0.92",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "USD", rate1.BaseCurrency)
+	require.Equal(t, "EUR", rate1.QuoteCurrency)
+	require.Equal(t, "0.9200000000", rate1.Rate)
+	require.NotZero(t, rate1.UpdatedAt)
+
+	rate2, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "EUR",
+		Rate:          "0.93",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "0.9300000000", rate2.Rate)
+	require.False(t, rate2.UpdatedAt.Before(rate1.UpdatedAt))
+}
+
+func TestGetExchangeRate(t *testing.T) {
+	rate1, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		BaseCurrency:  "EUR",
+		QuoteCurrency: "CAD",
+		Rate:          "1.48",
+	})
+	require.NoError(t, err)
+
+	rate2, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		BaseCurrency:  "EUR",
+		QuoteCurrency: "CAD",
+	})
+	require.NoError(t, err)
+	require.Equal(t, rate1.Rate, rate2.Rate)
+
+	rates, err := testQueries.ListExchangeRates(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, rates)
+}
+
+func TestUpsertExchangeRateInvalid(t *testing.T) {
+	_, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "CAD",
+		Rate:          "0",
+	})
+	require.Error(t, err)
+}
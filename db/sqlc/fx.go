@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// FXUsername owns the fx account of every currency
+const FXUsername = "simplebank_fx"
+
+// postExchange books the conversion of a cross-currency transfer through the fx accounts of both currencies.
+// The fx account of the source currency takes in Amount and the fx account of the destination currency pays
+// out ToAmount, so the entries of the transfer still add up to zero in each currency.
+// The fx accounts are locked in currency order after the customer accounts of the transfer, which is the
+// only order any transaction locks them in.
+func postExchange(ctx context.Context, q *Queries, transfer TransferTxResult, arg CrossCurrencyTransferTxParams) error {
+	legs := []struct {
+		currency string
+		amount   int64
+	}{
+		{transfer.FromAccount.Currency, arg.Amount},
+		{transfer.ToAccount.Currency, -arg.ToAmount},
+	}
+	if legs[0].currency > legs[1].currency {
+		legs[0], legs[1] = legs[1], legs[0]
+	}
+
+	for _, leg := range legs {
+		fxAccount, err := q.UpsertFXAccount(ctx, UpsertFXAccountParams{
+			Owner:    FXUsername,
+			Currency: leg.currency,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID:  fxAccount.ID,
+			Amount:     leg.amount,
+			TransferID: sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     fxAccount.ID,
+			Amount: leg.amount,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	HoldStatusActive   = "active"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusExpired  = "expired"
+)
+
+type PlaceHoldParams struct {
+	AccountID   int64     `json:"account_id"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type HoldResult struct {
+	Hold    Hold    `json:"hold"`
+	Account Account `json:"account"`
+}
+
+// PlaceHold reserves funds on an account until the hold is captured, released or expires.
+// Reserved funds no longer count towards the available balance.
+func (store *SQLStore) PlaceHold(ctx context.Context, arg PlaceHoldParams) (HoldResult, error) {
+	var result HoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Status != AccountStatusActive {
+			return &AccountStatusError{AccountID: account.ID, Status: account.Status}
+		}
+
+		toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if toAccount.Currency != account.Currency {
+			return ErrHoldCurrencyMismatch
+		}
+
+		result.Account, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     account.ID,
+			Amount: -arg.Amount,
+		})
+		if err != nil {
+			if isConstraintViolation(err, accountsBalanceCheck) {
+				return ErrInsufficientFunds
+			}
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID:   account.ID,
+			ToAccountID: toAccount.ID,
+			Amount:      arg.Amount,
+			ExpiresAt:   arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "hold.place",
+			ResourceType: "hold",
+			ResourceID:   auditID(result.Hold.ID),
+			After:        result,
+		})
+	})
+
+	return result, err
+}
+
+type CaptureHoldParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Amount may be less than the hold, the remainder is released. Zero captures the whole hold.
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHold settles an active hold with a transfer to the account the hold was placed for
+func (store *SQLStore) CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error) {
+	var result CaptureHoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+		if err := checkHoldCapturable(hold, time.Now()); err != nil {
+			return err
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount < 0 || amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		// lock both accounts before the release below, so it cannot deadlock with a transfer
+		if _, err = lockAccounts(ctx, q, hold.AccountID, hold.ToAccountID); err != nil {
+			return err
+		}
+
+		capture := CrossCurrencyTransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        amount,
+			ToAmount:      amount,
+			ExchangeRate:  "1",
+		}
+		// the capture is the transfer, placing the hold did not count against the limits
+		if err := checkTransferLimits(ctx, q, capture); err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+			ID:     hold.AccountID,
+			Amount: hold.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = postTransfer(ctx, q, capture)
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+			ID:             hold.ID,
+			Status:         HoldStatusCaptured,
+			CapturedAmount: amount,
+			TransferID:     sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, transferAuditEvent("transfer.create", result.Transfer), auditEvent{
+			Action:       "hold.capture",
+			ResourceType: "hold",
+			ResourceID:   auditID(hold.ID),
+			Before:       hold,
+			After:        result.Hold,
+		})
+	})
+
+	return result, err
+}
+
+// ReleaseHold gives the funds reserved by an active hold back to the account
+func (store *SQLStore) ReleaseHold(ctx context.Context, holdID int64) (HoldResult, error) {
+	var result HoldResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, holdID)
+		if err != nil {
+			return err
+		}
+		if hold.Status != HoldStatusActive {
+			return &HoldStatusError{HoldID: hold.ID, Status: hold.Status}
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusReleased)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, holdAuditEvent("hold.release", hold, result))
+	})
+
+	return result, err
+}
+
+type ExpireHoldsParams struct {
+	Now   time.Time `json:"now"`
+	Limit int32     `json:"limit"`
+}
+
+// ExpireHolds releases up to Limit active holds that expired by Now, skipping the ones another
+// transaction is working on
+func (store *SQLStore) ExpireHolds(ctx context.Context, arg ExpireHoldsParams) ([]Hold, error) {
+	var expired []Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		holds, err := q.ListExpiredHolds(ctx, ListExpiredHoldsParams{
+			ExpiresAt: arg.Now,
+			Limit:     arg.Limit,
+		})
+		if err != nil {
+			return err
+		}
+
+		// lock every affected account up front in lockAccounts order, since releasing the holds in
+		// expiry order would otherwise lock accounts in an order that can deadlock with transfers
+		accountIDs := make([]int64, 0, len(holds))
+		for _, hold := range holds {
+			accountIDs = append(accountIDs, hold.AccountID)
+		}
+		if _, err = lockAccounts(ctx, q, accountIDs...); err != nil {
+			return err
+		}
+
+		events := make([]auditEvent, 0, len(holds))
+		for _, hold := range holds {
+			result, err := releaseHold(ctx, q, hold, HoldStatusExpired)
+			if err != nil {
+				return err
+			}
+			expired = append(expired, result.Hold)
+			events = append(events, holdAuditEvent("hold.expire", hold, result))
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return recordAudit(ctx, q, events...)
+	})
+
+	return expired, err
+}
+
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (HoldResult, error) {
+	var result HoldResult
+	var err error
+
+	result.Account, err = q.AddAccountAvailableBalance(ctx, AddAccountAvailableBalanceParams{
+		ID:     hold.AccountID,
+		Amount: hold.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHold(ctx, UpdateHoldParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	return result, err
+}
+
+// holdAuditEvent records a hold that was released, by the account holder or because it expired
+func holdAuditEvent(action string, before Hold, result HoldResult) auditEvent {
+	return auditEvent{
+		Action:       action,
+		ResourceType: "hold",
+		ResourceID:   auditID(before.ID),
+		Before:       before,
+		After:        result,
+	}
+}
+
+// checkHoldCapturable rejects holds that were already settled or whose expiry has passed
+// but were not yet picked up by ExpireHolds
+func checkHoldCapturable(hold Hold, now time.Time) error {
+	if hold.Status != HoldStatusActive {
+		return &HoldStatusError{HoldID: hold.ID, Status: hold.Status}
+	}
+	if !hold.ExpiresAt.After(now) {
+		return &HoldStatusError{HoldID: hold.ID, Status: HoldStatusExpired}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createHoldAccounts returns a funded account and a merchant account in the same currency
+func createHoldAccounts(t *testing.T, balance int64) (Account, Account) {
+	account := fundAccount(t, createRandomAccount(t), balance)
+
+	merchant, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: account.Currency,
+	})
+	require.NoError(t, err)
+
+	return account, merchant
+}
+
+func placeRandomHold(t *testing.T, store Store, account, merchant Account, amount int64, expiresAt time.Time) HoldResult {
+	result, err := store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: merchant.ID,
+		Amount:      amount,
+		ExpiresAt:   expiresAt,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, result.Hold.ID)
+	require.Equal(t, amount, result.Hold.Amount)
+	require.Equal(t, HoldStatusActive, result.Hold.Status)
+	require.Equal(t, account.Balance, result.Account.Balance)
+	require.Equal(t, account.AvailableBalance-amount, result.Account.AvailableBalance)
+
+	return result
+}
+
+func TestPlaceHold(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+
+	placeRandomHold(t, store, account, merchant, 80, time.Now().Add(time.Hour))
+
+	_, err := store.PlaceHold(context.Background(), PlaceHoldParams{
+		AccountID:   account.ID,
+		ToAccountID: merchant.ID,
+		Amount:      30,
+		ExpiresAt:   time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestTransferTxRespectsHolds(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+	placeRandomHold(t, store, account, merchant, 80, time.Now().Add(time.Hour))
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        30,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   merchant.ID,
+		Amount:        20,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(80), result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.AvailableBalance)
+}
+
+func TestCaptureHold(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+	hold := placeRandomHold(t, store, account, merchant, 80, time.Now().Add(time.Hour)).Hold
+
+	_, err := store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID, Amount: 81})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	result, err := store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID, Amount: 50})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.Equal(t, int64(50), result.Hold.CapturedAmount)
+	require.True(t, result.Hold.TransferID.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+	require.Equal(t, int64(50), result.Transfer.Transfer.Amount)
+	require.Equal(t, int64(-50), result.Transfer.FromEntry.Amount)
+
+	// the part of the hold that was not captured is available again
+	require.Equal(t, int64(50), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(50), result.Transfer.FromAccount.AvailableBalance)
+	require.Equal(t, merchant.Balance+50, result.Transfer.ToAccount.Balance)
+
+	_, err = store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureHoldFull(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+	hold := placeRandomHold(t, store, account, merchant, 80, time.Now().Add(time.Hour)).Hold
+
+	result, err := store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(80), result.Hold.CapturedAmount)
+	require.Equal(t, int64(20), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(20), result.Transfer.FromAccount.AvailableBalance)
+}
+
+func TestReleaseHold(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+	hold := placeRandomHold(t, store, account, merchant, 80, time.Now().Add(time.Hour)).Hold
+
+	result, err := store.ReleaseHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusReleased, result.Hold.Status)
+	require.Equal(t, int64(100), result.Account.Balance)
+	require.Equal(t, int64(100), result.Account.AvailableBalance)
+
+	_, err = store.ReleaseHold(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestExpireHolds(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+	hold := placeRandomHold(t, store, account, merchant, 80, time.Now().Add(-time.Second)).Hold
+
+	_, err := store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	expired, err := store.ExpireHolds(context.Background(), ExpireHoldsParams{
+		Now:   time.Now(),
+		Limit: 100,
+	})
+	require.NoError(t, err)
+
+	var found bool
+	for _, h := range expired {
+		if h.ID == hold.ID {
+			found = true
+			require.Equal(t, HoldStatusExpired, h.Status)
+		}
+	}
+	require.True(t, found)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), updated.AvailableBalance)
+}
+
+func TestExpireHoldsDeadlock(t *testing.T) {
+	n := 10
+	store := NewStore(testDb)
+	account1, merchant := createHoldAccounts(t, 100)
+	account2, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: account1.Currency,
+	})
+	require.NoError(t, err)
+	account2 = fundAccount(t, account2, 100)
+
+	// the hold on the higher account expires first, the opposite of the order transfers lock in
+	placeRandomHold(t, store, account2, merchant, 10, time.Now().Add(-2*time.Second))
+	placeRandomHold(t, store, account1, merchant, 10, time.Now().Add(-time.Second))
+
+	errs := make(chan error)
+	go func() {
+		_, err := store.ExpireHolds(context.Background(), ExpireHoldsParams{
+			Now:   time.Now(),
+			Limit: 100,
+		})
+		errs <- err
+	}()
+	for x := 0; x < n; x++ {
+		fromAccountID, toAccountID := account1.ID, account2.ID
+		if x%2 == 0 {
+			fromAccountID, toAccountID = account2.ID, account1.ID
+		}
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: fromAccountID,
+				ToAccountID:   toAccountID,
+				Amount:        1,
+			})
+			errs <- err
+		}()
+	}
+
+	for x := 0; x <= n; x++ {
+		require.NoError(t, <-errs)
+	}
+}
+
+func TestCloseAccountTxWithHolds(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 100)
+	placeRandomHold(t, store, account, merchant, 100, time.Now().Add(time.Hour))
+
+	_, err := store.CloseAccountTx(context.Background(), CloseAccountTxParams{
+		AccountID:        account.ID,
+		SweepToAccountID: merchant.ID,
+	})
+	require.ErrorIs(t, err, ErrActiveHolds)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomIdempotencyKey(t *testing.T) IdempotencyKey {
+	user := createRandomUser(t)
+
+	arg := CreateIdempotencyKeyParams{
+		Username:     user.Username,
+		Key:          utils.RandomString(16),
+		RequestHash:  utils.RandomString(64),
+		ResponseCode: http.StatusCreated,
+		ResponseBody: json.RawMessage(`{"id":1}`),
+	}
+
+	key, err := testQueries.CreateIdempotencyKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+
+	require.Equal(t, arg.Username, key.Username)
+	require.Equal(t, arg.Key, key.Key)
+	require.Equal(t, arg.RequestHash, key.RequestHash)
+	require.Equal(t, arg.ResponseCode, key.ResponseCode)
+	require.JSONEq(t, string(arg.ResponseBody), string(key.ResponseBody))
+	require.NotZero(t, key.CreatedAt)
+
+	return key
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	createRandomIdempotencyKey(t)
+}
+
+func TestGetIdempotencyKey(t *testing.T) {
+	key1 := createRandomIdempotencyKey(t)
+
+	key2, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: key1.Username,
+		Key:      key1.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, key1.Username, key2.Username)
+	require.Equal(t, key1.Key, key2.Key)
+	require.Equal(t, key1.RequestHash, key2.RequestHash)
+	require.JSONEq(t, string(key1.ResponseBody), string(key2.ResponseBody))
+}
@@ -5,7 +5,11 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type Account struct {
@@ -14,6 +18,58 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// how far below zero the balance may go
+	OverdraftLimit int64 `json:"overdraft_limit"`
+	// customer accounts belong to users, cash accounts balance deposits and withdrawals
+	Kind string `json:"kind"`
+	// active accounts can move money, frozen ones can be reactivated, closed ones are final
+	Status string `json:"status"`
+	// balance minus the amount reserved by active holds
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+type AccountTransferLimit struct {
+	AccountID        int64         `json:"account_id"`
+	MaxAmount        sql.NullInt64 `json:"max_amount"`
+	MaxDailyAmount   sql.NullInt64 `json:"max_daily_amount"`
+	MaxMonthlyAmount sql.NullInt64 `json:"max_monthly_amount"`
+	MaxDailyCount    sql.NullInt32 `json:"max_daily_count"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username, anonymous for requests without a login or system for background jobs
+	Actor        string `json:"actor"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   string `json:"resource_id"`
+	// json rather than jsonb keeps the text the hash was computed over
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestID string          `json:"request_id"`
+	ClientIp  string          `json:"client_ip"`
+	// hash of the previous event, empty for the first one
+	PrevHash string `json:"prev_hash"`
+	// sha256 over prev_hash and the other columns except id
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BalanceSnapshot struct {
+	AccountID int64 `json:"account_id"`
+	// sum of the entries of the account created up to taken_at
+	Balance int64     `json:"balance"`
+	TakenAt time.Time `json:"taken_at"`
+}
+
+type Currency struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+	// ISO 4217 exponent, the number of decimal places of the currency
+	MinorUnits int32     `json:"minor_units"`
+	Enabled    bool      `json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -22,6 +78,131 @@ type Entry struct {
 	// can be positive or negative
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// the transfer that posted this entry
+	TransferID sql.NullInt64 `json:"transfer_id"`
+}
+
+type ExchangeRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// units of quote_currency bought by one unit of base_currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// receives the captured amount
+	ToAccountID    int64 `json:"to_account_id"`
+	Amount         int64 `json:"amount"`
+	CapturedAmount int64 `json:"captured_amount"`
+	// set once the hold is captured
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Status     string        `json:"status"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type IdempotencyKey struct {
+	Username string `json:"username"`
+	Key      string `json:"key"`
+	// sha256 of the method, path and body of the original request
+	RequestHash  string          `json:"request_hash"`
+	ResponseCode int32           `json:"response_code"`
+	ResponseBody json.RawMessage `json:"response_body"`
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+type Outbox struct {
+	ID int64 `json:"id"`
+	// the events of an account are published in id order
+	AccountID int64           `json:"account_id"`
+	EventType string          `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+	// null until the relay published the event
+	PublishedAt sql.NullTime `json:"published_at"`
+	// publish attempts that failed
+	Attempts  int32  `json:"attempts"`
+	LastError string `json:"last_error"`
+}
+
+type PendingTransfer struct {
+	ID            int64  `json:"id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	RequestedBy   string `json:"requested_by"`
+	Status        string `json:"status"`
+	// the fraud rules that sent the transfer to review
+	Reasons json.RawMessage `json:"reasons"`
+	// the transfer made once an admin approved it
+	TransferID sql.NullInt64  `json:"transfer_id"`
+	ReviewedBy sql.NullString `json:"reviewed_by"`
+	ReviewedAt sql.NullTime   `json:"reviewed_at"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+type ReconciliationRun struct {
+	ID       int64 `json:"id"`
+	FullScan bool  `json:"full_scan"`
+	// checkpoint the next incremental run continues from
+	LastTransferID int64 `json:"last_transfer_id"`
+	// checkpoint the next incremental run continues from
+	LastEntryID   int64           `json:"last_entry_id"`
+	Discrepancies int64           `json:"discrepancies"`
+	Report        json.RawMessage `json:"report"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// cron expression or interval such as @every 24h
+	Schedule  string       `json:"schedule"`
+	NextRunAt time.Time    `json:"next_run_at"`
+	EndAt     sql.NullTime `json:"end_at"`
+	Status    string       `json:"status"`
+	// runs that failed since the last successful one
+	ConsecutiveFailures int32     `json:"consecutive_failures"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type ScheduledTransferRun struct {
+	ID                  int64 `json:"id"`
+	ScheduledTransferID int64 `json:"scheduled_transfer_id"`
+	// set when the run moved money
+	TransferID   sql.NullInt64 `json:"transfer_id"`
+	ScheduledFor time.Time     `json:"scheduled_for"`
+	Status       string        `json:"status"`
+	Error        string        `json:"error"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
+
+type Session struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	RefreshToken string    `json:"refresh_token"`
+	UserAgent    string    `json:"user_agent"`
+	ClientIp     string    `json:"client_ip"`
+	IsBlocked    bool      `json:"is_blocked"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type TierTransferLimit struct {
+	Tier             string        `json:"tier"`
+	Currency         string        `json:"currency"`
+	MaxAmount        sql.NullInt64 `json:"max_amount"`
+	MaxDailyAmount   sql.NullInt64 `json:"max_daily_amount"`
+	MaxMonthlyAmount sql.NullInt64 `json:"max_monthly_amount"`
+	MaxDailyCount    sql.NullInt32 `json:"max_daily_count"`
+	UpdatedAt        time.Time     `json:"updated_at"`
 }
 
 type Transfer struct {
@@ -31,4 +212,60 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// credited to the destination account, in its currency
+	ToAmount int64 `json:"to_amount"`
+	// rate applied to convert amount into to_amount
+	ExchangeRate string `json:"exchange_rate"`
+	// the transfer this one reverses
+	ReversalOf sql.NullInt64 `json:"reversal_of"`
+	// part of amount already sent back by reversals
+	ReversedAmount int64 `json:"reversed_amount"`
+	// set on every leg of a batch transfer
+	BatchID sql.NullInt64 `json:"batch_id"`
+}
+
+type TransferBatch struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type User struct {
+	Username          string    `json:"username"`
+	FullName          string    `json:"full_name"`
+	CreatedAt         time.Time `json:"created_at"`
+	HashedPassword    string    `json:"hashed_password"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+	Email             string    `json:"email"`
+	// selects the tier_transfer_limits that apply to the accounts of the user
+	Tier string `json:"tier"`
+	Role string `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID             int64  `json:"id"`
+	SubscriptionID int64  `json:"subscription_id"`
+	EventID        int64  `json:"event_id"`
+	EventType      string `json:"event_type"`
+	// dead once every attempt failed, until it is retried by hand
+	Status        string    `json:"status"`
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// null when the last attempt got no response
+	LastStatusCode sql.NullInt32 `json:"last_status_code"`
+	LastError      string        `json:"last_error"`
+	DeliveredAt    sql.NullTime  `json:"delivered_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// outbox event types sent to the url, for the accounts of the owner
+	EventTypes []string `json:"event_types"`
+	// key of the HMAC-SHA256 signature of every delivery
+	Secret    string    `json:"secret"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
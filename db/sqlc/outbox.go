@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Domain events written to the outbox for downstream systems
+const (
+	EventAccountCreated    = "AccountCreated"
+	EventTransferCompleted = "TransferCompleted"
+	EventEntryPosted       = "EntryPosted"
+)
+
+// addOutboxEvent writes an event in the transaction of the change it describes, so the event exists
+// exactly when the change was committed. The webhooks of the account owner that take the event get a
+// delivery in the same transaction.
+func addOutboxEvent(ctx context.Context, q *Queries, eventType string, accountID int64, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event, err := q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		AccountID: accountID,
+		EventType: eventType,
+		Payload:   data,
+	})
+	if err != nil {
+		return err
+	}
+	return q.CreateWebhookDeliveries(ctx, event.ID)
+}
+
+// addTransferEvents announces a posted transfer to its source account and each entry to its own account.
+// It runs after the accounts were locked, so the events of an account get ids in commit order.
+func addTransferEvents(ctx context.Context, q *Queries, result TransferTxResult) error {
+	err := addOutboxEvent(ctx, q, EventTransferCompleted, result.Transfer.FromAccountID, result.Transfer)
+	if err != nil {
+		return err
+	}
+	for _, entry := range []Entry{result.FromEntry, result.ToEntry} {
+		if err := addOutboxEvent(ctx, q, EventEntryPosted, entry.AccountID, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type RelayOutboxTxParams struct {
+	Limit int32
+	// Publish delivers a single event. An event that could not be delivered stays in the outbox to be
+	// retried and holds back the later events of its account, so they are not delivered out of order.
+	Publish func(ctx context.Context, event Outbox) error
+}
+
+type RelayOutboxTxResult struct {
+	Published int `json:"published"`
+	Failed    int `json:"failed"`
+	// HeldBack counts the events left for later behind a failed event of the same account
+	HeldBack int `json:"held_back"`
+}
+
+// RelayOutboxTx publishes the oldest unpublished events and marks the ones that went out. Only one relay
+// works at a time, which keeps the events of each account in order; a relay that finds another one at
+// work returns without publishing anything. An event is marked after it was published, so it goes out
+// again when the relay dies in between: delivery is at least once and consumers have to expect duplicates.
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error) {
+	var result RelayOutboxTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		locked, err := q.TryLockOutbox(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		events, err := q.ListUnpublishedOutboxEvents(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		held := make(map[int64]bool)
+		for _, event := range events {
+			if held[event.AccountID] {
+				result.HeldBack++
+				continue
+			}
+
+			if publishErr := arg.Publish(ctx, event); publishErr != nil {
+				held[event.AccountID] = true
+				result.Failed++
+				err = q.MarkOutboxEventFailed(ctx, MarkOutboxEventFailedParams{
+					ID:        event.ID,
+					LastError: publishErr.Error(),
+				})
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := q.MarkOutboxEventPublished(ctx, event.ID); err != nil {
+				return err
+			}
+			result.Published++
+		}
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// relayAll runs RelayOutboxTx until the outbox is drained and returns the events of the given accounts in
+// the order they were handed to publish
+func relayAll(t *testing.T, store Store, accountIDs []int64, publish func(event Outbox) error) []Outbox {
+	watched := make(map[int64]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		watched[id] = true
+	}
+
+	var seen []Outbox
+	for {
+		result, err := store.RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+			Limit: 100,
+			Publish: func(ctx context.Context, event Outbox) error {
+				if !watched[event.AccountID] {
+					return nil
+				}
+				seen = append(seen, event)
+				return publish(event)
+			},
+		})
+		require.NoError(t, err)
+		if result.Published < 100 {
+			return seen
+		}
+	}
+}
+
+func TestTransferTxOutboxEvents(t *testing.T) {
+	store := NewStore(testDb)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	relayAll(t, store, nil, nil)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	events := relayAll(t, store, []int64{account1.ID, account2.ID}, func(Outbox) error { return nil })
+	require.Len(t, events, 3)
+
+	require.Equal(t, EventTransferCompleted, events[0].EventType)
+	require.Equal(t, account1.ID, events[0].AccountID)
+	require.Equal(t, EventEntryPosted, events[1].EventType)
+	require.Equal(t, account1.ID, events[1].AccountID)
+	require.Equal(t, EventEntryPosted, events[2].EventType)
+	require.Equal(t, account2.ID, events[2].AccountID)
+	require.Less(t, events[0].ID, events[1].ID)
+
+	var transfer Transfer
+	require.NoError(t, json.Unmarshal(events[0].Payload, &transfer))
+	require.Equal(t, result.Transfer.ID, transfer.ID)
+	var fromEntry, toEntry Entry
+	require.NoError(t, json.Unmarshal(events[1].Payload, &fromEntry))
+	require.NoError(t, json.Unmarshal(events[2].Payload, &toEntry))
+	require.Equal(t, int64(-10), fromEntry.Amount)
+	require.Equal(t, int64(10), toEntry.Amount)
+
+	// published events are not relayed again
+	require.Empty(t, relayAll(t, store, []int64{account1.ID, account2.ID}, func(Outbox) error { return nil }))
+}
+
+func TestCreateAccountOutboxEvent(t *testing.T) {
+	store := NewStore(testDb)
+	user := createRandomUser(t)
+
+	account, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	events := relayAll(t, store, []int64{account.ID}, func(Outbox) error { return nil })
+	require.Len(t, events, 1)
+	require.Equal(t, EventAccountCreated, events[0].EventType)
+}
+
+func TestRelayOutboxTxHoldsBackFailedAccount(t *testing.T) {
+	store := NewStore(testDb)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	relayAll(t, store, nil, nil)
+
+	for i := 0; i < 2; i++ {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        10,
+		})
+		require.NoError(t, err)
+	}
+
+	// account2 cannot be published to, so only the first of its events is tried
+	failed := relayAll(t, store, []int64{account1.ID, account2.ID}, func(event Outbox) error {
+		if event.AccountID == account2.ID {
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+	require.Len(t, failed, 5)
+
+	retried := relayAll(t, store, []int64{account2.ID}, func(Outbox) error { return nil })
+	require.Len(t, retried, 2)
+	require.Less(t, retried[0].ID, retried[1].ID)
+	require.Equal(t, int32(1), retried[0].Attempts)
+	require.Equal(t, "downstream unavailable", retried[0].LastError)
+	require.Zero(t, retried[1].Attempts)
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	PendingTransferStatusPending  = "pending"
+	PendingTransferStatusApproved = "approved"
+	PendingTransferStatusRejected = "rejected"
+)
+
+type CreatePendingTransferTxParams struct {
+	CreatePendingTransferParams
+	// Idempotency, when set, stores the pending transfer under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// CreatePendingTransferTx parks a transfer for review together with its audit record
+func (store *SQLStore) CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParams) (PendingTransfer, error) {
+	var pending PendingTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		pending, err = q.CreatePendingTransfer(ctx, arg.CreatePendingTransferParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.Idempotency != nil {
+			if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, pending); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "pending_transfer.create",
+			ResourceType: "pending_transfer",
+			ResourceID:   auditID(pending.ID),
+			After:        pending,
+		})
+	})
+
+	return pending, err
+}
+
+type ApprovePendingTransferTxParams struct {
+	ID         int64  `json:"id"`
+	ReviewedBy string `json:"reviewed_by"`
+	// ToAmount and ExchangeRate convert the amount when the destination account holds another currency
+	ToAmount     int64  `json:"to_amount"`
+	ExchangeRate string `json:"exchange_rate"`
+}
+
+type ApprovePendingTransferTxResult struct {
+	PendingTransfer PendingTransfer  `json:"pending_transfer"`
+	Transfer        TransferTxResult `json:"transfer"`
+}
+
+// ApprovePendingTransferTx makes a transfer that was held for review and marks it approved. The transfer is
+// subject to the same balance, status and limit checks as it would have been when it was requested.
+func (store *SQLStore) ApprovePendingTransferTx(ctx context.Context, arg ApprovePendingTransferTxParams) (ApprovePendingTransferTxResult, error) {
+	var result ApprovePendingTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		pending, err := q.GetPendingTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if pending.Status != PendingTransferStatusPending {
+			return &PendingTransferStatusError{PendingTransferID: pending.ID, Status: pending.Status}
+		}
+
+		transfer := CrossCurrencyTransferTxParams{
+			FromAccountID: pending.FromAccountID,
+			ToAccountID:   pending.ToAccountID,
+			Amount:        pending.Amount,
+			ToAmount:      arg.ToAmount,
+			ExchangeRate:  arg.ExchangeRate,
+		}
+		if err := checkTransferLimits(ctx, q, transfer); err != nil {
+			return err
+		}
+
+		result.Transfer, err = postTransfer(ctx, q, transfer)
+		if err != nil {
+			return err
+		}
+
+		result.PendingTransfer, err = q.ReviewPendingTransfer(ctx, ReviewPendingTransferParams{
+			ID:         pending.ID,
+			Status:     PendingTransferStatusApproved,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+			ReviewedBy: sql.NullString{String: arg.ReviewedBy, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, transferAuditEvent("transfer.create", result.Transfer), auditEvent{
+			Action:       "pending_transfer.review",
+			ResourceType: "pending_transfer",
+			ResourceID:   auditID(pending.ID),
+			Before:       pending,
+			After:        result.PendingTransfer,
+		})
+	})
+
+	return result, err
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPendingTransfer(t *testing.T, from, to Account, amount int64) PendingTransfer {
+	arg := CreatePendingTransferParams{
+		FromAccountID: from.ID,
+		ToAccountID:   to.ID,
+		Amount:        amount,
+		Currency:      from.Currency,
+		RequestedBy:   from.Owner,
+		Reasons:       json.RawMessage(`[{"rule":"fan-out","action":"review","message":"matched"}]`),
+	}
+
+	pending, err := testQueries.CreatePendingTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, pending.ID)
+	require.Equal(t, arg.FromAccountID, pending.FromAccountID)
+	require.Equal(t, arg.ToAccountID, pending.ToAccountID)
+	require.Equal(t, arg.Amount, pending.Amount)
+	require.Equal(t, arg.RequestedBy, pending.RequestedBy)
+	require.Equal(t, PendingTransferStatusPending, pending.Status)
+	require.JSONEq(t, string(arg.Reasons), string(pending.Reasons))
+	require.False(t, pending.TransferID.Valid)
+	require.False(t, pending.ReviewedAt.Valid)
+
+	return pending
+}
+
+func TestCreatePendingTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDb)
+	source, payees := createBatchAccounts(t, 100, 1)
+
+	arg := CreatePendingTransferTxParams{
+		CreatePendingTransferParams: CreatePendingTransferParams{
+			FromAccountID: source.ID,
+			ToAccountID:   payees[0].ID,
+			Amount:        60,
+			Currency:      source.Currency,
+			RequestedBy:   source.Owner,
+			Reasons:       json.RawMessage(`[]`),
+		},
+		Idempotency: &IdempotencyParams{
+			Username:     source.Owner,
+			Key:          utils.RandomString(16),
+			RequestHash:  utils.RandomString(64),
+			ResponseCode: 202,
+		},
+	}
+
+	pending, err := store.CreatePendingTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: arg.Idempotency.Username,
+		Key:      arg.Idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(202), stored.ResponseCode)
+
+	var storedPending PendingTransfer
+	require.NoError(t, json.Unmarshal(stored.ResponseBody, &storedPending))
+	require.Equal(t, pending.ID, storedPending.ID)
+
+	// a retry that got past the middleware is rolled back instead of parking a second transfer
+	_, err = store.CreatePendingTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+}
+
+func TestApprovePendingTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+	source, payees := createBatchAccounts(t, 100, 1)
+	admin := createRandomUser(t)
+	pending := createRandomPendingTransfer(t, source, payees[0], 60)
+
+	result, err := store.ApprovePendingTransferTx(context.Background(), ApprovePendingTransferTxParams{
+		ID:           pending.ID,
+		ReviewedBy:   admin.Username,
+		ToAmount:     60,
+		ExchangeRate: "1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferStatusApproved, result.PendingTransfer.Status)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}, result.PendingTransfer.TransferID)
+	require.Equal(t, sql.NullString{String: admin.Username, Valid: true}, result.PendingTransfer.ReviewedBy)
+	require.True(t, result.PendingTransfer.ReviewedAt.Valid)
+	require.Equal(t, int64(40), result.Transfer.FromAccount.Balance)
+	require.Equal(t, int64(60), result.Transfer.ToAccount.Balance)
+
+	_, err = store.ApprovePendingTransferTx(context.Background(), ApprovePendingTransferTxParams{
+		ID:           pending.ID,
+		ReviewedBy:   admin.Username,
+		ToAmount:     60,
+		ExchangeRate: "1",
+	})
+	require.ErrorIs(t, err, ErrPendingTransferReviewed)
+}
+
+func TestApprovePendingTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDb)
+	source, payees := createBatchAccounts(t, 50, 1)
+	pending := createRandomPendingTransfer(t, source, payees[0], 60)
+
+	_, err := store.ApprovePendingTransferTx(context.Background(), ApprovePendingTransferTxParams{
+		ID:           pending.ID,
+		ReviewedBy:   createRandomUser(t).Username,
+		ToAmount:     60,
+		ExchangeRate: "1",
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the transfer stays in review
+	got, err := testQueries.GetPendingTransfer(context.Background(), pending.ID)
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferStatusPending, got.Status)
+}
+
+func TestRejectPendingTransfer(t *testing.T) {
+	source, payees := createBatchAccounts(t, 100, 1)
+	admin := createRandomUser(t)
+	pending := createRandomPendingTransfer(t, source, payees[0], 60)
+
+	arg := ReviewPendingTransferParams{
+		ID:         pending.ID,
+		Status:     PendingTransferStatusRejected,
+		ReviewedBy: sql.NullString{String: admin.Username, Valid: true},
+	}
+	rejected, err := testQueries.ReviewPendingTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, PendingTransferStatusRejected, rejected.Status)
+	require.False(t, rejected.TransferID.Valid)
+	require.True(t, rejected.ReviewedAt.Valid)
+
+	// a reviewed transfer cannot be reviewed again
+	_, err = testQueries.ReviewPendingTransfer(context.Background(), arg)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	list, err := testQueries.ListPendingTransfers(context.Background(), ListPendingTransfersParams{
+		Status: PendingTransferStatusPending,
+		Limit:  100,
+	})
+	require.NoError(t, err)
+	for _, item := range list {
+		require.NotEqual(t, pending.ID, item.ID)
+	}
+}
@@ -6,23 +6,144 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
 )
 
 type Querier interface {
+	AddAccountAvailableBalance(ctx context.Context, arg AddAccountAvailableBalanceParams) (Account, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error)
+	BlockSession(ctx context.Context, id uuid.UUID) (Session, error)
+	CountNewPayeesSince(ctx context.Context, arg CountNewPayeesSinceParams) (int64, error)
+	CountTransfersToPayee(ctx context.Context, arg CountTransfersToPayeeParams) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) (AuditEvent, error)
+	CreateBalanceSnapshots(ctx context.Context, takenAt time.Time) (int64, error)
+	CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	CreateIdempotencyKey(ctx context.Context, arg CreateIdempotencyKeyParams) (IdempotencyKey, error)
+	CreateOutboxEvent(ctx context.Context, arg CreateOutboxEventParams) (Outbox, error)
+	CreatePendingTransfer(ctx context.Context, arg CreatePendingTransferParams) (PendingTransfer, error)
+	CreateReconciliationRun(ctx context.Context, arg CreateReconciliationRunParams) (ReconciliationRun, error)
+	CreateScheduledTransfer(ctx context.Context, arg CreateScheduledTransferParams) (ScheduledTransfer, error)
+	CreateScheduledTransferRun(ctx context.Context, arg CreateScheduledTransferRunParams) (ScheduledTransferRun, error)
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
+	CreateTransferBatch(ctx context.Context) (TransferBatch, error)
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateWebhookDeliveries(ctx context.Context, id int64) error
+	CreateWebhookSubscription(ctx context.Context, arg CreateWebhookSubscriptionParams) (WebhookSubscription, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountTransferLimit(ctx context.Context, accountID int64) error
+	DeleteScheduledTransfer(ctx context.Context, id int64) error
+	DeleteTierTransferLimit(ctx context.Context, arg DeleteTierTransferLimitParams) error
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceAsOf(ctx context.Context, arg GetAccountBalanceAsOfParams) (int64, error)
+	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountTransferLimit(ctx context.Context, accountID int64) (AccountTransferLimit, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
+	GetEffectiveTransferLimit(ctx context.Context, id int64) (GetEffectiveTransferLimitRow, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetEntryCutoffID(ctx context.Context, arg GetEntryCutoffIDParams) (int64, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetLastAuditEvent(ctx context.Context) (AuditEvent, error)
+	GetLatestReconciliationRun(ctx context.Context) (ReconciliationRun, error)
+	GetPendingTransfer(ctx context.Context, id int64) (PendingTransfer, error)
+	GetPendingTransferForUpdate(ctx context.Context, id int64) (PendingTransfer, error)
+	GetReconciliationRun(ctx context.Context, id int64) (ReconciliationRun, error)
+	GetScheduledTransfer(ctx context.Context, id int64) (ScheduledTransfer, error)
+	GetSession(ctx context.Context, id uuid.UUID) (Session, error)
+	GetTierTransferLimit(ctx context.Context, arg GetTierTransferLimitParams) (TierTransferLimit, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferCutoffID(ctx context.Context, arg GetTransferCutoffIDParams) (int64, error)
+	GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error)
+	GetTransferLimitUsage(ctx context.Context, fromAccountID int64) (GetTransferLimitUsageRow, error)
+	GetUser(ctx context.Context, username string) (User, error)
+	GetWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (WebhookSubscription, error)
+	LeaseScheduledTransfers(ctx context.Context, arg LeaseScheduledTransfersParams) error
+	LeaseWebhookDeliveries(ctx context.Context, arg LeaseWebhookDeliveriesParams) error
+	ListAccountEntryTotals(ctx context.Context, arg ListAccountEntryTotalsParams) ([]ListAccountEntryTotalsRow, error)
+	ListAccountEntryTotalsByID(ctx context.Context, ids []int64) ([]ListAccountEntryTotalsByIDRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsByOwner(ctx context.Context, arg ListAccountsByOwnerParams) ([]Account, error)
+	ListAccountsByOwnerAfter(ctx context.Context, arg ListAccountsByOwnerAfterParams) ([]Account, error)
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	ListAuditEventsAfter(ctx context.Context, arg ListAuditEventsAfterParams) ([]AuditEvent, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListDueScheduledTransfers(ctx context.Context, arg ListDueScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListDueWebhookDeliveries(ctx context.Context, arg ListDueWebhookDeliveriesParams) ([]ListDueWebhookDeliveriesRow, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesAfter(ctx context.Context, arg ListEntriesAfterParams) ([]Entry, error)
 	ListEntriesForAccount(ctx context.Context, arg ListEntriesForAccountParams) ([]Entry, error)
+	ListEntriesForAccountAfter(ctx context.Context, arg ListEntriesForAccountAfterParams) ([]Entry, error)
+	ListEntriesWithBalanceForAccount(ctx context.Context, arg ListEntriesWithBalanceForAccountParams) ([]ListEntriesWithBalanceForAccountRow, error)
+	ListEntriesWithBalanceForAccountAfter(ctx context.Context, arg ListEntriesWithBalanceForAccountAfterParams) ([]ListEntriesWithBalanceForAccountAfterRow, error)
+	ListEntryTransfers(ctx context.Context, arg ListEntryTransfersParams) ([]ListEntryTransfersRow, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListExpiredHolds(ctx context.Context, arg ListExpiredHoldsParams) ([]Hold, error)
+	ListPendingTransfers(ctx context.Context, arg ListPendingTransfersParams) ([]PendingTransfer, error)
+	ListReconciliationRuns(ctx context.Context, arg ListReconciliationRunsParams) ([]ReconciliationRun, error)
+	ListScheduledTransferRuns(ctx context.Context, arg ListScheduledTransferRunsParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransferRunsAfter(ctx context.Context, arg ListScheduledTransferRunsAfterParams) ([]ScheduledTransferRun, error)
+	ListScheduledTransfers(ctx context.Context, arg ListScheduledTransfersParams) ([]ScheduledTransfer, error)
+	ListScheduledTransfersAfter(ctx context.Context, arg ListScheduledTransfersAfterParams) ([]ScheduledTransfer, error)
+	ListTierTransferLimits(ctx context.Context) ([]TierTransferLimit, error)
+	ListTransferAmountsSince(ctx context.Context, arg ListTransferAmountsSinceParams) ([]int64, error)
+	ListTransferEntryTotals(ctx context.Context, arg ListTransferEntryTotalsParams) ([]ListTransferEntryTotalsRow, error)
+	ListTransferReversals(ctx context.Context, reversalOf sql.NullInt64) ([]Transfer, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error)
 	ListTransfersForAccount(ctx context.Context, arg ListTransfersForAccountParams) ([]Transfer, error)
+	ListTransfersForAccountAfter(ctx context.Context, arg ListTransfersForAccountAfterParams) ([]Transfer, error)
+	ListUnpublishedOutboxEvents(ctx context.Context, limit int32) ([]Outbox, error)
+	ListWebhookDeliveries(ctx context.Context, arg ListWebhookDeliveriesParams) ([]WebhookDelivery, error)
+	ListWebhookSubscriptions(ctx context.Context, arg ListWebhookSubscriptionsParams) ([]WebhookSubscription, error)
+	LockAuditChain(ctx context.Context) error
+	MarkOutboxEventFailed(ctx context.Context, arg MarkOutboxEventFailedParams) error
+	MarkOutboxEventPublished(ctx context.Context, id int64) error
+	RetryWebhookDelivery(ctx context.Context, id int64) (WebhookDelivery, error)
+	ReviewPendingTransfer(ctx context.Context, arg ReviewPendingTransferParams) (PendingTransfer, error)
+	SearchEntriesByAmount(ctx context.Context, arg SearchEntriesByAmountParams) ([]Entry, error)
+	SearchEntriesByAmountDesc(ctx context.Context, arg SearchEntriesByAmountDescParams) ([]Entry, error)
+	SearchEntriesByCreatedAt(ctx context.Context, arg SearchEntriesByCreatedAtParams) ([]Entry, error)
+	SearchEntriesByCreatedAtDesc(ctx context.Context, arg SearchEntriesByCreatedAtDescParams) ([]Entry, error)
+	SearchEntriesForAccountByAmount(ctx context.Context, arg SearchEntriesForAccountByAmountParams) ([]Entry, error)
+	SearchEntriesForAccountByAmountDesc(ctx context.Context, arg SearchEntriesForAccountByAmountDescParams) ([]Entry, error)
+	SearchEntriesForAccountByCreatedAt(ctx context.Context, arg SearchEntriesForAccountByCreatedAtParams) ([]Entry, error)
+	SearchEntriesForAccountByCreatedAtDesc(ctx context.Context, arg SearchEntriesForAccountByCreatedAtDescParams) ([]Entry, error)
+	SearchTransfersByAmount(ctx context.Context, arg SearchTransfersByAmountParams) ([]Transfer, error)
+	SearchTransfersByAmountDesc(ctx context.Context, arg SearchTransfersByAmountDescParams) ([]Transfer, error)
+	SearchTransfersByCreatedAt(ctx context.Context, arg SearchTransfersByCreatedAtParams) ([]Transfer, error)
+	SearchTransfersByCreatedAtDesc(ctx context.Context, arg SearchTransfersByCreatedAtDescParams) ([]Transfer, error)
+	SearchTransfersForAccountByAmount(ctx context.Context, arg SearchTransfersForAccountByAmountParams) ([]Transfer, error)
+	SearchTransfersForAccountByAmountDesc(ctx context.Context, arg SearchTransfersForAccountByAmountDescParams) ([]Transfer, error)
+	SearchTransfersForAccountByCreatedAt(ctx context.Context, arg SearchTransfersForAccountByCreatedAtParams) ([]Transfer, error)
+	SearchTransfersForAccountByCreatedAtDesc(ctx context.Context, arg SearchTransfersForAccountByCreatedAtDescParams) ([]Transfer, error)
+	SetCurrencyEnabled(ctx context.Context, arg SetCurrencyEnabledParams) (Currency, error)
+	TryLockOutbox(ctx context.Context) (bool, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateAccountStatus(ctx context.Context, arg UpdateAccountStatusParams) (Account, error)
+	UpdateClaimedScheduledTransfer(ctx context.Context, arg UpdateClaimedScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateHold(ctx context.Context, arg UpdateHoldParams) (Hold, error)
+	UpdateScheduledTransfer(ctx context.Context, arg UpdateScheduledTransferParams) (ScheduledTransfer, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserTier(ctx context.Context, arg UpdateUserTierParams) (User, error)
+	UpdateWebhookDelivery(ctx context.Context, arg UpdateWebhookDeliveryParams) (WebhookDelivery, error)
+	UpdateWebhookSubscription(ctx context.Context, arg UpdateWebhookSubscriptionParams) (WebhookSubscription, error)
+	UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (AccountTransferLimit, error)
+	UpsertCashAccount(ctx context.Context, arg UpsertCashAccountParams) (Account, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertFXAccount(ctx context.Context, arg UpsertFXAccountParams) (Account, error)
+	UpsertTierTransferLimit(ctx context.Context, arg UpsertTierTransferLimitParams) (TierTransferLimit, error)
 }
 
 var _ Querier = (*Queries)(nil)
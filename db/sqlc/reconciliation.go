@@ -0,0 +1,314 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+const (
+	OrphanedEntryNoTransfer      = "no_transfer"
+	OrphanedEntryAccountMismatch = "account_mismatch"
+)
+
+const (
+	defaultReconcileBatchSize = 500
+	// defaultReconcileSettleTime is how old a row must be before it is checked, when ReconcileParams.Before is not set
+	defaultReconcileSettleTime = time.Minute
+)
+
+type ReconcileParams struct {
+	// Full checks every account, transfer and entry instead of continuing from the last run's checkpoint
+	Full bool
+	// Before ends the run at the newest transfer and entry created before it, so transactions that are
+	// still in flight are not reported as broken. It defaults to a minute ago.
+	Before    time.Time
+	BatchSize int32
+}
+
+// ReconciliationReport lists every discrepancy a reconciliation run found. An empty report has
+// Discrepancies set to zero and empty lists, never null ones.
+type ReconciliationReport struct {
+	RunID int64 `json:"run_id"`
+	Full  bool  `json:"full"`
+	// the transfers and entries checked are those with ids in (After, Last]
+	AfterTransferID    int64              `json:"after_transfer_id"`
+	LastTransferID     int64              `json:"last_transfer_id"`
+	AfterEntryID       int64              `json:"after_entry_id"`
+	LastEntryID        int64              `json:"last_entry_id"`
+	AccountsChecked    int64              `json:"accounts_checked"`
+	TransfersChecked   int64              `json:"transfers_checked"`
+	EntriesChecked     int64              `json:"entries_checked"`
+	Discrepancies      int64              `json:"discrepancies"`
+	AccountDrifts      []AccountDrift     `json:"account_drifts"`
+	TransferMismatches []TransferMismatch `json:"transfer_mismatches"`
+	OrphanedEntries    []OrphanedEntry    `json:"orphaned_entries"`
+}
+
+// AccountDrift is an account whose balance differs from the sum of its entries
+type AccountDrift struct {
+	AccountID    int64 `json:"account_id"`
+	Balance      int64 `json:"balance"`
+	EntriesTotal int64 `json:"entries_total"`
+	// Drift is Balance - EntriesTotal
+	Drift int64 `json:"drift"`
+}
+
+// TransferMismatch is a transfer that does not have exactly one debit of Amount and one credit of ToAmount,
+// or whose entries, fx legs included, do not add up to zero in each of its currencies
+type TransferMismatch struct {
+	TransferID int64 `json:"transfer_id"`
+	Amount     int64 `json:"amount"`
+	ToAmount   int64 `json:"to_amount"`
+	EntryCount int64 `json:"entry_count"`
+	FromTotal  int64 `json:"from_total"`
+	ToTotal    int64 `json:"to_total"`
+	// FromCurrencyTotal and ToCurrencyTotal sum the entries of the transfer in the currency of either account
+	FromCurrencyTotal int64 `json:"from_currency_total"`
+	ToCurrencyTotal   int64 `json:"to_currency_total"`
+}
+
+// OrphanedEntry is an entry that no transfer accounts for
+type OrphanedEntry struct {
+	EntryID    int64         `json:"entry_id"`
+	AccountID  int64         `json:"account_id"`
+	Amount     int64         `json:"amount"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	Reason     string        `json:"reason"`
+}
+
+// Reconcile checks the ledger and records the report as a new reconciliation run. Transfers and entries are
+// checked from the previous run's checkpoint onwards; an incremental run only checks the balances of accounts
+// with new entries, so drift introduced without posting an entry is only found by a full run.
+// Everything is read from one snapshot, so concurrent transfers cannot show up as half posted.
+func (store *SQLStore) Reconcile(ctx context.Context, arg ReconcileParams) (ReconciliationReport, error) {
+	report := ReconciliationReport{
+		Full:               arg.Full,
+		AccountDrifts:      []AccountDrift{},
+		TransferMismatches: []TransferMismatch{},
+		OrphanedEntries:    []OrphanedEntry{},
+	}
+	batchSize := arg.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultReconcileBatchSize
+	}
+	before := arg.Before
+	if before.IsZero() {
+		before = time.Now().Add(-defaultReconcileSettleTime)
+	}
+
+	err := store.execTxOptions(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, func(q *Queries) error {
+		if !report.Full {
+			previous, err := q.GetLatestReconciliationRun(ctx)
+			switch {
+			case err == sql.ErrNoRows:
+				report.Full = true
+			case err != nil:
+				return err
+			default:
+				report.AfterTransferID = previous.LastTransferID
+				report.AfterEntryID = previous.LastEntryID
+			}
+		}
+		report.LastTransferID = report.AfterTransferID
+		report.LastEntryID = report.AfterEntryID
+
+		if err := reconcileTransfers(ctx, q, &report, before, batchSize); err != nil {
+			return err
+		}
+		touched, err := reconcileEntries(ctx, q, &report, before, batchSize)
+		if err != nil {
+			return err
+		}
+		if report.Full {
+			err = reconcileAllAccounts(ctx, q, &report, batchSize)
+		} else {
+			err = reconcileAccounts(ctx, q, &report, touched, batchSize)
+		}
+		if err != nil {
+			return err
+		}
+
+		report.Discrepancies = int64(len(report.AccountDrifts) + len(report.TransferMismatches) + len(report.OrphanedEntries))
+		body, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		run, err := q.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			FullScan:       report.Full,
+			LastTransferID: report.LastTransferID,
+			LastEntryID:    report.LastEntryID,
+			Discrepancies:  report.Discrepancies,
+			Report:         body,
+		})
+		report.RunID = run.ID
+		return err
+	})
+
+	return report, err
+}
+
+// reconcileTransfers checks the transfers up to the newest one created before the cutoff time. Ids and
+// created_at do not increase together, so the range is fixed by id up front: filtering each batch by
+// created_at would move the checkpoint past older transfers that are not visible yet.
+func reconcileTransfers(ctx context.Context, q *Queries, report *ReconciliationReport, before time.Time, batchSize int32) error {
+	cutoffID, err := q.GetTransferCutoffID(ctx, GetTransferCutoffIDParams{
+		AfterID: report.LastTransferID,
+		Before:  before,
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		transfers, err := q.ListTransferEntryTotals(ctx, ListTransferEntryTotalsParams{
+			Limit:    batchSize,
+			AfterID:  report.LastTransferID,
+			CutoffID: cutoffID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, transfer := range transfers {
+			// a cross-currency transfer also posts the two legs of its fx accounts, see postExchange
+			entryCount := int64(2)
+			if transfer.FromCurrency != transfer.ToCurrency {
+				entryCount = 4
+			}
+			if transfer.EntryCount != entryCount ||
+				transfer.FromTotal != -transfer.Amount || transfer.ToTotal != transfer.ToAmount ||
+				transfer.FromCurrencyTotal != 0 || transfer.ToCurrencyTotal != 0 {
+				report.TransferMismatches = append(report.TransferMismatches, TransferMismatch{
+					TransferID:        transfer.ID,
+					Amount:            transfer.Amount,
+					ToAmount:          transfer.ToAmount,
+					EntryCount:        transfer.EntryCount,
+					FromTotal:         transfer.FromTotal,
+					ToTotal:           transfer.ToTotal,
+					FromCurrencyTotal: transfer.FromCurrencyTotal,
+					ToCurrencyTotal:   transfer.ToCurrencyTotal,
+				})
+			}
+			report.LastTransferID = transfer.ID
+		}
+		report.TransfersChecked += int64(len(transfers))
+
+		if len(transfers) < int(batchSize) {
+			return nil
+		}
+	}
+}
+
+// reconcileEntries reports the entries that do not belong to a transfer and returns the accounts they touched.
+// Like reconcileTransfers it checks the entries up to the newest one created before the cutoff time.
+func reconcileEntries(ctx context.Context, q *Queries, report *ReconciliationReport, before time.Time, batchSize int32) ([]int64, error) {
+	var touched []int64
+	seen := make(map[int64]bool)
+
+	cutoffID, err := q.GetEntryCutoffID(ctx, GetEntryCutoffIDParams{
+		AfterID: report.LastEntryID,
+		Before:  before,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		entries, err := q.ListEntryTransfers(ctx, ListEntryTransfersParams{
+			Limit:    batchSize,
+			AfterID:  report.LastEntryID,
+			CutoffID: cutoffID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			reason := ""
+			switch {
+			case !entry.TransferID.Valid:
+				reason = OrphanedEntryNoTransfer
+			case entry.AccountID != entry.FromAccountID.Int64 && entry.AccountID != entry.ToAccountID.Int64 &&
+				entry.AccountKind != AccountKindFX:
+				reason = OrphanedEntryAccountMismatch
+			}
+			if reason != "" {
+				report.OrphanedEntries = append(report.OrphanedEntries, OrphanedEntry{
+					EntryID:    entry.ID,
+					AccountID:  entry.AccountID,
+					Amount:     entry.Amount,
+					TransferID: entry.TransferID,
+					Reason:     reason,
+				})
+			}
+
+			if !seen[entry.AccountID] {
+				seen[entry.AccountID] = true
+				touched = append(touched, entry.AccountID)
+			}
+			report.LastEntryID = entry.ID
+		}
+		report.EntriesChecked += int64(len(entries))
+
+		if len(entries) < int(batchSize) {
+			return touched, nil
+		}
+	}
+}
+
+func reconcileAllAccounts(ctx context.Context, q *Queries, report *ReconciliationReport, batchSize int32) error {
+	var lastID int64
+	for {
+		accounts, err := q.ListAccountEntryTotals(ctx, ListAccountEntryTotalsParams{
+			ID:    lastID,
+			Limit: batchSize,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			checkAccountDrift(report, account.ID, account.Balance, account.EntriesTotal)
+			lastID = account.ID
+		}
+
+		if len(accounts) < int(batchSize) {
+			return nil
+		}
+	}
+}
+
+func reconcileAccounts(ctx context.Context, q *Queries, report *ReconciliationReport, accountIDs []int64, batchSize int32) error {
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+
+	for start := 0; start < len(accountIDs); start += int(batchSize) {
+		end := start + int(batchSize)
+		if end > len(accountIDs) {
+			end = len(accountIDs)
+		}
+
+		accounts, err := q.ListAccountEntryTotalsByID(ctx, accountIDs[start:end])
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			checkAccountDrift(report, account.ID, account.Balance, account.EntriesTotal)
+		}
+	}
+	return nil
+}
+
+func checkAccountDrift(report *ReconciliationReport, accountID, balance, entriesTotal int64) {
+	report.AccountsChecked++
+	if balance != entriesTotal {
+		report.AccountDrifts = append(report.AccountDrifts, AccountDrift{
+			AccountID:    accountID,
+			Balance:      balance,
+			EntriesTotal: entriesTotal,
+			Drift:        balance - entriesTotal,
+		})
+	}
+}
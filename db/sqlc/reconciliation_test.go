@@ -0,0 +1,176 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func findAccountDrift(report ReconciliationReport, accountID int64) (AccountDrift, bool) {
+	for _, drift := range report.AccountDrifts {
+		if drift.AccountID == accountID {
+			return drift, true
+		}
+	}
+	return AccountDrift{}, false
+}
+
+func findOrphanedEntry(report ReconciliationReport, entryID int64) (OrphanedEntry, bool) {
+	for _, entry := range report.OrphanedEntries {
+		if entry.EntryID == entryID {
+			return entry, true
+		}
+	}
+	return OrphanedEntry{}, false
+}
+
+func TestReconcile(t *testing.T) {
+	store := NewStore(testDb)
+	before := time.Now().Add(time.Minute)
+
+	account, payees := createBatchAccounts(t, 0, 1)
+	payee := payees[0]
+
+	_, err := store.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+	transfer, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   payee.ID,
+		Amount:        40,
+	})
+	require.NoError(t, err)
+	require.Equal(t, sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}, transfer.FromEntry.TransferID)
+	require.Equal(t, sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}, transfer.ToEntry.TransferID)
+
+	report, err := store.Reconcile(context.Background(), ReconcileParams{Full: true, Before: before})
+	require.NoError(t, err)
+	require.NotZero(t, report.RunID)
+	require.True(t, report.Full)
+	require.Zero(t, report.AfterTransferID)
+	require.GreaterOrEqual(t, report.LastTransferID, transfer.Transfer.ID)
+	require.GreaterOrEqual(t, report.LastEntryID, transfer.ToEntry.ID)
+	for _, mismatch := range report.TransferMismatches {
+		require.NotEqual(t, transfer.Transfer.ID, mismatch.TransferID)
+	}
+	_, found := findAccountDrift(report, account.ID)
+	require.False(t, found)
+	_, found = findAccountDrift(report, payee.ID)
+	require.False(t, found)
+
+	run, err := testQueries.GetReconciliationRun(context.Background(), report.RunID)
+	require.NoError(t, err)
+	require.Equal(t, report.LastTransferID, run.LastTransferID)
+	require.Equal(t, report.LastEntryID, run.LastEntryID)
+	require.Equal(t, report.Discrepancies, run.Discrepancies)
+
+	// move money without posting entries, and post an entry without a transfer
+	_, err = testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{ID: payee.ID, Amount: 5})
+	require.NoError(t, err)
+	orphan, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 7})
+	require.NoError(t, err)
+
+	incremental, err := store.Reconcile(context.Background(), ReconcileParams{Before: before})
+	require.NoError(t, err)
+	require.False(t, incremental.Full)
+	require.Equal(t, report.LastEntryID, incremental.AfterEntryID)
+	require.Equal(t, report.LastTransferID, incremental.AfterTransferID)
+
+	orphaned, found := findOrphanedEntry(incremental, orphan.ID)
+	require.True(t, found)
+	require.Equal(t, OrphanedEntryNoTransfer, orphaned.Reason)
+
+	drift, found := findAccountDrift(incremental, account.ID)
+	require.True(t, found)
+	require.Equal(t, int64(-7), drift.Drift)
+
+	// the payee has no new entries, so only a full run notices its drift
+	_, found = findAccountDrift(incremental, payee.ID)
+	require.False(t, found)
+
+	report, err = store.Reconcile(context.Background(), ReconcileParams{Full: true, Before: before})
+	require.NoError(t, err)
+	drift, found = findAccountDrift(report, payee.ID)
+	require.True(t, found)
+	require.Equal(t, int64(5), drift.Drift)
+	require.Equal(t, int64(40), drift.EntriesTotal)
+}
+
+func TestReconcileCrossCurrencyTransfer(t *testing.T) {
+	store := NewStore(testDb)
+	before := time.Now().Add(time.Minute)
+
+	account := fundAccount(t, createAccountInCurrency(t, "USD"), 100)
+	payee := createAccountInCurrency(t, "EUR")
+
+	transfer, err := store.CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		FromAccountID: account.ID,
+		ToAccountID:   payee.ID,
+		Amount:        50,
+		ToAmount:      46,
+		ExchangeRate:  "0.92",
+	})
+	require.NoError(t, err)
+
+	report, err := store.Reconcile(context.Background(), ReconcileParams{Full: true, Before: before})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, report.LastTransferID, transfer.Transfer.ID)
+	for _, mismatch := range report.TransferMismatches {
+		require.NotEqual(t, transfer.Transfer.ID, mismatch.TransferID)
+	}
+	for _, orphaned := range report.OrphanedEntries {
+		require.NotEqual(t, sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}, orphaned.TransferID)
+	}
+
+	// an entry that throws one currency out of balance is reported against the transfer
+	_, err = testQueries.CreateEntry(context.Background(), CreateEntryParams{
+		AccountID:  getFXAccount(t, "EUR").ID,
+		Amount:     1,
+		TransferID: sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	report, err = store.Reconcile(context.Background(), ReconcileParams{Full: true, Before: before})
+	require.NoError(t, err)
+
+	var mismatch *TransferMismatch
+	for i := range report.TransferMismatches {
+		if report.TransferMismatches[i].TransferID == transfer.Transfer.ID {
+			mismatch = &report.TransferMismatches[i]
+		}
+	}
+	require.NotNil(t, mismatch)
+	require.Equal(t, int64(5), mismatch.EntryCount)
+	require.Zero(t, mismatch.FromCurrencyTotal)
+	require.Equal(t, int64(1), mismatch.ToCurrencyTotal)
+}
+
+func TestReconcileOutOfOrderEntries(t *testing.T) {
+	store := NewStore(testDb)
+	before := time.Now().Add(time.Minute)
+
+	account := createRandomAccount(t)
+	report, err := store.Reconcile(context.Background(), ReconcileParams{Before: before})
+	require.NoError(t, err)
+
+	// the older entry gets a later created_at, as it would if its transaction committed last
+	older, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 3})
+	require.NoError(t, err)
+	newer, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{AccountID: account.ID, Amount: 4})
+	require.NoError(t, err)
+	_, err = testDb.ExecContext(context.Background(),
+		"UPDATE entries SET created_at = $2 WHERE id = $1", older.ID, before.Add(time.Minute))
+	require.NoError(t, err)
+
+	incremental, err := store.Reconcile(context.Background(), ReconcileParams{Before: before})
+	require.NoError(t, err)
+	require.Equal(t, report.LastEntryID, incremental.AfterEntryID)
+	require.GreaterOrEqual(t, incremental.LastEntryID, newer.ID)
+
+	_, found := findOrphanedEntry(incremental, older.ID)
+	require.True(t, found)
+	_, found = findOrphanedEntry(incremental, newer.ID)
+	require.True(t, found)
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math/big"
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount is in the currency of the original source account. Zero reverses whatever is left.
+	Amount int64 `json:"amount"`
+	// Idempotency, when set, stores the result under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+type ReverseTransferTxResult struct {
+	// Original is the reversed transfer, with its updated reversed amount
+	Original Transfer         `json:"original"`
+	Reversal TransferTxResult `json:"reversal"`
+}
+
+// ReverseTransferTx sends all or part of a transfer back to its source account. The reversal is a transfer
+// in the opposite direction that points at the original, which keeps track of how much was reversed so far.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		original, err := q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+		if original.ReversalOf.Valid {
+			return ErrReverseReversal
+		}
+
+		remaining := original.Amount - original.ReversedAmount
+		if remaining == 0 {
+			return ErrTransferReversed
+		}
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remaining
+		}
+		if amount < 0 || amount > remaining {
+			return ErrReversalExceedsTransfer
+		}
+
+		// the destination gives back its share of the original credit, at the original rate
+		toAmount := convertedShare(original, original.ReversedAmount+amount) - convertedShare(original, original.ReversedAmount)
+		if toAmount <= 0 {
+			return ErrReversalTooSmall
+		}
+		rate, err := inverseRate(original.ExchangeRate)
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = postTransfer(ctx, q, CrossCurrencyTransferTxParams{
+			FromAccountID: original.ToAccountID,
+			ToAccountID:   original.FromAccountID,
+			Amount:        toAmount,
+			ToAmount:      amount,
+			ExchangeRate:  rate,
+			ReversalOf:    sql.NullInt64{Int64: original.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Original, err = q.AddTransferReversedAmount(ctx, AddTransferReversedAmountParams{
+			ID:     original.ID,
+			Amount: amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.Idempotency != nil {
+			if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, result); err != nil {
+				return err
+			}
+		}
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "transfer.reverse",
+			ResourceType: "transfer",
+			ResourceID:   auditID(original.ID),
+			Before:       original,
+			After:        result,
+		})
+	})
+
+	return result, err
+}
+
+// convertedShare is the part of the transfer's ToAmount that corresponds to amount. Working on running
+// totals makes the reversals of a transfer add up to exactly its ToAmount.
+func convertedShare(transfer Transfer, amount int64) int64 {
+	share := new(big.Int).Mul(big.NewInt(transfer.ToAmount), big.NewInt(amount))
+	return share.Quo(share, big.NewInt(transfer.Amount)).Int64()
+}
+
+func inverseRate(rate string) (string, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return "", fmt.Errorf("invalid exchange rate %q", rate)
+	}
+	return r.Inv(r).FloatString(10), nil
+}
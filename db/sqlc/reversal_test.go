@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createReversibleTransfer(t *testing.T, amount, toAmount int64, rate string) TransferTxResult {
+	account1 := fundAccount(t, createRandomAccount(t), 1000)
+	account2 := fundAccount(t, createRandomAccount(t), 1000)
+
+	result, err := NewStore(testDb).CrossCurrencyTransferTx(context.Background(), CrossCurrencyTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  rate,
+	})
+	require.NoError(t, err)
+	return result
+}
+
+func TestReverseTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+	original := createReversibleTransfer(t, 100, 100, "1")
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, original.Transfer.Amount, result.Original.ReversedAmount)
+
+	reversal := result.Reversal.Transfer
+	require.True(t, reversal.ReversalOf.Valid)
+	require.Equal(t, original.Transfer.ID, reversal.ReversalOf.Int64)
+	require.Equal(t, original.Transfer.ToAccountID, reversal.FromAccountID)
+	require.Equal(t, original.Transfer.FromAccountID, reversal.ToAccountID)
+	require.Equal(t, int64(100), reversal.Amount)
+
+	require.Equal(t, original.FromAccount.Balance+100, result.Reversal.ToAccount.Balance)
+	require.Equal(t, original.ToAccount.Balance-100, result.Reversal.FromAccount.Balance)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferReversed)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: reversal.ID,
+	})
+	require.ErrorIs(t, err, ErrReverseReversal)
+
+	reversals, err := testQueries.ListTransferReversals(context.Background(), reversal.ReversalOf)
+	require.NoError(t, err)
+	require.Len(t, reversals, 1)
+	require.Equal(t, reversal.ID, reversals[0].ID)
+}
+
+func TestReverseTransferTxPartial(t *testing.T) {
+	store := NewStore(testDb)
+	original := createReversibleTransfer(t, 100, 100, "1")
+
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(30), result.Original.ReversedAmount)
+	require.Equal(t, int64(30), result.Reversal.Transfer.Amount)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsTransfer)
+
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), result.Original.ReversedAmount)
+	require.Equal(t, int64(70), result.Reversal.Transfer.Amount)
+}
+
+func TestReverseTransferTxCrossCurrency(t *testing.T) {
+	store := NewStore(testDb)
+	original := createReversibleTransfer(t, 100, 92, "0.92")
+
+	var debited int64
+	for _, amount := range []int64{33, 33, 34} {
+		result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+			TransferID: original.Transfer.ID,
+			Amount:     amount,
+		})
+		require.NoError(t, err)
+		require.Equal(t, amount, result.Reversal.Transfer.ToAmount)
+		debited += result.Reversal.Transfer.Amount
+	}
+
+	// the reversals take back exactly what the original credited
+	require.Equal(t, original.Transfer.ToAmount, debited)
+}
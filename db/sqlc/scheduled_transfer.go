@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusPaused    = "paused"
+	ScheduledTransferStatusCompleted = "completed"
+)
+
+const (
+	ScheduledTransferRunSucceeded = "succeeded"
+	ScheduledTransferRunFailed    = "failed"
+)
+
+// ScheduledTransferOutcome is what running a scheduled transfer produced and where its schedule goes next
+type ScheduledTransferOutcome struct {
+	// TransferID is only valid when the run moved money. RunScheduledTransferTx sets it for the transfer
+	// it makes.
+	TransferID sql.NullInt64
+	Error      string
+	Next       UpdateScheduledTransferParams
+}
+
+type CreateScheduledTransferTxParams struct {
+	CreateScheduledTransferParams
+	// Idempotency, when set, stores the scheduled transfer under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+}
+
+// CreateScheduledTransferTx creates a standing order together with its audit record
+func (store *SQLStore) CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferTxParams) (ScheduledTransfer, error) {
+	var scheduled ScheduledTransfer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		scheduled, err = q.CreateScheduledTransfer(ctx, arg.CreateScheduledTransferParams)
+		if err != nil {
+			return err
+		}
+
+		if arg.Idempotency != nil {
+			if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, scheduled); err != nil {
+				return err
+			}
+		}
+
+		return recordAudit(ctx, q, auditEvent{
+			Action:       "scheduled_transfer.create",
+			ResourceType: "scheduled_transfer",
+			ResourceID:   auditID(scheduled.ID),
+			After:        scheduled,
+		})
+	})
+
+	return scheduled, err
+}
+
+type ClaimScheduledTransfersTxParams struct {
+	Now   time.Time
+	Limit int32
+	// Lease is how long the claimed scheduled transfers are hidden from other workers. It has to outlast
+	// running all of them, a scheduled transfer whose lease ran out may be claimed again.
+	Lease time.Duration
+}
+
+type ClaimScheduledTransfersTxResult struct {
+	// Due holds the claimed scheduled transfers as they were before the lease, so NextRunAt is the
+	// occurrence to run
+	Due []ScheduledTransfer
+	// LeasedUntil identifies the claim when a run is recorded, see RunScheduledTransferTx
+	LeasedUntil time.Time
+}
+
+// ClaimScheduledTransfersTx locks the scheduled transfers that are due, skipping the ones another worker is
+// claiming, and moves their next run to the end of the lease. The transaction ends before any money moves;
+// each occurrence is run and recorded in its own transaction with RunScheduledTransferTx.
+func (store *SQLStore) ClaimScheduledTransfersTx(ctx context.Context, arg ClaimScheduledTransfersTxParams) (ClaimScheduledTransfersTxResult, error) {
+	result := ClaimScheduledTransfersTxResult{
+		// truncated to what postgres stores, so the lease still matches when the run is recorded
+		LeasedUntil: arg.Now.Add(arg.Lease).UTC().Truncate(time.Microsecond),
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result.Due, err = q.ListDueScheduledTransfers(ctx, ListDueScheduledTransfersParams{
+			NextRunAt: arg.Now,
+			Limit:     arg.Limit,
+		})
+		if err != nil || len(result.Due) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(result.Due))
+		for i, scheduled := range result.Due {
+			ids[i] = scheduled.ID
+		}
+		return q.LeaseScheduledTransfers(ctx, LeaseScheduledTransfersParams{
+			LeasedUntil: result.LeasedUntil,
+			Ids:         ids,
+		})
+	})
+
+	return result, err
+}
+
+type RunScheduledTransferTxParams struct {
+	// Scheduled is the scheduled transfer as it was claimed
+	Scheduled   ScheduledTransfer
+	LeasedUntil time.Time
+	// Transfer, when set, is made in the same transaction and recorded on the run. When it fails nothing
+	// is recorded and its error is returned.
+	Transfer *TransferTxParams
+	Outcome  ScheduledTransferOutcome
+}
+
+// RunScheduledTransferTx makes the transfer of a claimed occurrence of a scheduled transfer, records the run
+// and moves the schedule on, all in one transaction. It fails with sql.ErrNoRows when the claim was lost,
+// because the lease ran out and another worker claimed the scheduled transfer or it was changed since.
+func (store *SQLStore) RunScheduledTransferTx(ctx context.Context, arg RunScheduledTransferTxParams) (ScheduledTransferRun, error) {
+	var run ScheduledTransferRun
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		outcome := arg.Outcome
+		var events []auditEvent
+
+		if arg.Transfer != nil {
+			result, err := makeTransfer(ctx, q, CrossCurrencyTransferTxParams{
+				FromAccountID: arg.Transfer.FromAccountID,
+				ToAccountID:   arg.Transfer.ToAccountID,
+				Amount:        arg.Transfer.Amount,
+				ToAmount:      arg.Transfer.Amount,
+				ExchangeRate:  "1",
+				Idempotency:   arg.Transfer.Idempotency,
+			})
+			if err != nil {
+				return err
+			}
+
+			outcome.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+			events = append(events, transferAuditEvent("transfer.create", result))
+		}
+
+		next, err := q.UpdateClaimedScheduledTransfer(ctx, UpdateClaimedScheduledTransferParams{
+			ID:                  arg.Scheduled.ID,
+			NextRunAt:           outcome.Next.NextRunAt,
+			Status:              outcome.Next.Status,
+			ConsecutiveFailures: outcome.Next.ConsecutiveFailures,
+			LeasedUntil:         arg.LeasedUntil,
+		})
+		if err != nil {
+			return err
+		}
+
+		status := ScheduledTransferRunFailed
+		if outcome.TransferID.Valid {
+			status = ScheduledTransferRunSucceeded
+		}
+		run, err = q.CreateScheduledTransferRun(ctx, CreateScheduledTransferRunParams{
+			ScheduledTransferID: arg.Scheduled.ID,
+			TransferID:          outcome.TransferID,
+			ScheduledFor:        arg.Scheduled.NextRunAt,
+			Status:              status,
+			Error:               outcome.Error,
+		})
+		if err != nil {
+			return err
+		}
+
+		events = append(events, auditEvent{
+			Action:       "scheduled_transfer.run",
+			ResourceType: "scheduled_transfer",
+			ResourceID:   auditID(arg.Scheduled.ID),
+			Before:       arg.Scheduled,
+			After:        next,
+		})
+		return recordAudit(ctx, q, events...)
+	})
+
+	return run, err
+}
@@ -0,0 +1,289 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, nextRunAt time.Time) ScheduledTransfer {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := CreateScheduledTransferParams{
+		Owner:         account1.Owner,
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        utils.RandomMoney(),
+		Schedule:      "@every 24h",
+		NextRunAt:     nextRunAt,
+	}
+
+	scheduled, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, scheduled.ID)
+	require.Equal(t, arg.Owner, scheduled.Owner)
+	require.Equal(t, arg.FromAccountID, scheduled.FromAccountID)
+	require.Equal(t, arg.ToAccountID, scheduled.ToAccountID)
+	require.Equal(t, arg.Amount, scheduled.Amount)
+	require.Equal(t, arg.Schedule, scheduled.Schedule)
+	require.WithinDuration(t, arg.NextRunAt, scheduled.NextRunAt, time.Second)
+	require.False(t, scheduled.EndAt.Valid)
+	require.Equal(t, ScheduledTransferStatusActive, scheduled.Status)
+	require.Zero(t, scheduled.ConsecutiveFailures)
+
+	return scheduled
+}
+
+func TestCreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+}
+
+func TestCreateScheduledTransferTxIdempotency(t *testing.T) {
+	store := NewStore(testDb)
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := CreateScheduledTransferTxParams{
+		CreateScheduledTransferParams: CreateScheduledTransferParams{
+			Owner:         account1.Owner,
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        utils.RandomMoney(),
+			Schedule:      "@every 24h",
+			NextRunAt:     time.Now().Add(time.Hour),
+		},
+		Idempotency: &IdempotencyParams{
+			Username:     account1.Owner,
+			Key:          utils.RandomString(16),
+			RequestHash:  utils.RandomString(64),
+			ResponseCode: 201,
+		},
+	}
+
+	scheduled, err := store.CreateScheduledTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: arg.Idempotency.Username,
+		Key:      arg.Idempotency.Key,
+	})
+	require.NoError(t, err)
+
+	var storedScheduled ScheduledTransfer
+	require.NoError(t, json.Unmarshal(stored.ResponseBody, &storedScheduled))
+	require.Equal(t, scheduled.ID, storedScheduled.ID)
+
+	// a retry that got past the middleware is rolled back instead of creating a second standing order
+	_, err = store.CreateScheduledTransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+
+	scheduledTransfers, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner: account1.Owner,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, scheduledTransfers, 1)
+}
+
+func TestUpdateScheduledTransfer(t *testing.T) {
+	scheduled := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	arg := UpdateScheduledTransferParams{
+		ID:                  scheduled.ID,
+		Amount:              scheduled.Amount + 1,
+		Schedule:            "0 9 * * *",
+		NextRunAt:           scheduled.NextRunAt.Add(time.Hour),
+		EndAt:               sql.NullTime{Time: scheduled.NextRunAt.Add(48 * time.Hour), Valid: true},
+		Status:              ScheduledTransferStatusPaused,
+		ConsecutiveFailures: 3,
+	}
+	updated, err := testQueries.UpdateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Amount, updated.Amount)
+	require.Equal(t, arg.Schedule, updated.Schedule)
+	require.WithinDuration(t, arg.NextRunAt, updated.NextRunAt, time.Second)
+	require.True(t, updated.EndAt.Valid)
+	require.Equal(t, arg.Status, updated.Status)
+	require.Equal(t, arg.ConsecutiveFailures, updated.ConsecutiveFailures)
+}
+
+func TestDeleteScheduledTransfer(t *testing.T) {
+	scheduled := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	err := testQueries.DeleteScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestListScheduledTransfers(t *testing.T) {
+	scheduled := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	list, err := testQueries.ListScheduledTransfers(context.Background(), ListScheduledTransfersParams{
+		Owner:  scheduled.Owner,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	require.Equal(t, scheduled.ID, list[0].ID)
+}
+
+func claimScheduledTransfers(t *testing.T, store Store, now time.Time) ClaimScheduledTransfersTxResult {
+	claim, err := store.ClaimScheduledTransfersTx(context.Background(), ClaimScheduledTransfersTxParams{
+		Now:   now,
+		Limit: 100,
+		Lease: time.Minute,
+	})
+	require.NoError(t, err)
+	return claim
+}
+
+func claimed(claim ClaimScheduledTransfersTxResult, id int64) (ScheduledTransfer, bool) {
+	for _, scheduled := range claim.Due {
+		if scheduled.ID == id {
+			return scheduled, true
+		}
+	}
+	return ScheduledTransfer{}, false
+}
+
+func TestClaimScheduledTransfersTx(t *testing.T) {
+	store := NewStore(testDb)
+	nextRunAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	scheduled := createRandomScheduledTransfer(t, nextRunAt)
+	later := createRandomScheduledTransfer(t, time.Now().Add(time.Hour))
+
+	now := time.Now()
+	claim := claimScheduledTransfers(t, store, now)
+	due, ok := claimed(claim, scheduled.ID)
+	require.True(t, ok)
+	require.WithinDuration(t, nextRunAt, due.NextRunAt, time.Second)
+	_, ok = claimed(claim, later.ID)
+	require.False(t, ok)
+
+	leased, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.True(t, claim.LeasedUntil.Equal(leased.NextRunAt))
+	require.WithinDuration(t, now.Add(time.Minute), leased.NextRunAt, time.Second)
+
+	// a leased scheduled transfer is not claimed again until its lease runs out
+	_, ok = claimed(claimScheduledTransfers(t, store, now), scheduled.ID)
+	require.False(t, ok)
+	_, ok = claimed(claimScheduledTransfers(t, store, now.Add(2*time.Minute)), scheduled.ID)
+	require.True(t, ok)
+}
+
+func TestRunScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDb)
+	nextRunAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	scheduled := createRandomScheduledTransfer(t, nextRunAt)
+
+	claim := claimScheduledTransfers(t, store, time.Now())
+	due, ok := claimed(claim, scheduled.ID)
+	require.True(t, ok)
+
+	next := UpdateScheduledTransferParams{
+		ID:        due.ID,
+		Amount:    due.Amount,
+		Schedule:  due.Schedule,
+		NextRunAt: due.NextRunAt.Add(24 * time.Hour),
+		Status:    ScheduledTransferStatusActive,
+	}
+	run, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{
+		Scheduled:   due,
+		LeasedUntil: claim.LeasedUntil,
+		Transfer: &TransferTxParams{
+			FromAccountID: due.FromAccountID,
+			ToAccountID:   due.ToAccountID,
+			Amount:        1,
+		},
+		Outcome: ScheduledTransferOutcome{Next: next},
+	})
+	require.NoError(t, err)
+	require.NotZero(t, run.ID)
+	require.Equal(t, ScheduledTransferRunSucceeded, run.Status)
+	require.True(t, run.TransferID.Valid)
+	require.WithinDuration(t, nextRunAt, run.ScheduledFor, time.Second)
+
+	transfer, err := testQueries.GetTransfer(context.Background(), run.TransferID.Int64)
+	require.NoError(t, err)
+	require.Equal(t, due.FromAccountID, transfer.FromAccountID)
+	require.Equal(t, int64(1), transfer.Amount)
+
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, nextRunAt.Add(24*time.Hour), updated.NextRunAt, time.Second)
+
+	// the schedule moved on, so the claim can no longer record a run
+	_, err = store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{
+		Scheduled:   due,
+		LeasedUntil: claim.LeasedUntil,
+		Outcome:     ScheduledTransferOutcome{Error: ErrInsufficientFunds.Error(), Next: next},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	recorded, err := testQueries.ListScheduledTransferRuns(context.Background(), ListScheduledTransferRunsParams{
+		ScheduledTransferID: scheduled.ID,
+		Limit:               5,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	require.Equal(t, run.ID, recorded[0].ID)
+}
+
+func TestRunScheduledTransferTxFailed(t *testing.T) {
+	store := NewStore(testDb)
+	nextRunAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	scheduled := createRandomScheduledTransfer(t, nextRunAt)
+
+	claim := claimScheduledTransfers(t, store, time.Now())
+	due, ok := claimed(claim, scheduled.ID)
+	require.True(t, ok)
+
+	// a transfer that fails rolls the whole run back
+	_, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{
+		Scheduled:   due,
+		LeasedUntil: claim.LeasedUntil,
+		Transfer: &TransferTxParams{
+			FromAccountID: due.FromAccountID,
+			ToAccountID:   due.ToAccountID,
+			Amount:        1 << 40,
+		},
+		Outcome: ScheduledTransferOutcome{Next: UpdateScheduledTransferParams{
+			NextRunAt: due.NextRunAt.Add(24 * time.Hour),
+			Status:    ScheduledTransferStatusActive,
+		}},
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	run, err := store.RunScheduledTransferTx(context.Background(), RunScheduledTransferTxParams{
+		Scheduled:   due,
+		LeasedUntil: claim.LeasedUntil,
+		Outcome: ScheduledTransferOutcome{
+			Error: ErrInsufficientFunds.Error(),
+			Next: UpdateScheduledTransferParams{
+				NextRunAt:           due.NextRunAt.Add(time.Hour),
+				Status:              ScheduledTransferStatusActive,
+				ConsecutiveFailures: due.ConsecutiveFailures + 1,
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferRunFailed, run.Status)
+	require.False(t, run.TransferID.Valid)
+	require.Equal(t, ErrInsufficientFunds.Error(), run.Error)
+
+	updated, err := testQueries.GetScheduledTransfer(context.Background(), scheduled.ID)
+	require.NoError(t, err)
+	require.WithinDuration(t, nextRunAt.Add(time.Hour), updated.NextRunAt, time.Second)
+	require.Equal(t, int32(1), updated.ConsecutiveFailures)
+}
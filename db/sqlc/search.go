@@ -0,0 +1,232 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+const (
+	searchSortByAmount      = "amount"
+	searchDirectionIncoming = "incoming"
+	searchDirectionOutgoing = "outgoing"
+)
+
+// SearchTransfersParams filters and sorts the transfers. AccountID and CounterpartyID match either side of a
+// transfer, FromAccountID and ToAccountID only their own side. Without any of them every transfer is searched.
+// The After fields hold the cursor of the previous page.
+type SearchTransfersParams struct {
+	Limit          int32         `json:"limit"`
+	AccountID      sql.NullInt64 `json:"account_id"`
+	CounterpartyID sql.NullInt64 `json:"counterparty_id"`
+	FromAccountID  sql.NullInt64 `json:"from_account_id"`
+	ToAccountID    sql.NullInt64 `json:"to_account_id"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+	SortBy         string        `json:"sort_by"`
+	Descending     bool          `json:"descending"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+}
+
+// sides resolves the accounts of the search into the account whose outgoing transfers match, the account whose
+// incoming transfers match and the account that must be on the other side. scoped is false without any account.
+func (arg SearchTransfersParams) sides() (outgoing, incoming int64, counterparty sql.NullInt64, scoped bool) {
+	switch {
+	case arg.AccountID.Valid:
+		return arg.AccountID.Int64, arg.AccountID.Int64, arg.CounterpartyID, true
+	case arg.FromAccountID.Valid:
+		return arg.FromAccountID.Int64, 0, arg.ToAccountID, true
+	case arg.ToAccountID.Valid:
+		return 0, arg.ToAccountID.Int64, sql.NullInt64{}, true
+	case arg.CounterpartyID.Valid:
+		return arg.CounterpartyID.Int64, arg.CounterpartyID.Int64, sql.NullInt64{}, true
+	}
+	return 0, 0, sql.NullInt64{}, false
+}
+
+// SearchTransfers runs the query written for the sort order of the search, so every page is read in order from
+// an index instead of sorting all the matches. A search on an account reads the index of each side of it.
+func (store *SQLStore) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error) {
+	outgoing, incoming, counterparty, scoped := arg.sides()
+
+	if scoped && arg.SortBy == searchSortByAmount {
+		params := SearchTransfersForAccountByAmountParams{
+			Limit:             arg.Limit,
+			OutgoingAccountID: outgoing,
+			CounterpartyID:    counterparty,
+			StartTime:         arg.StartTime,
+			EndTime:           arg.EndTime,
+			MinAmount:         arg.MinAmount,
+			MaxAmount:         arg.MaxAmount,
+			AfterAmount:       arg.AfterAmount,
+			AfterCreatedAt:    arg.AfterCreatedAt,
+			AfterID:           arg.AfterID,
+			IncomingAccountID: incoming,
+		}
+		if arg.Descending {
+			return store.SearchTransfersForAccountByAmountDesc(ctx, SearchTransfersForAccountByAmountDescParams(params))
+		}
+		return store.SearchTransfersForAccountByAmount(ctx, params)
+	}
+
+	if scoped {
+		params := SearchTransfersForAccountByCreatedAtParams{
+			Limit:             arg.Limit,
+			OutgoingAccountID: outgoing,
+			CounterpartyID:    counterparty,
+			StartTime:         arg.StartTime,
+			EndTime:           arg.EndTime,
+			MinAmount:         arg.MinAmount,
+			MaxAmount:         arg.MaxAmount,
+			AfterCreatedAt:    arg.AfterCreatedAt,
+			AfterID:           arg.AfterID,
+			IncomingAccountID: incoming,
+		}
+		if arg.Descending {
+			return store.SearchTransfersForAccountByCreatedAtDesc(ctx, SearchTransfersForAccountByCreatedAtDescParams(params))
+		}
+		return store.SearchTransfersForAccountByCreatedAt(ctx, params)
+	}
+
+	if arg.SortBy == searchSortByAmount {
+		params := SearchTransfersByAmountParams{
+			Limit:          arg.Limit,
+			StartTime:      arg.StartTime,
+			EndTime:        arg.EndTime,
+			MinAmount:      arg.MinAmount,
+			MaxAmount:      arg.MaxAmount,
+			AfterAmount:    arg.AfterAmount,
+			AfterCreatedAt: arg.AfterCreatedAt,
+			AfterID:        arg.AfterID,
+		}
+		if arg.Descending {
+			return store.SearchTransfersByAmountDesc(ctx, SearchTransfersByAmountDescParams(params))
+		}
+		return store.SearchTransfersByAmount(ctx, params)
+	}
+
+	params := SearchTransfersByCreatedAtParams{
+		Limit:          arg.Limit,
+		StartTime:      arg.StartTime,
+		EndTime:        arg.EndTime,
+		MinAmount:      arg.MinAmount,
+		MaxAmount:      arg.MaxAmount,
+		AfterCreatedAt: arg.AfterCreatedAt,
+		AfterID:        arg.AfterID,
+	}
+	if arg.Descending {
+		return store.SearchTransfersByCreatedAtDesc(ctx, SearchTransfersByCreatedAtDescParams(params))
+	}
+	return store.SearchTransfersByCreatedAt(ctx, params)
+}
+
+// SearchEntriesParams filters and sorts the entries on their absolute amount, the direction is given by the sign.
+// The counterparty is an account with another entry of the same transfer. The After fields hold the cursor of the
+// previous page.
+type SearchEntriesParams struct {
+	Limit          int32          `json:"limit"`
+	AccountID      sql.NullInt64  `json:"account_id"`
+	Direction      sql.NullString `json:"direction"`
+	CounterpartyID sql.NullInt64  `json:"counterparty_id"`
+	StartTime      sql.NullTime   `json:"start_time"`
+	EndTime        sql.NullTime   `json:"end_time"`
+	MinAmount      sql.NullInt64  `json:"min_amount"`
+	MaxAmount      sql.NullInt64  `json:"max_amount"`
+	AfterID        sql.NullInt64  `json:"after_id"`
+	SortBy         string         `json:"sort_by"`
+	Descending     bool           `json:"descending"`
+	AfterAmount    sql.NullInt64  `json:"after_amount"`
+	AfterCreatedAt sql.NullTime   `json:"after_created_at"`
+}
+
+// sign is the sign of the amount of the entries in the direction of the search
+func (arg SearchEntriesParams) sign() sql.NullInt64 {
+	switch arg.Direction.String {
+	case searchDirectionIncoming:
+		return sql.NullInt64{Int64: 1, Valid: true}
+	case searchDirectionOutgoing:
+		return sql.NullInt64{Int64: -1, Valid: true}
+	}
+	return sql.NullInt64{}
+}
+
+// SearchEntries runs the query written for the sort order of the search, like SearchTransfers. The counterparty
+// is checked on the entries the index returns, it does not change the index the search reads.
+func (store *SQLStore) SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]Entry, error) {
+	if arg.AccountID.Valid && arg.SortBy == searchSortByAmount {
+		params := SearchEntriesForAccountByAmountParams{
+			Limit:          arg.Limit,
+			AccountID:      arg.AccountID.Int64,
+			StartTime:      arg.StartTime,
+			EndTime:        arg.EndTime,
+			MinAmount:      arg.MinAmount,
+			MaxAmount:      arg.MaxAmount,
+			Sign:           arg.sign(),
+			CounterpartyID: arg.CounterpartyID,
+			AfterAmount:    arg.AfterAmount,
+			AfterCreatedAt: arg.AfterCreatedAt,
+			AfterID:        arg.AfterID,
+		}
+		if arg.Descending {
+			return store.SearchEntriesForAccountByAmountDesc(ctx, SearchEntriesForAccountByAmountDescParams(params))
+		}
+		return store.SearchEntriesForAccountByAmount(ctx, params)
+	}
+
+	if arg.AccountID.Valid {
+		params := SearchEntriesForAccountByCreatedAtParams{
+			Limit:          arg.Limit,
+			AccountID:      arg.AccountID.Int64,
+			StartTime:      arg.StartTime,
+			EndTime:        arg.EndTime,
+			MinAmount:      arg.MinAmount,
+			MaxAmount:      arg.MaxAmount,
+			Sign:           arg.sign(),
+			CounterpartyID: arg.CounterpartyID,
+			AfterCreatedAt: arg.AfterCreatedAt,
+			AfterID:        arg.AfterID,
+		}
+		if arg.Descending {
+			return store.SearchEntriesForAccountByCreatedAtDesc(ctx, SearchEntriesForAccountByCreatedAtDescParams(params))
+		}
+		return store.SearchEntriesForAccountByCreatedAt(ctx, params)
+	}
+
+	if arg.SortBy == searchSortByAmount {
+		params := SearchEntriesByAmountParams{
+			Limit:          arg.Limit,
+			StartTime:      arg.StartTime,
+			EndTime:        arg.EndTime,
+			MinAmount:      arg.MinAmount,
+			MaxAmount:      arg.MaxAmount,
+			Sign:           arg.sign(),
+			CounterpartyID: arg.CounterpartyID,
+			AfterAmount:    arg.AfterAmount,
+			AfterCreatedAt: arg.AfterCreatedAt,
+			AfterID:        arg.AfterID,
+		}
+		if arg.Descending {
+			return store.SearchEntriesByAmountDesc(ctx, SearchEntriesByAmountDescParams(params))
+		}
+		return store.SearchEntriesByAmount(ctx, params)
+	}
+
+	params := SearchEntriesByCreatedAtParams{
+		Limit:          arg.Limit,
+		StartTime:      arg.StartTime,
+		EndTime:        arg.EndTime,
+		MinAmount:      arg.MinAmount,
+		MaxAmount:      arg.MaxAmount,
+		Sign:           arg.sign(),
+		CounterpartyID: arg.CounterpartyID,
+		AfterCreatedAt: arg.AfterCreatedAt,
+		AfterID:        arg.AfterID,
+	}
+	if arg.Descending {
+		return store.SearchEntriesByCreatedAtDesc(ctx, SearchEntriesByCreatedAtDescParams(params))
+	}
+	return store.SearchEntriesByCreatedAt(ctx, params)
+}
@@ -3,11 +3,35 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"sort"
 )
 
 type Store interface {
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParams) (TransferTxResult, error)
+	DepositTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	WithdrawTx(ctx context.Context, arg CashTxParams) (CashTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (Account, error)
+	CloseAccountTx(ctx context.Context, arg CloseAccountTxParams) (CloseAccountTxResult, error)
+	CreateScheduledTransferTx(ctx context.Context, arg CreateScheduledTransferTxParams) (ScheduledTransfer, error)
+	ClaimScheduledTransfersTx(ctx context.Context, arg ClaimScheduledTransfersTxParams) (ClaimScheduledTransfersTxResult, error)
+	RunScheduledTransferTx(ctx context.Context, arg RunScheduledTransferTxParams) (ScheduledTransferRun, error)
+	PlaceHold(ctx context.Context, arg PlaceHoldParams) (HoldResult, error)
+	CaptureHold(ctx context.Context, arg CaptureHoldParams) (CaptureHoldResult, error)
+	ReleaseHold(ctx context.Context, holdID int64) (HoldResult, error)
+	ExpireHolds(ctx context.Context, arg ExpireHoldsParams) ([]Hold, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	Reconcile(ctx context.Context, arg ReconcileParams) (ReconciliationReport, error)
+	CreatePendingTransferTx(ctx context.Context, arg CreatePendingTransferTxParams) (PendingTransfer, error)
+	ApprovePendingTransferTx(ctx context.Context, arg ApprovePendingTransferTxParams) (ApprovePendingTransferTxResult, error)
+	VerifyAuditChain(ctx context.Context, batchSize int32) (AuditVerification, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error)
+	ClaimWebhookDeliveriesTx(ctx context.Context, arg ClaimWebhookDeliveriesTxParams) ([]ListDueWebhookDeliveriesRow, error)
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error)
+	SearchEntries(ctx context.Context, arg SearchEntriesParams) ([]Entry, error)
 	Querier
 }
 type SQLStore struct {
@@ -23,7 +47,11 @@ func NewStore(db *sql.DB) Store {
 }
 
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxOptions(ctx, nil, fn)
+}
+
+func (store *SQLStore) execTxOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -45,7 +73,18 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Idempotency, when set, stores the result under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
 }
+
+// IdempotencyParams identifies the client request a transaction result is recorded against
+type IdempotencyParams struct {
+	Username     string
+	Key          string
+	RequestHash  string
+	ResponseCode int32
+}
+
 type TransferTxResult struct {
 	Transfer    Transfer `json:"transfer"`
 	FromAccount Account  `json:"from_account"`
@@ -55,49 +94,139 @@ type TransferTxResult struct {
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	return store.CrossCurrencyTransferTx(ctx, CrossCurrencyTransferTxParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      arg.Amount,
+		ExchangeRate:  "1",
+		Idempotency:   arg.Idempotency,
+	})
+}
+
+// CrossCurrencyTransferTxParams describes a transfer between accounts that may hold different currencies.
+// The caller converts the amount; the store only records the rate that was applied.
+type CrossCurrencyTransferTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Amount is debited from the source account, in its currency
+	Amount int64 `json:"amount"`
+	// ToAmount is credited to the destination account, in its currency
+	ToAmount     int64  `json:"to_amount"`
+	ExchangeRate string `json:"exchange_rate"`
+	// Idempotency, when set, stores the result under the caller's key in the same transaction
+	Idempotency *IdempotencyParams `json:"-"`
+	// ReversalOf links a reversal to the transfer it sends back, see ReverseTransferTx
+	ReversalOf sql.NullInt64 `json:"-"`
+	// BatchID groups the legs of a batch transfer, see BatchTransferTx
+	BatchID sql.NullInt64 `json:"-"`
+}
+
+// CrossCurrencyTransferTx debits Amount from the source account and credits ToAmount to the destination
+// account, recording both amounts and the exchange rate on the transfer row.
+func (store *SQLStore) CrossCurrencyTransferTx(ctx context.Context, arg CrossCurrencyTransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
-
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
-
+		result, err = makeTransfer(ctx, q, arg)
 		if err != nil {
 			return err
 		}
+		return recordAudit(ctx, q, transferAuditEvent("transfer.create", result))
+	})
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
-		if err != nil {
-			return err
-		}
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
-		})
-		if err != nil {
-			return err
+	return result, err
+
+}
+
+// makeTransfer checks the transfer limits, posts the transfer and stores the idempotency key when set.
+// The caller records the audit event.
+func makeTransfer(ctx context.Context, q *Queries, arg CrossCurrencyTransferTxParams) (TransferTxResult, error) {
+	err := checkTransferLimits(ctx, q, arg)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	result, err := postTransfer(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+
+	if arg.Idempotency != nil {
+		if err := storeIdempotencyKey(ctx, q, *arg.Idempotency, result); err != nil {
+			return result, err
 		}
+	}
+	return result, nil
+}
 
-		if arg.FromAccountID > arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+// postTransfer records a transfer with its pair of entries and moves the money between the two accounts,
+// converting between currencies through the fx accounts when they differ, see postExchange.
+// It is the single place where balances change, so every store transaction that moves money goes through it.
+func postTransfer(ctx context.Context, q *Queries, arg CrossCurrencyTransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		ToAmount:      arg.ToAmount,
+		ExchangeRate:  arg.ExchangeRate,
+		ReversalOf:    arg.ReversalOf,
+		BatchID:       arg.BatchID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.ToAmount,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if arg.FromAccountID > arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.ToAmount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.ToAmount, arg.FromAccountID, -arg.Amount)
+	}
+	if err != nil {
+		if isConstraintViolation(err, accountsBalanceCheck) {
+			return result, ErrInsufficientFunds
 		}
-		if err != nil {
-			return err
+		return result, err
+	}
+
+	for _, account := range []Account{result.FromAccount, result.ToAccount} {
+		if account.Status != AccountStatusActive {
+			return result, &AccountStatusError{AccountID: account.ID, Status: account.Status}
 		}
-		return nil
-	})
+	}
 
-	return result, err
+	if result.FromAccount.Kind != AccountKindCash && result.FromAccount.AvailableBalance < -result.FromAccount.OverdraftLimit {
+		return result, ErrInsufficientFunds
+	}
 
+	if result.FromAccount.Currency != result.ToAccount.Currency {
+		if err := postExchange(ctx, q, result, arg); err != nil {
+			return result, err
+		}
+	}
+
+	return result, addTransferEvents(ctx, q, result)
 }
 
 func addMoney(
@@ -122,3 +251,45 @@ func addMoney(
 
 	return
 }
+
+// lockAccounts takes row locks on every given account, highest id first like addMoney does,
+// so transactions touching any set of accounts acquire their locks in the same order.
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	ids := make([]int64, len(accountIDs))
+	copy(ids, accountIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		if _, locked := accounts[id]; locked {
+			continue
+		}
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("cannot lock account [%d]: %w", id, err)
+		}
+		accounts[id] = account
+	}
+	return accounts, nil
+}
+
+// storeIdempotencyKey records the serialized result of a transaction under the client's idempotency key.
+// A concurrent request that already claimed the key makes the whole transaction roll back.
+func storeIdempotencyKey(ctx context.Context, q *Queries, arg IdempotencyParams, result interface{}) error {
+	body, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateIdempotencyKey(ctx, CreateIdempotencyKeyParams{
+		Username:     arg.Username,
+		Key:          arg.Key,
+		RequestHash:  arg.RequestHash,
+		ResponseCode: arg.ResponseCode,
+		ResponseBody: body,
+	})
+	if isConstraintViolation(err, idempotencyKeysPkey) {
+		return ErrDuplicateIdempotencyKey
+	}
+	return err
+}
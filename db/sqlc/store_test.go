@@ -2,13 +2,47 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 
+	"github.com/mrityunjaygr8/simplebank/utils"
 	"github.com/stretchr/testify/require"
 )
 
+// fundAccount sets the balance of the account so that a test can move money out of it
+func fundAccount(t *testing.T, account Account, balance int64) Account {
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: balance,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func createAccountInCurrency(t *testing.T, currency string) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    createRandomUser(t).Username,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func getFXAccount(t *testing.T, currency string) Account {
+	account, err := testQueries.UpsertFXAccount(context.Background(), UpsertFXAccountParams{
+		Owner:    FXUsername,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountKindFX, account.Kind)
+	return account
+}
+
 func TestTransferTx(t *testing.T) {
-	account1 := createRandomAccount(t)
+	n := 5
+	amount := int64(10)
+
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
 	account2 := createRandomAccount(t)
 
 	store := NewStore(testDb)
@@ -17,9 +51,6 @@ func TestTransferTx(t *testing.T) {
 	results := make(chan TransferTxResult)
 	existed := make(map[int]bool)
 
-	n := 5
-	amount := int64(10)
-
 	for x := 0; x < n; x++ {
 		go func() {
 			transfer, err := store.TransferTx(context.Background(), TransferTxParams{
@@ -105,16 +136,16 @@ func TestTransferTx(t *testing.T) {
 
 }
 func TestTransferTxDeadlock(t *testing.T) {
-	account1 := createRandomAccount(t)
-	account2 := createRandomAccount(t)
+	n := 10
+	amount := int64(10)
+
+	account1 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
+	account2 := fundAccount(t, createRandomAccount(t), int64(n)*amount)
 
 	store := NewStore(testDb)
 
 	errs := make(chan error)
 
-	n := 10
-	amount := int64(10)
-
 	for x := 0; x < n; x++ {
 		fromAccountID := account1.ID
 		toAccountID := account2.ID
@@ -148,3 +179,129 @@ func TestTransferTxDeadlock(t *testing.T) {
 	require.Equal(t, account2.Balance, updatedAccount2.Balance)
 
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	store := NewStore(testDb)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestTransferTxIdempotency(t *testing.T) {
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	store := NewStore(testDb)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Idempotency: &IdempotencyParams{
+			Username:     account1.Owner,
+			Key:          utils.RandomString(16),
+			RequestHash:  utils.RandomString(64),
+			ResponseCode: 201,
+		},
+	}
+
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	stored, err := testQueries.GetIdempotencyKey(context.Background(), GetIdempotencyKeyParams{
+		Username: arg.Idempotency.Username,
+		Key:      arg.Idempotency.Key,
+	})
+	require.NoError(t, err)
+	require.Equal(t, arg.Idempotency.RequestHash, stored.RequestHash)
+
+	var storedResult TransferTxResult
+	require.NoError(t, json.Unmarshal(stored.ResponseBody, &storedResult))
+	require.Equal(t, result.Transfer.ID, storedResult.Transfer.ID)
+
+	// retrying with the same key must not move the money a second time
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrDuplicateIdempotencyKey)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-arg.Amount, updatedAccount1.Balance)
+}
+
+func TestCrossCurrencyTransferTx(t *testing.T) {
+	account1 := fundAccount(t, createAccountInCurrency(t, "USD"), 1000)
+	account2 := createAccountInCurrency(t, "EUR")
+	fxUSD := getFXAccount(t, "USD")
+	fxEUR := getFXAccount(t, "EUR")
+
+	store := NewStore(testDb)
+
+	arg := CrossCurrencyTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+		ToAmount:      92,
+		ExchangeRate:  "0.92",
+	}
+
+	result, err := store.CrossCurrencyTransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Amount, result.Transfer.Amount)
+	require.Equal(t, arg.ToAmount, result.Transfer.ToAmount)
+	require.Equal(t, "0.9200000000", result.Transfer.ExchangeRate)
+
+	require.Equal(t, -arg.Amount, result.FromEntry.Amount)
+	require.Equal(t, arg.ToAmount, result.ToEntry.Amount)
+
+	require.Equal(t, account1.Balance-arg.Amount, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+arg.ToAmount, result.ToAccount.Balance)
+
+	// the fx accounts take the conversion, so each currency still nets to zero
+	require.Equal(t, fxUSD.Balance+arg.Amount, getFXAccount(t, "USD").Balance)
+	require.Equal(t, fxEUR.Balance-arg.ToAmount, getFXAccount(t, "EUR").Balance)
+}
+
+func TestTransferTxAccountNotActive(t *testing.T) {
+	store := NewStore(testDb)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	_, err := testQueries.UpdateAccountStatus(context.Background(), UpdateAccountStatusParams{
+		ID:         account2.ID,
+		FromStatus: AccountStatusActive,
+		ToStatus:   AccountStatusFrozen,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	var statusErr *AccountStatusError
+	require.ErrorAs(t, err, &statusErr)
+	require.Equal(t, account2.ID, statusErr.AccountID)
+	require.Equal(t, AccountStatusFrozen, statusErr.Status)
+
+	unchanged, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, unchanged.Balance)
+}
@@ -7,25 +7,107 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const addTransferReversedAmount = `-- name: AddTransferReversedAmount :one
+UPDATE transfers
+SET reversed_amount = reversed_amount + $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id
+`
+
+type AddTransferReversedAmountParams struct {
+	Amount int64 `json:"amount"`
+	ID     int64 `json:"id"`
+}
+
+func (q *Queries) AddTransferReversedAmount(ctx context.Context, arg AddTransferReversedAmountParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, addTransferReversedAmount, arg.Amount, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.BatchID,
+	)
+	return i, err
+}
+
+const countNewPayeesSince = `-- name: CountNewPayeesSince :one
+SELECT COUNT(*) FROM (
+  SELECT to_account_id FROM transfers
+  WHERE from_account_id = $1
+  GROUP BY to_account_id
+  HAVING MIN(created_at) >= $2::timestamptz
+) AS new_payees
+`
+
+type CountNewPayeesSinceParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	Since         time.Time `json:"since"`
+}
+
+func (q *Queries) CountNewPayeesSince(ctx context.Context, arg CountNewPayeesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countNewPayeesSince, arg.FromAccountID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransfersToPayee = `-- name: CountTransfersToPayee :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2
+`
+
+type CountTransfersToPayeeParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+}
+
+func (q *Queries) CountTransfersToPayee(ctx context.Context, arg CountTransfersToPayeeParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersToPayee, arg.FromAccountID, arg.ToAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, to_amount, exchange_rate, reversal_of, batch_id
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7
 )
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ToAmount      int64         `json:"to_amount"`
+	ExchangeRate  string        `json:"exchange_rate"`
+	ReversalOf    sql.NullInt64 `json:"reversal_of"`
+	BatchID       sql.NullInt64 `json:"batch_id"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ToAmount,
+		arg.ExchangeRate,
+		arg.ReversalOf,
+		arg.BatchID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -33,12 +115,17 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.BatchID,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -51,12 +138,116 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.BatchID,
 	)
 	return i, err
 }
 
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ToAmount,
+		&i.ExchangeRate,
+		&i.ReversalOf,
+		&i.ReversedAmount,
+		&i.BatchID,
+	)
+	return i, err
+}
+
+const listTransferAmountsSince = `-- name: ListTransferAmountsSince :many
+SELECT amount FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2::timestamptz
+ORDER BY created_at, id
+`
+
+type ListTransferAmountsSinceParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	Since         time.Time `json:"since"`
+}
+
+func (q *Queries) ListTransferAmountsSince(ctx context.Context, arg ListTransferAmountsSinceParams) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferAmountsSince, arg.FromAccountID, arg.Since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []int64{}
+	for rows.Next() {
+		var amount int64
+		if err := rows.Scan(&amount); err != nil {
+			return nil, err
+		}
+		items = append(items, amount)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransferReversals = `-- name: ListTransferReversals :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE reversal_of = $1
+ORDER BY id
+`
+
+func (q *Queries) ListTransferReversals(ctx context.Context, reversalOf sql.NullInt64) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransferReversals, reversalOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2
 `
@@ -81,6 +272,58 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAfter = `-- name: ListTransfersAfter :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type ListTransfersAfterParams struct {
+	Limit          int32     `json:"limit"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+func (q *Queries) ListTransfersAfter(ctx context.Context, arg ListTransfersAfterParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersAfter, arg.Limit, arg.AfterCreatedAt, arg.AfterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
 		); err != nil {
 			return nil, err
 		}
@@ -96,8 +339,9 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 }
 
 const listTransfersForAccount = `-- name: ListTransfersForAccount :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
 WHERE from_account_id = $3 OR to_account_id = $3
+ORDER BY created_at, id
 LIMIT $1
 OFFSET $2
 `
@@ -123,6 +367,669 @@ func (q *Queries) ListTransfersForAccount(ctx context.Context, arg ListTransfers
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersForAccountAfter = `-- name: ListTransfersForAccountAfter :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE (from_account_id = $2 OR to_account_id = $2)
+  AND (created_at, id) > ($3::timestamptz, $4::bigint)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type ListTransfersForAccountAfterParams struct {
+	Limit          int32     `json:"limit"`
+	AccountID      int64     `json:"account_id"`
+	AfterCreatedAt time.Time `json:"after_created_at"`
+	AfterID        int64     `json:"after_id"`
+}
+
+func (q *Queries) ListTransfersForAccountAfter(ctx context.Context, arg ListTransfersForAccountAfterParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersForAccountAfter,
+		arg.Limit,
+		arg.AccountID,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByAmount = `-- name: SearchTransfersByAmount :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND amount >= COALESCE($4::bigint, 0)
+  AND amount <= COALESCE($5::bigint, 9223372036854775807)
+  AND (amount, created_at, id) > (COALESCE($6::bigint, 0), COALESCE($7::timestamptz, '-infinity'), COALESCE($8::bigint, 0))
+ORDER BY amount, created_at, id
+LIMIT $1
+`
+
+type SearchTransfersByAmountParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchTransfersByAmount(ctx context.Context, arg SearchTransfersByAmountParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByAmount,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByAmountDesc = `-- name: SearchTransfersByAmountDesc :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND amount >= COALESCE($4::bigint, 0)
+  AND amount <= COALESCE($5::bigint, 9223372036854775807)
+  AND (amount, created_at, id) < (COALESCE($6::bigint, 9223372036854775807), COALESCE($7::timestamptz, 'infinity'), COALESCE($8::bigint, 9223372036854775807))
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchTransfersByAmountDescParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	AfterAmount    sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchTransfersByAmountDesc(ctx context.Context, arg SearchTransfersByAmountDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByAmountDesc,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByCreatedAt = `-- name: SearchTransfersByCreatedAt :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND amount >= COALESCE($4::bigint, 0)
+  AND amount <= COALESCE($5::bigint, 9223372036854775807)
+  AND (created_at, id) > (COALESCE($6::timestamptz, '-infinity'), COALESCE($7::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type SearchTransfersByCreatedAtParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchTransfersByCreatedAt(ctx context.Context, arg SearchTransfersByCreatedAtParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByCreatedAt,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersByCreatedAtDesc = `-- name: SearchTransfersByCreatedAtDesc :many
+SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE created_at >= COALESCE($2::timestamptz, '-infinity')
+  AND created_at < COALESCE($3::timestamptz, 'infinity')
+  AND amount >= COALESCE($4::bigint, 0)
+  AND amount <= COALESCE($5::bigint, 9223372036854775807)
+  AND (created_at, id) < (COALESCE($6::timestamptz, 'infinity'), COALESCE($7::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchTransfersByCreatedAtDescParams struct {
+	Limit          int32         `json:"limit"`
+	StartTime      sql.NullTime  `json:"start_time"`
+	EndTime        sql.NullTime  `json:"end_time"`
+	MinAmount      sql.NullInt64 `json:"min_amount"`
+	MaxAmount      sql.NullInt64 `json:"max_amount"`
+	AfterCreatedAt sql.NullTime  `json:"after_created_at"`
+	AfterID        sql.NullInt64 `json:"after_id"`
+}
+
+func (q *Queries) SearchTransfersByCreatedAtDesc(ctx context.Context, arg SearchTransfersByCreatedAtDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersByCreatedAtDesc,
+		arg.Limit,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersForAccountByAmount = `-- name: SearchTransfersForAccountByAmount :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE from_account_id = $2
+  AND to_account_id = COALESCE($3::bigint, to_account_id)
+  AND created_at >= COALESCE($4::timestamptz, '-infinity')
+  AND created_at < COALESCE($5::timestamptz, 'infinity')
+  AND amount >= COALESCE($6::bigint, 0)
+  AND amount <= COALESCE($7::bigint, 9223372036854775807)
+  AND (amount, created_at, id) > (COALESCE($8::bigint, 0), COALESCE($9::timestamptz, '-infinity'), COALESCE($10::bigint, 0))
+ORDER BY amount, created_at, id
+LIMIT $1)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE to_account_id = $11
+  AND from_account_id = COALESCE($3, from_account_id)
+  AND created_at >= COALESCE($4, '-infinity')
+  AND created_at < COALESCE($5, 'infinity')
+  AND amount >= COALESCE($6, 0)
+  AND amount <= COALESCE($7, 9223372036854775807)
+  AND (amount, created_at, id) > (COALESCE($8, 0), COALESCE($9, '-infinity'), COALESCE($10, 0))
+ORDER BY amount, created_at, id
+LIMIT $1)
+ORDER BY amount, created_at, id
+LIMIT $1
+`
+
+type SearchTransfersForAccountByAmountParams struct {
+	Limit             int32         `json:"limit"`
+	OutgoingAccountID int64         `json:"outgoing_account_id"`
+	CounterpartyID    sql.NullInt64 `json:"counterparty_id"`
+	StartTime         sql.NullTime  `json:"start_time"`
+	EndTime           sql.NullTime  `json:"end_time"`
+	MinAmount         sql.NullInt64 `json:"min_amount"`
+	MaxAmount         sql.NullInt64 `json:"max_amount"`
+	AfterAmount       sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt    sql.NullTime  `json:"after_created_at"`
+	AfterID           sql.NullInt64 `json:"after_id"`
+	IncomingAccountID int64         `json:"incoming_account_id"`
+}
+
+// each side of the account is read in order from its own index and the two are merged,
+// an account id of 0 leaves that side out
+func (q *Queries) SearchTransfersForAccountByAmount(ctx context.Context, arg SearchTransfersForAccountByAmountParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersForAccountByAmount,
+		arg.Limit,
+		arg.OutgoingAccountID,
+		arg.CounterpartyID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.IncomingAccountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersForAccountByAmountDesc = `-- name: SearchTransfersForAccountByAmountDesc :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE from_account_id = $2
+  AND to_account_id = COALESCE($3::bigint, to_account_id)
+  AND created_at >= COALESCE($4::timestamptz, '-infinity')
+  AND created_at < COALESCE($5::timestamptz, 'infinity')
+  AND amount >= COALESCE($6::bigint, 0)
+  AND amount <= COALESCE($7::bigint, 9223372036854775807)
+  AND (amount, created_at, id) < (COALESCE($8::bigint, 9223372036854775807), COALESCE($9::timestamptz, 'infinity'), COALESCE($10::bigint, 9223372036854775807))
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE to_account_id = $11
+  AND from_account_id = COALESCE($3, from_account_id)
+  AND created_at >= COALESCE($4, '-infinity')
+  AND created_at < COALESCE($5, 'infinity')
+  AND amount >= COALESCE($6, 0)
+  AND amount <= COALESCE($7, 9223372036854775807)
+  AND (amount, created_at, id) < (COALESCE($8, 9223372036854775807), COALESCE($9, 'infinity'), COALESCE($10, 9223372036854775807))
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1)
+ORDER BY amount DESC, created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchTransfersForAccountByAmountDescParams struct {
+	Limit             int32         `json:"limit"`
+	OutgoingAccountID int64         `json:"outgoing_account_id"`
+	CounterpartyID    sql.NullInt64 `json:"counterparty_id"`
+	StartTime         sql.NullTime  `json:"start_time"`
+	EndTime           sql.NullTime  `json:"end_time"`
+	MinAmount         sql.NullInt64 `json:"min_amount"`
+	MaxAmount         sql.NullInt64 `json:"max_amount"`
+	AfterAmount       sql.NullInt64 `json:"after_amount"`
+	AfterCreatedAt    sql.NullTime  `json:"after_created_at"`
+	AfterID           sql.NullInt64 `json:"after_id"`
+	IncomingAccountID int64         `json:"incoming_account_id"`
+}
+
+// each side of the account is read in order from its own index and the two are merged,
+// an account id of 0 leaves that side out
+func (q *Queries) SearchTransfersForAccountByAmountDesc(ctx context.Context, arg SearchTransfersForAccountByAmountDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersForAccountByAmountDesc,
+		arg.Limit,
+		arg.OutgoingAccountID,
+		arg.CounterpartyID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.IncomingAccountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersForAccountByCreatedAt = `-- name: SearchTransfersForAccountByCreatedAt :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE from_account_id = $2
+  AND to_account_id = COALESCE($3::bigint, to_account_id)
+  AND created_at >= COALESCE($4::timestamptz, '-infinity')
+  AND created_at < COALESCE($5::timestamptz, 'infinity')
+  AND amount >= COALESCE($6::bigint, 0)
+  AND amount <= COALESCE($7::bigint, 9223372036854775807)
+  AND (created_at, id) > (COALESCE($8::timestamptz, '-infinity'), COALESCE($9::bigint, 0))
+ORDER BY created_at, id
+LIMIT $1)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE to_account_id = $10
+  AND from_account_id = COALESCE($3, from_account_id)
+  AND created_at >= COALESCE($4, '-infinity')
+  AND created_at < COALESCE($5, 'infinity')
+  AND amount >= COALESCE($6, 0)
+  AND amount <= COALESCE($7, 9223372036854775807)
+  AND (created_at, id) > (COALESCE($8, '-infinity'), COALESCE($9, 0))
+ORDER BY created_at, id
+LIMIT $1)
+ORDER BY created_at, id
+LIMIT $1
+`
+
+type SearchTransfersForAccountByCreatedAtParams struct {
+	Limit             int32         `json:"limit"`
+	OutgoingAccountID int64         `json:"outgoing_account_id"`
+	CounterpartyID    sql.NullInt64 `json:"counterparty_id"`
+	StartTime         sql.NullTime  `json:"start_time"`
+	EndTime           sql.NullTime  `json:"end_time"`
+	MinAmount         sql.NullInt64 `json:"min_amount"`
+	MaxAmount         sql.NullInt64 `json:"max_amount"`
+	AfterCreatedAt    sql.NullTime  `json:"after_created_at"`
+	AfterID           sql.NullInt64 `json:"after_id"`
+	IncomingAccountID int64         `json:"incoming_account_id"`
+}
+
+// each side of the account is read in order from its own index and the two are merged,
+// an account id of 0 leaves that side out
+func (q *Queries) SearchTransfersForAccountByCreatedAt(ctx context.Context, arg SearchTransfersForAccountByCreatedAtParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersForAccountByCreatedAt,
+		arg.Limit,
+		arg.OutgoingAccountID,
+		arg.CounterpartyID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.IncomingAccountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfersForAccountByCreatedAtDesc = `-- name: SearchTransfersForAccountByCreatedAtDesc :many
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE from_account_id = $2
+  AND to_account_id = COALESCE($3::bigint, to_account_id)
+  AND created_at >= COALESCE($4::timestamptz, '-infinity')
+  AND created_at < COALESCE($5::timestamptz, 'infinity')
+  AND amount >= COALESCE($6::bigint, 0)
+  AND amount <= COALESCE($7::bigint, 9223372036854775807)
+  AND (created_at, id) < (COALESCE($8::timestamptz, 'infinity'), COALESCE($9::bigint, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1)
+UNION ALL
+(SELECT id, from_account_id, to_account_id, amount, created_at, to_amount, exchange_rate, reversal_of, reversed_amount, batch_id FROM transfers
+WHERE to_account_id = $10
+  AND from_account_id = COALESCE($3, from_account_id)
+  AND created_at >= COALESCE($4, '-infinity')
+  AND created_at < COALESCE($5, 'infinity')
+  AND amount >= COALESCE($6, 0)
+  AND amount <= COALESCE($7, 9223372036854775807)
+  AND (created_at, id) < (COALESCE($8, 'infinity'), COALESCE($9, 9223372036854775807))
+ORDER BY created_at DESC, id DESC
+LIMIT $1)
+ORDER BY created_at DESC, id DESC
+LIMIT $1
+`
+
+type SearchTransfersForAccountByCreatedAtDescParams struct {
+	Limit             int32         `json:"limit"`
+	OutgoingAccountID int64         `json:"outgoing_account_id"`
+	CounterpartyID    sql.NullInt64 `json:"counterparty_id"`
+	StartTime         sql.NullTime  `json:"start_time"`
+	EndTime           sql.NullTime  `json:"end_time"`
+	MinAmount         sql.NullInt64 `json:"min_amount"`
+	MaxAmount         sql.NullInt64 `json:"max_amount"`
+	AfterCreatedAt    sql.NullTime  `json:"after_created_at"`
+	AfterID           sql.NullInt64 `json:"after_id"`
+	IncomingAccountID int64         `json:"incoming_account_id"`
+}
+
+// each side of the account is read in order from its own index and the two are merged,
+// an account id of 0 leaves that side out
+func (q *Queries) SearchTransfersForAccountByCreatedAtDesc(ctx context.Context, arg SearchTransfersForAccountByCreatedAtDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfersForAccountByCreatedAtDesc,
+		arg.Limit,
+		arg.OutgoingAccountID,
+		arg.CounterpartyID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.MinAmount,
+		arg.MaxAmount,
+		arg.AfterCreatedAt,
+		arg.AfterID,
+		arg.IncomingAccountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.ToAmount,
+			&i.ExchangeRate,
+			&i.ReversalOf,
+			&i.ReversedAmount,
+			&i.BatchID,
 		); err != nil {
 			return nil, err
 		}
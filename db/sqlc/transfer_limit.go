@@ -0,0 +1,68 @@
+package db
+
+import "context"
+
+// Velocity limits, see TransferLimitError
+const (
+	TransferLimitAmount        = "max_amount"
+	TransferLimitDailyAmount   = "max_daily_amount"
+	TransferLimitMonthlyAmount = "max_monthly_amount"
+	TransferLimitDailyCount    = "max_daily_count"
+)
+
+// checkTransferLimits makes sure a transfer stays within the limits of its source account. Withdrawals and
+// hold captures are transfers out of the account too and are checked the same way. Account limits
+// override the limits of the owner's tier one by one, days and months start at midnight UTC and reversals
+// do not count. Both accounts are locked before the transfers of the day and month are added up, so
+// concurrent transfers from the same account are checked one after the other.
+func checkTransferLimits(ctx context.Context, q *Queries, arg CrossCurrencyTransferTxParams) error {
+	limit, err := q.GetEffectiveTransferLimit(ctx, arg.FromAccountID)
+	if err != nil {
+		return err
+	}
+	if !limit.MaxAmount.Valid && !limit.MaxDailyAmount.Valid && !limit.MaxMonthlyAmount.Valid && !limit.MaxDailyCount.Valid {
+		return nil
+	}
+
+	if limit.MaxAmount.Valid && arg.Amount > limit.MaxAmount.Int64 {
+		return &TransferLimitError{
+			AccountID: arg.FromAccountID,
+			Limit:     TransferLimitAmount,
+			Max:       limit.MaxAmount.Int64,
+			Requested: arg.Amount,
+		}
+	}
+
+	if _, err := lockAccounts(ctx, q, arg.FromAccountID, arg.ToAccountID); err != nil {
+		return err
+	}
+
+	usage, err := q.GetTransferLimitUsage(ctx, arg.FromAccountID)
+	if err != nil {
+		return err
+	}
+
+	checks := []struct {
+		name      string
+		max       int64
+		enabled   bool
+		used      int64
+		requested int64
+	}{
+		{TransferLimitDailyAmount, limit.MaxDailyAmount.Int64, limit.MaxDailyAmount.Valid, usage.DailyAmount, arg.Amount},
+		{TransferLimitMonthlyAmount, limit.MaxMonthlyAmount.Int64, limit.MaxMonthlyAmount.Valid, usage.MonthlyAmount, arg.Amount},
+		{TransferLimitDailyCount, int64(limit.MaxDailyCount.Int32), limit.MaxDailyCount.Valid, usage.DailyCount, 1},
+	}
+	for _, check := range checks {
+		if check.enabled && check.used+check.requested > check.max {
+			return &TransferLimitError{
+				AccountID: arg.FromAccountID,
+				Limit:     check.name,
+				Max:       check.max,
+				Used:      check.used,
+				Requested: check.requested,
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,204 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// setRandomTier moves the owner of the account to a new tier with the given limits for the account currency
+func setRandomTier(t *testing.T, account Account, arg UpsertTierTransferLimitParams) TierTransferLimit {
+	user, err := testQueries.UpdateUserTier(context.Background(), UpdateUserTierParams{
+		Username: account.Owner,
+		Tier:     utils.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	arg.Tier = user.Tier
+	arg.Currency = account.Currency
+	limit, err := testQueries.UpsertTierTransferLimit(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.Tier, limit.Tier)
+	return limit
+}
+
+func TestTransferTxTierLimits(t *testing.T) {
+	store := NewStore(testDb)
+	account, payees := createBatchAccounts(t, 1000, 1)
+	setRandomTier(t, account, UpsertTierTransferLimitParams{
+		MaxAmount:     sql.NullInt64{Int64: 100, Valid: true},
+		MaxDailyCount: sql.NullInt32{Int32: 2, Valid: true},
+	})
+
+	arg := TransferTxParams{FromAccountID: account.ID, ToAccountID: payees[0].ID, Amount: 101}
+	_, err := store.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitAmount, limitErr.Limit)
+	require.Equal(t, int64(100), limitErr.Max)
+
+	arg.Amount = 100
+	for i := 0; i < 2; i++ {
+		_, err = store.TransferTx(context.Background(), arg)
+		require.NoError(t, err)
+	}
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitDailyCount, limitErr.Limit)
+	require.Equal(t, int64(2), limitErr.Used)
+
+	updated, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(800), updated.Balance)
+}
+
+func TestTransferTxAccountLimitsOverrideTier(t *testing.T) {
+	store := NewStore(testDb)
+	account, payees := createBatchAccounts(t, 1000, 1)
+	setRandomTier(t, account, UpsertTierTransferLimitParams{
+		MaxDailyAmount:   sql.NullInt64{Int64: 100, Valid: true},
+		MaxMonthlyAmount: sql.NullInt64{Int64: 400, Valid: true},
+	})
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:      account.ID,
+		MaxDailyAmount: sql.NullInt64{Int64: 500, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the account raises the daily limit, the monthly one still comes from the tier
+	arg := TransferTxParams{FromAccountID: account.ID, ToAccountID: payees[0].ID, Amount: 300}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitMonthlyAmount, limitErr.Limit)
+	require.Equal(t, int64(300), limitErr.Used)
+	require.Equal(t, int64(300), limitErr.Requested)
+
+	require.NoError(t, testQueries.DeleteAccountTransferLimit(context.Background(), account.ID))
+
+	arg.Amount = 50
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitDailyAmount, limitErr.Limit)
+}
+
+func TestTransferTxLimitsConcurrent(t *testing.T) {
+	n := 10
+	store := NewStore(testDb)
+	account, payees := createBatchAccounts(t, 1000, 2)
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:      account.ID,
+		MaxDailyAmount: sql.NullInt64{Int64: 50, Valid: true},
+	})
+	require.NoError(t, err)
+
+	errs := make(chan error)
+	for x := 0; x < n; x++ {
+		payee := payees[x%2]
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account.ID,
+				ToAccountID:   payee.ID,
+				Amount:        10,
+			})
+			errs <- err
+		}()
+	}
+
+	var succeeded int
+	for x := 0; x < n; x++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	}
+	require.Equal(t, 5, succeeded)
+
+	usage, err := testQueries.GetTransferLimitUsage(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(50), usage.DailyAmount)
+	require.Equal(t, int64(5), usage.DailyCount)
+}
+
+func TestBatchTransferTxLimits(t *testing.T) {
+	store := NewStore(testDb)
+	source, payees := createBatchAccounts(t, 1000, 2)
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:      source.ID,
+		MaxDailyAmount: sql.NullInt64{Int64: 150, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		Legs: []BatchTransferLeg{
+			{FromAccountID: source.ID, ToAccountID: payees[0].ID, Amount: 100},
+			{FromAccountID: source.ID, ToAccountID: payees[1].ID, Amount: 100},
+		},
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	require.Contains(t, err.Error(), "leg 1")
+
+	usage, err := testQueries.GetTransferLimitUsage(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Zero(t, usage.DailyCount)
+}
+
+func TestWithdrawTxLimits(t *testing.T) {
+	store := NewStore(testDb)
+	account := fundAccount(t, createRandomAccount(t), 1000)
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:      account.ID,
+		MaxDailyAmount: sql.NullInt64{Int64: 150, Valid: true},
+	})
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 100})
+	require.NoError(t, err)
+
+	_, err = store.WithdrawTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 100})
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, TransferLimitDailyAmount, limitErr.Limit)
+	require.Equal(t, int64(100), limitErr.Used)
+
+	// deposits are not limited
+	_, err = store.DepositTx(context.Background(), CashTxParams{AccountID: account.ID, Amount: 500})
+	require.NoError(t, err)
+}
+
+func TestCaptureHoldLimits(t *testing.T) {
+	store := NewStore(testDb)
+	account, merchant := createHoldAccounts(t, 1000)
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID: account.ID,
+		MaxAmount: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	hold := placeRandomHold(t, store, account, merchant, 300, time.Now().Add(time.Hour))
+
+	_, err = store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.Hold.ID})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// the failed capture leaves the hold in place
+	got, err := testQueries.GetHold(context.Background(), hold.Hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusActive, got.Status)
+
+	captured, err := store.CaptureHold(context.Background(), CaptureHoldParams{HoldID: hold.Hold.ID, Amount: 100})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), captured.Hold.CapturedAmount)
+}
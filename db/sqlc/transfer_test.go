@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -13,10 +14,13 @@ func createRandomTransfer(t *testing.T) Transfer {
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
 
+	amount := utils.RandomMoney()
 	arg := CreateTransferParams{
 		FromAccountID: account1.ID,
 		ToAccountID:   account2.ID,
-		Amount:        utils.RandomMoney(),
+		Amount:        amount,
+		ToAmount:      amount,
+		ExchangeRate:  "1",
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -26,6 +30,8 @@ func createRandomTransfer(t *testing.T) Transfer {
 	require.Equal(t, account1.ID, transfer.FromAccountID)
 	require.Equal(t, account2.ID, transfer.ToAccountID)
 	require.Equal(t, arg.Amount, transfer.Amount)
+	require.Equal(t, arg.ToAmount, transfer.ToAmount)
+	require.Equal(t, "1.0000000000", transfer.ExchangeRate)
 	require.NotZero(t, transfer.ID)
 	require.NotZero(t, transfer.CreatedAt)
 
@@ -77,6 +83,8 @@ func TestListTransfersForAccount(t *testing.T) {
 	for x := 0; x < 10; x++ {
 		var transfer CreateTransferParams
 		transfer.Amount = utils.RandomMoney()
+		transfer.ToAmount = transfer.Amount
+		transfer.ExchangeRate = "1"
 		if x%2 == 0 {
 			transfer.FromAccountID = account1.ID
 			transfer.ToAccountID = account2.ID
@@ -105,3 +113,165 @@ func TestListTransfersForAccount(t *testing.T) {
 	}
 
 }
+
+func TestListTransfersForAccountAfter(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	var created []Transfer
+	for x := 0; x < 5; x++ {
+		transfer, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        int64(x + 1),
+			ToAmount:      int64(x + 1),
+			ExchangeRate:  "1",
+		})
+		require.NoError(t, err)
+		created = append(created, transfer)
+	}
+
+	var listed []Transfer
+	arg := ListTransfersForAccountAfterParams{Limit: 2, AccountID: account2.ID}
+	for {
+		transfers, err := testQueries.ListTransfersForAccountAfter(context.Background(), arg)
+		require.NoError(t, err)
+		listed = append(listed, transfers...)
+		if len(transfers) < int(arg.Limit) {
+			break
+		}
+
+		last := transfers[len(transfers)-1]
+		arg.AfterCreatedAt = last.CreatedAt
+		arg.AfterID = last.ID
+	}
+
+	require.Len(t, listed, len(created))
+	for i := range created {
+		require.Equal(t, created[i].ID, listed[i].ID)
+	}
+}
+
+func TestSearchTransfers(t *testing.T) {
+	store := NewStore(testDb)
+	account, payees := createBatchAccounts(t, 1000, 2)
+
+	for _, leg := range []BatchTransferLeg{
+		{ToAccountID: payees[0].ID, Amount: 100},
+		{ToAccountID: payees[0].ID, Amount: 300},
+		{ToAccountID: payees[1].ID, Amount: 200},
+	} {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account.ID,
+			ToAccountID:   leg.ToAccountID,
+			Amount:        leg.Amount,
+		})
+		require.NoError(t, err)
+	}
+
+	transfers, err := store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Limit:         10,
+		FromAccountID: sql.NullInt64{Int64: account.ID, Valid: true},
+		ToAccountID:   sql.NullInt64{Int64: payees[0].ID, Valid: true},
+		MinAmount:     sql.NullInt64{Int64: 200, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, int64(300), transfers[0].Amount)
+
+	transfers, err = store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Limit:          10,
+		AccountID:      sql.NullInt64{Int64: payees[0].ID, Valid: true},
+		CounterpartyID: sql.NullInt64{Int64: account.ID, Valid: true},
+		EndTime:        sql.NullTime{Time: time.Now().Add(-time.Hour), Valid: true},
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+
+	// the payee only has incoming transfers, newest first
+	transfers, err = store.SearchTransfers(context.Background(), SearchTransfersParams{
+		Limit:      10,
+		AccountID:  sql.NullInt64{Int64: payees[0].ID, Valid: true},
+		Descending: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, int64(300), transfers[0].Amount)
+	require.Equal(t, int64(100), transfers[1].Amount)
+
+	arg := SearchTransfersParams{
+		Limit:      2,
+		AccountID:  sql.NullInt64{Int64: account.ID, Valid: true},
+		SortBy:     "amount",
+		Descending: true,
+	}
+	transfers, err = store.SearchTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 2)
+	require.Equal(t, int64(300), transfers[0].Amount)
+	require.Equal(t, int64(200), transfers[1].Amount)
+
+	last := transfers[1]
+	arg.AfterID = sql.NullInt64{Int64: last.ID, Valid: true}
+	arg.AfterAmount = sql.NullInt64{Int64: last.Amount, Valid: true}
+	arg.AfterCreatedAt = sql.NullTime{Time: last.CreatedAt, Valid: true}
+	transfers, err = store.SearchTransfers(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, transfers, 1)
+	require.Equal(t, int64(100), transfers[0].Amount)
+}
+
+func TestTransferHistory(t *testing.T) {
+	source, payees := createBatchAccounts(t, 0, 3)
+	since := time.Now().Add(-time.Minute)
+
+	for _, transfer := range []struct {
+		to     Account
+		amount int64
+	}{{payees[0], 1000}, {payees[0], 1234}, {payees[1], 2000}} {
+		_, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+			FromAccountID: source.ID,
+			ToAccountID:   transfer.to.ID,
+			Amount:        transfer.amount,
+			ToAmount:      transfer.amount,
+			ExchangeRate:  "1",
+		})
+		require.NoError(t, err)
+	}
+
+	count, err := testQueries.CountTransfersToPayee(context.Background(), CountTransfersToPayeeParams{
+		FromAccountID: source.ID,
+		ToAccountID:   payees[0].ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	count, err = testQueries.CountTransfersToPayee(context.Background(), CountTransfersToPayeeParams{
+		FromAccountID: source.ID,
+		ToAccountID:   payees[2].ID,
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	count, err = testQueries.CountNewPayeesSince(context.Background(), CountNewPayeesSinceParams{
+		FromAccountID: source.ID,
+		Since:         since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	// payees first paid before the window are not new
+	count, err = testQueries.CountNewPayeesSince(context.Background(), CountNewPayeesSinceParams{
+		FromAccountID: source.ID,
+		Since:         time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	amounts, err := testQueries.ListTransferAmountsSince(context.Background(), ListTransferAmountsSinceParams{
+		FromAccountID: source.ID,
+		Since:         since,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1000, 1234, 2000}, amounts)
+}
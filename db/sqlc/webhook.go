@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusDelivered = "delivered"
+	WebhookDeliveryStatusDead      = "dead"
+)
+
+type ClaimWebhookDeliveriesTxParams struct {
+	Now   time.Time
+	Limit int32
+	// Lease is how long the claimed deliveries are hidden from other workers. It has to outlast sending
+	// all of them, a delivery whose lease ran out may be claimed and sent again.
+	Lease time.Duration
+}
+
+// ClaimWebhookDeliveriesTx locks the webhook deliveries that are due, skipping the ones another worker is
+// claiming, and moves their next attempt to the end of the lease. The transaction ends before anything is
+// sent; the outcome of each attempt is recorded with UpdateWebhookDelivery, which ignores a delivery whose
+// attempts have moved on since the claim.
+func (store *SQLStore) ClaimWebhookDeliveriesTx(ctx context.Context, arg ClaimWebhookDeliveriesTxParams) ([]ListDueWebhookDeliveriesRow, error) {
+	var due []ListDueWebhookDeliveriesRow
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		due, err = q.ListDueWebhookDeliveries(ctx, ListDueWebhookDeliveriesParams{
+			NextAttemptAt: arg.Now,
+			Limit:         arg.Limit,
+		})
+		if err != nil || len(due) == 0 {
+			return err
+		}
+
+		ids := make([]int64, len(due))
+		for i, delivery := range due {
+			ids[i] = delivery.ID
+		}
+		return q.LeaseWebhookDeliveries(ctx, LeaseWebhookDeliveriesParams{
+			LeasedUntil: arg.Now.Add(arg.Lease),
+			Ids:         ids,
+		})
+	})
+
+	return due, err
+}
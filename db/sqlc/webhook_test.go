@@ -0,0 +1,236 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhookSubscription(t *testing.T, owner string, eventTypes ...string) WebhookSubscription {
+	subscription, err := testQueries.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/hooks",
+		EventTypes: eventTypes,
+		Secret:     "0123456789abcdef",
+	})
+	require.NoError(t, err)
+	require.True(t, subscription.Active)
+	require.Equal(t, eventTypes, subscription.EventTypes)
+	return subscription
+}
+
+// deliverWebhooks claims the due deliveries once and records the outcome deliver returns for the ones of
+// subscription. The claim takes no lease, so the others stay due.
+func deliverWebhooks(t *testing.T, store Store, subscription WebhookSubscription, deliver func(ListDueWebhookDeliveriesRow) UpdateWebhookDeliveryParams) []ListDueWebhookDeliveriesRow {
+	due, err := store.ClaimWebhookDeliveriesTx(context.Background(), ClaimWebhookDeliveriesTxParams{
+		Now:   time.Now(),
+		Limit: 1000,
+	})
+	require.NoError(t, err)
+
+	var seen []ListDueWebhookDeliveriesRow
+	for _, delivery := range due {
+		if delivery.SubscriptionID != subscription.ID {
+			continue
+		}
+		seen = append(seen, delivery)
+
+		next := deliver(delivery)
+		next.ID = delivery.ID
+		next.ClaimedAttempts = delivery.Attempts
+		_, err := store.UpdateWebhookDelivery(context.Background(), next)
+		require.NoError(t, err)
+	}
+	return seen
+}
+
+// claimWebhooks claims the due deliveries at now for a minute and returns the ones of subscription
+func claimWebhooks(t *testing.T, store Store, subscription WebhookSubscription, now time.Time) []ListDueWebhookDeliveriesRow {
+	due, err := store.ClaimWebhookDeliveriesTx(context.Background(), ClaimWebhookDeliveriesTxParams{
+		Now:   now,
+		Limit: 1000,
+		Lease: time.Minute,
+	})
+	require.NoError(t, err)
+
+	var claimed []ListDueWebhookDeliveriesRow
+	for _, delivery := range due {
+		if delivery.SubscriptionID == subscription.ID {
+			claimed = append(claimed, delivery)
+		}
+	}
+	return claimed
+}
+
+func TestTransferTxWebhookDeliveries(t *testing.T) {
+	store := NewStore(testDb)
+	account1 := fundAccount(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	subscription := createRandomWebhookSubscription(t, account1.Owner, EventTransferCompleted)
+	other := createRandomWebhookSubscription(t, account2.Owner, EventAccountCreated)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// only the event type the subscription asked for is delivered, and only to the owner of the account
+	due := deliverWebhooks(t, store, subscription, func(delivery ListDueWebhookDeliveriesRow) UpdateWebhookDeliveryParams {
+		return UpdateWebhookDeliveryParams{
+			Status:         WebhookDeliveryStatusDelivered,
+			Attempts:       delivery.Attempts + 1,
+			NextAttemptAt:  time.Now(),
+			LastStatusCode: sql.NullInt32{Int32: 200, Valid: true},
+			DeliveredAt:    sql.NullTime{Time: time.Now(), Valid: true},
+		}
+	})
+	require.Len(t, due, 1)
+	require.Equal(t, EventTransferCompleted, due[0].EventType)
+	require.Equal(t, account1.ID, due[0].AccountID)
+	require.Equal(t, subscription.Url, due[0].Url)
+	require.Equal(t, subscription.Secret, due[0].Secret)
+
+	var transfer Transfer
+	require.NoError(t, json.Unmarshal(due[0].Payload, &transfer))
+	require.Equal(t, result.Transfer.ID, transfer.ID)
+
+	deliveries, err := store.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: subscription.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, WebhookDeliveryStatusDelivered, deliveries[0].Status)
+	require.Equal(t, int32(1), deliveries[0].Attempts)
+	require.True(t, deliveries[0].DeliveredAt.Valid)
+
+	deliveries, err = store.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		SubscriptionID: other.ID,
+		Limit:          10,
+	})
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+
+	// delivered webhooks are not sent again
+	require.Empty(t, deliverWebhooks(t, store, subscription, nil))
+}
+
+func TestRetryWebhookDelivery(t *testing.T) {
+	store := NewStore(testDb)
+	user := createRandomUser(t)
+	subscription := createRandomWebhookSubscription(t, user.Username, EventAccountCreated)
+
+	_, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	due := deliverWebhooks(t, store, subscription, func(delivery ListDueWebhookDeliveriesRow) UpdateWebhookDeliveryParams {
+		return UpdateWebhookDeliveryParams{
+			Status:         WebhookDeliveryStatusDead,
+			Attempts:       delivery.Attempts + 1,
+			NextAttemptAt:  time.Now(),
+			LastStatusCode: sql.NullInt32{Int32: 500, Valid: true},
+			LastError:      "unexpected status 500 Internal Server Error",
+		}
+	})
+	require.Len(t, due, 1)
+
+	// dead deliveries are left alone until they are retried
+	require.Empty(t, deliverWebhooks(t, store, subscription, nil))
+
+	delivery, err := store.RetryWebhookDelivery(context.Background(), due[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryStatusPending, delivery.Status)
+	require.Zero(t, delivery.Attempts)
+	require.Equal(t, "unexpected status 500 Internal Server Error", delivery.LastError)
+
+	_, err = store.RetryWebhookDelivery(context.Background(), due[0].ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	retried := deliverWebhooks(t, store, subscription, func(delivery ListDueWebhookDeliveriesRow) UpdateWebhookDeliveryParams {
+		return UpdateWebhookDeliveryParams{Status: WebhookDeliveryStatusDelivered, Attempts: 1, NextAttemptAt: time.Now()}
+	})
+	require.Len(t, retried, 1)
+	require.Equal(t, due[0].ID, retried[0].ID)
+
+	// deleting the subscription drops its deliveries
+	require.NoError(t, store.DeleteWebhookSubscription(context.Background(), subscription.ID))
+	_, err = store.GetWebhookDelivery(context.Background(), due[0].ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestClaimWebhookDeliveriesTxLease(t *testing.T) {
+	store := NewStore(testDb)
+	user := createRandomUser(t)
+	subscription := createRandomWebhookSubscription(t, user.Username, EventAccountCreated)
+
+	_, err := store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	claimed := claimWebhooks(t, store, subscription, now)
+	require.Len(t, claimed, 1)
+
+	delivery, err := store.GetWebhookDelivery(context.Background(), claimed[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryStatusPending, delivery.Status)
+	require.WithinDuration(t, now.Add(time.Minute), delivery.NextAttemptAt, time.Second)
+
+	// a leased delivery is not claimed again until its lease runs out
+	require.Empty(t, claimWebhooks(t, store, subscription, now))
+	reclaimed := claimWebhooks(t, store, subscription, now.Add(2*time.Minute))
+	require.Len(t, reclaimed, 1)
+	require.Equal(t, claimed[0].ID, reclaimed[0].ID)
+
+	// the first claim to record its attempt wins, the other one finds the attempts moved on
+	update := UpdateWebhookDeliveryParams{
+		ID:              claimed[0].ID,
+		Status:          WebhookDeliveryStatusDelivered,
+		Attempts:        claimed[0].Attempts + 1,
+		NextAttemptAt:   now,
+		DeliveredAt:     sql.NullTime{Time: now, Valid: true},
+		ClaimedAttempts: claimed[0].Attempts,
+	}
+	delivery, err = store.UpdateWebhookDelivery(context.Background(), update)
+	require.NoError(t, err)
+	require.Equal(t, WebhookDeliveryStatusDelivered, delivery.Status)
+	require.Equal(t, int32(1), delivery.Attempts)
+
+	_, err = store.UpdateWebhookDelivery(context.Background(), update)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestInactiveWebhookSubscription(t *testing.T) {
+	store := NewStore(testDb)
+	user := createRandomUser(t)
+	subscription := createRandomWebhookSubscription(t, user.Username, EventAccountCreated)
+
+	subscription, err := store.UpdateWebhookSubscription(context.Background(), UpdateWebhookSubscriptionParams{
+		ID:         subscription.ID,
+		Url:        subscription.Url,
+		EventTypes: subscription.EventTypes,
+		Secret:     subscription.Secret,
+		Active:     false,
+	})
+	require.NoError(t, err)
+	require.False(t, subscription.Active)
+
+	_, err = store.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+
+	require.Empty(t, deliverWebhooks(t, store, subscription, nil))
+}
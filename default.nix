@@ -1,5 +1,5 @@
 { pkgs ? import <nixpkgs> {} }:
   pkgs.mkShell {
     # nativeBuildInputs is usually what you want -- tools you need to run
-    nativeBuildInputs = with pkgs; [go-migrate gnumake sqlc mockgen];
+    nativeBuildInputs = with pkgs; [go-migrate gnumake sqlc mockgen protobuf protoc-gen-go protoc-gen-go-grpc];
 }
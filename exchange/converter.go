@@ -0,0 +1,75 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+// ErrCannotConvert is matched by the errors of a transfer that cannot be converted as requested, as opposed
+// to a failure to look up the rate
+var ErrCannotConvert = errors.New("transfer cannot be converted")
+
+type conversionError struct {
+	message string
+}
+
+func (err *conversionError) Error() string {
+	return err.message
+}
+
+func (err *conversionError) Is(target error) bool {
+	return target == ErrCannotConvert
+}
+
+func cannotConvert(format string, args ...interface{}) error {
+	return &conversionError{message: fmt.Sprintf(format, args...)}
+}
+
+// Converter works out transfers between accounts that hold different currencies
+type Converter struct {
+	currencies *CurrencyRegistry
+	rates      RateProvider
+}
+
+func NewConverter(currencies *CurrencyRegistry, rates RateProvider) *Converter {
+	return &Converter{currencies: currencies, rates: rates}
+}
+
+// Transfer looks up the rate from fromCurrency to the currency of toAccount and works out the amount
+// credited to it. Errors matching ErrCannotConvert describe a transfer the request itself rules out.
+func (converter *Converter) Transfer(ctx context.Context, fromAccountID int64, toAccount db.Account, amount int64, fromCurrency db.Currency) (db.CrossCurrencyTransferTxParams, error) {
+	var arg db.CrossCurrencyTransferTxParams
+
+	toCurrency, enabled := converter.currencies.Enabled(ctx, toAccount.Currency)
+	if !enabled {
+		return arg, cannotConvert("currency %s of account [%d] is not enabled", toAccount.Currency, toAccount.ID)
+	}
+
+	rate, err := converter.rates.Rate(ctx, fromCurrency.Code, toCurrency.Code)
+	if err != nil {
+		if errors.Is(err, ErrRateNotFound) {
+			return arg, cannotConvert("no exchange rate from %s to %s", fromCurrency.Code, toCurrency.Code)
+		}
+		return arg, err
+	}
+
+	toAmount, err := Convert(amount, rate, fromCurrency.MinorUnits, toCurrency.MinorUnits)
+	if err != nil {
+		return arg, err
+	}
+	if toAmount <= 0 {
+		return arg, cannotConvert("amount is too small to convert from %s to %s", fromCurrency.Code, toCurrency.Code)
+	}
+
+	arg = db.CrossCurrencyTransferTxParams{
+		FromAccountID: fromAccountID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		ToAmount:      toAmount,
+		ExchangeRate:  rate,
+	}
+	return arg, nil
+}
@@ -0,0 +1,119 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRates answers rate lookups from a map keyed by rateKey, or fails every lookup with err
+type stubRates struct {
+	rates map[string]string
+	err   error
+}
+
+func (stub stubRates) Rate(ctx context.Context, base, quote string) (string, error) {
+	if stub.err != nil {
+		return "", stub.err
+	}
+	if rate, ok := stub.rates[rateKey(base, quote)]; ok {
+		return rate, nil
+	}
+	return "", ErrRateNotFound
+}
+
+func TestConverterTransfer(t *testing.T) {
+	usd := db.Currency{Code: "USD", MinorUnits: 2, Enabled: true}
+	currencies := []db.Currency{
+		usd,
+		{Code: "EUR", MinorUnits: 2, Enabled: true},
+		{Code: "JPY", MinorUnits: 0, Enabled: true},
+		{Code: "CAD", MinorUnits: 2, Enabled: false},
+	}
+
+	testCases := []struct {
+		name          string
+		toCurrency    string
+		amount        int64
+		rates         stubRates
+		checkResponse func(t *testing.T, arg db.CrossCurrencyTransferTxParams, err error)
+	}{
+		{
+			name:       "OK",
+			toCurrency: "EUR",
+			amount:     1000,
+			rates:      stubRates{rates: map[string]string{rateKey("USD", "EUR"): "0.92"}},
+			checkResponse: func(t *testing.T, arg db.CrossCurrencyTransferTxParams, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.CrossCurrencyTransferTxParams{
+					FromAccountID: 1,
+					ToAccountID:   2,
+					Amount:        1000,
+					ToAmount:      920,
+					ExchangeRate:  "0.92",
+				}, arg)
+			},
+		},
+		{
+			name:       "NotEnabled",
+			toCurrency: "CAD",
+			amount:     1000,
+			rates:      stubRates{rates: map[string]string{rateKey("USD", "CAD"): "1.25"}},
+			checkResponse: func(t *testing.T, arg db.CrossCurrencyTransferTxParams, err error) {
+				require.ErrorIs(t, err, ErrCannotConvert)
+				require.EqualError(t, err, "currency CAD of account [2] is not enabled")
+			},
+		},
+		{
+			name:       "NoRate",
+			toCurrency: "EUR",
+			amount:     1000,
+			rates:      stubRates{},
+			checkResponse: func(t *testing.T, arg db.CrossCurrencyTransferTxParams, err error) {
+				require.ErrorIs(t, err, ErrCannotConvert)
+				require.EqualError(t, err, "no exchange rate from USD to EUR")
+			},
+		},
+		{
+			name:       "TooSmall",
+			toCurrency: "JPY",
+			amount:     1,
+			rates:      stubRates{rates: map[string]string{rateKey("USD", "JPY"): "0.5"}},
+			checkResponse: func(t *testing.T, arg db.CrossCurrencyTransferTxParams, err error) {
+				require.ErrorIs(t, err, ErrCannotConvert)
+			},
+		},
+		{
+			name:       "RateError",
+			toCurrency: "EUR",
+			amount:     1000,
+			rates:      stubRates{err: errors.New("connection refused")},
+			checkResponse: func(t *testing.T, arg db.CrossCurrencyTransferTxParams, err error) {
+				require.Error(t, err)
+				require.False(t, errors.Is(err, ErrCannotConvert))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return(currencies, nil)
+
+			converter := NewConverter(NewCurrencyRegistry(store), tc.rates)
+			toAccount := db.Account{ID: 2, Currency: tc.toCurrency}
+
+			arg, err := converter.Transfer(context.Background(), 1, toAccount, tc.amount, usd)
+			tc.checkResponse(t, arg, err)
+		})
+	}
+}
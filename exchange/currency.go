@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+const currencyCacheDuration = time.Minute
+
+// CurrencyRegistry caches the currencies table so request validation does not hit the database every time.
+// Changes made through the same registry are visible immediately, other registries pick them up once the
+// cache expires.
+type CurrencyRegistry struct {
+	querier    db.Querier
+	mu         sync.RWMutex
+	currencies map[string]db.Currency
+	loadedAt   time.Time
+}
+
+func NewCurrencyRegistry(querier db.Querier) *CurrencyRegistry {
+	return &CurrencyRegistry{querier: querier}
+}
+
+// Enabled returns the currency with the given code if it exists and is enabled
+func (registry *CurrencyRegistry) Enabled(ctx context.Context, code string) (db.Currency, bool) {
+	registry.refresh(ctx)
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	currency, ok := registry.currencies[code]
+	return currency, ok && currency.Enabled
+}
+
+// refresh reloads the currencies once the cache has expired. If the reload fails the stale entries are kept.
+func (registry *CurrencyRegistry) refresh(ctx context.Context) {
+	registry.mu.RLock()
+	fresh := time.Since(registry.loadedAt) < currencyCacheDuration
+	registry.mu.RUnlock()
+	if fresh {
+		return
+	}
+
+	currencies, err := registry.querier.ListCurrencies(ctx)
+	if err != nil {
+		log.Printf("cannot load currencies: %v", err)
+		return
+	}
+
+	loaded := make(map[string]db.Currency, len(currencies))
+	for _, currency := range currencies {
+		loaded[currency.Code] = currency
+	}
+
+	registry.mu.Lock()
+	registry.currencies = loaded
+	registry.loadedAt = time.Now()
+	registry.mu.Unlock()
+}
+
+// Invalidate forces the next lookup to reload the currencies
+func (registry *CurrencyRegistry) Invalidate() {
+	registry.mu.Lock()
+	registry.loadedAt = time.Time{}
+	registry.mu.Unlock()
+}
@@ -0,0 +1,44 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+			{Code: "USD", MinorUnits: 2, Enabled: true},
+			{Code: "CAD", MinorUnits: 2, Enabled: false},
+		}, nil),
+		store.EXPECT().ListCurrencies(gomock.Any()).Times(1).Return([]db.Currency{
+			{Code: "USD", MinorUnits: 2, Enabled: true},
+			{Code: "CAD", MinorUnits: 2, Enabled: true},
+		}, nil),
+	)
+
+	registry := NewCurrencyRegistry(store)
+
+	currency, enabled := registry.Enabled(context.Background(), "USD")
+	require.True(t, enabled)
+	require.Equal(t, int32(2), currency.MinorUnits)
+
+	// served from the cache
+	_, enabled = registry.Enabled(context.Background(), "CAD")
+	require.False(t, enabled)
+	_, enabled = registry.Enabled(context.Background(), "JPY")
+	require.False(t, enabled)
+
+	registry.Invalidate()
+	_, enabled = registry.Enabled(context.Background(), "CAD")
+	require.True(t, enabled)
+}
@@ -0,0 +1,36 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+// DBRateProvider reads rates from the exchange_rates table
+type DBRateProvider struct {
+	querier db.Querier
+}
+
+func NewDBRateProvider(querier db.Querier) RateProvider {
+	return &DBRateProvider{querier: querier}
+}
+
+func (provider *DBRateProvider) Rate(ctx context.Context, base, quote string) (string, error) {
+	if base == quote {
+		return "1", nil
+	}
+
+	rate, err := provider.querier.GetExchangeRate(ctx, db.GetExchangeRateParams{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrRateNotFound
+		}
+		return "", err
+	}
+
+	return rate.Rate, nil
+}
@@ -0,0 +1,40 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBRateProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{
+		BaseCurrency:  "USD",
+		QuoteCurrency: "EUR",
+	})).Times(1).Return(db.ExchangeRate{BaseCurrency: "USD", QuoteCurrency: "EUR", Rate: "0.9200000000"}, nil)
+	store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Eq(db.GetExchangeRateParams{
+		BaseCurrency:  "EUR",
+		QuoteCurrency: "CAD",
+	})).Times(1).Return(db.ExchangeRate{}, sql.ErrNoRows)
+
+	provider := NewDBRateProvider(store)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.9200000000", rate)
+
+	rate, err = provider.Rate(context.Background(), "USD", "USD")
+	require.NoError(t, err)
+	require.Equal(t, "1", rate)
+
+	_, err = provider.Rate(context.Background(), "EUR", "CAD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
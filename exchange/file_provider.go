@@ -0,0 +1,58 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+type fileRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	Rate          string `json:"rate"`
+}
+
+// FileRateProvider serves rates loaded once from a JSON file, so transfers keep working without a rate feed.
+// The file holds a list of {"base_currency", "quote_currency", "rate"} objects.
+type FileRateProvider struct {
+	rates map[string]string
+}
+
+func NewFileRateProvider(path string) (RateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read exchange rates: %w", err)
+	}
+
+	var entries []fileRate
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot parse exchange rates: %w", err)
+	}
+
+	provider := &FileRateProvider{rates: make(map[string]string, len(entries))}
+	for _, entry := range entries {
+		if _, err := parseRate(entry.Rate); err != nil {
+			return nil, fmt.Errorf("%s/%s: %w", entry.BaseCurrency, entry.QuoteCurrency, err)
+		}
+		provider.rates[rateKey(entry.BaseCurrency, entry.QuoteCurrency)] = entry.Rate
+	}
+
+	return provider, nil
+}
+
+func (provider *FileRateProvider) Rate(ctx context.Context, base, quote string) (string, error) {
+	if base == quote {
+		return "1", nil
+	}
+
+	rate, ok := provider.rates[rateKey(base, quote)]
+	if !ok {
+		return "", ErrRateNotFound
+	}
+	return rate, nil
+}
+
+func rateKey(base, quote string) string {
+	return base + "/" + quote
+}
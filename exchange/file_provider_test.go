@@ -0,0 +1,48 @@
+package exchange
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeRatesFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestFileRateProvider(t *testing.T) {
+	path := writeRatesFile(t, `[{"base_currency": "USD", "quote_currency": "EUR", "rate": "0.92"}]`)
+
+	provider, err := NewFileRateProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "EUR")
+	require.NoError(t, err)
+	require.Equal(t, "0.92", rate)
+
+	rate, err = provider.Rate(context.Background(), "CAD", "CAD")
+	require.NoError(t, err)
+	require.Equal(t, "1", rate)
+
+	_, err = provider.Rate(context.Background(), "EUR", "USD")
+	require.ErrorIs(t, err, ErrRateNotFound)
+}
+
+func TestFileRateProviderInvalidRate(t *testing.T) {
+	path := writeRatesFile(t, `[{"base_currency": "USD", "quote_currency": "EUR", "rate": "-1"}]`)
+
+	provider, err := NewFileRateProvider(path)
+	require.ErrorIs(t, err, ErrInvalidRate)
+	require.Nil(t, provider)
+}
+
+func TestFileRateProviderMissingFile(t *testing.T) {
+	provider, err := NewFileRateProvider(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+	require.Nil(t, provider)
+}
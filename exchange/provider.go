@@ -0,0 +1,50 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	ErrRateNotFound = errors.New("exchange rate not found")
+	ErrInvalidRate  = errors.New("exchange rate must be a positive decimal")
+)
+
+// RateProvider looks up the rate used to convert money between two currencies
+type RateProvider interface {
+	// Rate returns the units of quote currency bought by one unit of base currency, as a decimal string
+	Rate(ctx context.Context, base, quote string) (string, error)
+}
+
+// Convert applies rate to an amount in minor units of the base currency and returns minor units of the
+// quote currency. The minor units are the ISO 4217 exponents of the two currencies. The result is rounded
+// down, so a conversion never credits more than the exact converted value.
+func Convert(amount int64, rate string, baseMinorUnits, quoteMinorUnits int32) (int64, error) {
+	r, err := parseRate(rate)
+	if err != nil {
+		return 0, err
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(amount), r)
+	converted.Mul(converted, scale(quoteMinorUnits))
+	converted.Quo(converted, scale(baseMinorUnits))
+	result := new(big.Int).Quo(converted.Num(), converted.Denom())
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("converted amount overflows: %s", result)
+	}
+	return result.Int64(), nil
+}
+
+func parseRate(rate string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(rate)
+	if !ok || r.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return r, nil
+}
+
+func scale(minorUnits int32) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(minorUnits)), nil))
+}
@@ -0,0 +1,42 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name       string
+		amount     int64
+		rate       string
+		baseUnits  int32
+		quoteUnits int32
+		expected   int64
+		err        error
+	}{
+		{name: "SameCurrency", amount: 1000, rate: "1", baseUnits: 2, quoteUnits: 2, expected: 1000},
+		{name: "Decimal", amount: 1000, rate: "0.9215", baseUnits: 2, quoteUnits: 2, expected: 921},
+		{name: "RoundsDown", amount: 3, rate: "0.3333333333", baseUnits: 2, quoteUnits: 2, expected: 0},
+		{name: "Fraction", amount: 300, rate: "1/3", baseUnits: 2, quoteUnits: 2, expected: 100},
+		{name: "FewerMinorUnits", amount: 1050, rate: "150", baseUnits: 2, quoteUnits: 0, expected: 1575},
+		{name: "MoreMinorUnits", amount: 1575, rate: "0.0066", baseUnits: 0, quoteUnits: 3, expected: 10395},
+		{name: "Zero", amount: 1000, rate: "0", baseUnits: 2, quoteUnits: 2, err: ErrInvalidRate},
+		{name: "Negative", amount: 1000, rate: "-1.5", baseUnits: 2, quoteUnits: 2, err: ErrInvalidRate},
+		{name: "NotANumber", amount: 1000, rate: "abc", baseUnits: 2, quoteUnits: 2, err: ErrInvalidRate},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			converted, err := Convert(tc.amount, tc.rate, tc.baseUnits, tc.quoteUnits)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, converted)
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package fraud
+
+import (
+	"context"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+// Actions a rule can ask for, from the least to the most severe
+const (
+	ActionAllow  = "allow"
+	ActionReview = "review"
+	ActionBlock  = "block"
+)
+
+var severity = map[string]int{
+	ActionAllow:  0,
+	ActionReview: 1,
+	ActionBlock:  2,
+}
+
+// Transfer is what the rules get to see of a transfer that is about to be made. Amount is in minor units of
+// Currency, the currency of the source account.
+type Transfer struct {
+	FromAccountID int64
+	ToAccountID   int64
+	Amount        int64
+	Currency      string
+	// At is when the transfer is made, rules look back from it
+	At time.Time
+}
+
+// Reason records a rule that matched a transfer
+type Reason struct {
+	Rule    string `json:"rule"`
+	Action  string `json:"action"`
+	Message string `json:"message"`
+}
+
+// Decision is the outcome of screening a transfer: the most severe action of the rules that matched, or
+// allow when none did
+type Decision struct {
+	Action  string   `json:"action"`
+	Reasons []Reason `json:"reasons"`
+}
+
+// History is the part of the store rules read the past transfers of an account from
+type History interface {
+	CountTransfersToPayee(ctx context.Context, arg db.CountTransfersToPayeeParams) (int64, error)
+	CountNewPayeesSince(ctx context.Context, arg db.CountNewPayeesSinceParams) (int64, error)
+	ListTransferAmountsSince(ctx context.Context, arg db.ListTransferAmountsSinceParams) ([]int64, error)
+}
+
+// Rule inspects a transfer. It returns nil when the transfer does not match.
+type Rule interface {
+	Evaluate(ctx context.Context, history History, transfer Transfer) (*Reason, error)
+}
+
+// Screener decides whether a transfer may go ahead
+type Screener interface {
+	Screen(ctx context.Context, transfer Transfer) (Decision, error)
+	// ScreenBatch decides on the transfers of a batch in order, each as if the ones before it had been made
+	ScreenBatch(ctx context.Context, transfers []Transfer) ([]Decision, error)
+}
+
+// Engine runs every rule against a transfer. An engine without rules allows everything.
+type Engine struct {
+	history History
+	rules   []Rule
+	now     func() time.Time
+}
+
+func NewEngine(history History, rules ...Rule) *Engine {
+	return &Engine{
+		history: history,
+		rules:   rules,
+		now:     time.Now,
+	}
+}
+
+func (engine *Engine) Screen(ctx context.Context, transfer Transfer) (Decision, error) {
+	if transfer.At.IsZero() {
+		transfer.At = engine.now()
+	}
+	return engine.screen(ctx, engine.history, transfer)
+}
+
+// ScreenBatch screens every transfer against a history that already holds the transfers before it, so a
+// payment split into the legs of a batch matches the same rules as the transfers made one by one
+func (engine *Engine) ScreenBatch(ctx context.Context, transfers []Transfer) ([]Decision, error) {
+	now := engine.now()
+	decisions := make([]Decision, len(transfers))
+
+	for i, transfer := range transfers {
+		if transfer.At.IsZero() {
+			transfer.At = now
+		}
+
+		decision, err := engine.screen(ctx, batchHistory{History: engine.history, earlier: transfers[:i]}, transfer)
+		if err != nil {
+			return nil, err
+		}
+		decisions[i] = decision
+	}
+
+	return decisions, nil
+}
+
+func (engine *Engine) screen(ctx context.Context, history History, transfer Transfer) (Decision, error) {
+	decision := Decision{Action: ActionAllow, Reasons: []Reason{}}
+
+	for _, rule := range engine.rules {
+		reason, err := rule.Evaluate(ctx, history, transfer)
+		if err != nil {
+			return Decision{}, err
+		}
+		if reason == nil {
+			continue
+		}
+
+		decision.Reasons = append(decision.Reasons, *reason)
+		if severity[reason.Action] > severity[decision.Action] {
+			decision.Action = reason.Action
+		}
+	}
+
+	return decision, nil
+}
+
+// batchHistory adds the earlier transfers of a batch to the history. They are about to be made, so they
+// fall within every window the rules look back over.
+type batchHistory struct {
+	History
+	earlier []Transfer
+}
+
+func (history batchHistory) CountTransfersToPayee(ctx context.Context, arg db.CountTransfersToPayeeParams) (int64, error) {
+	count, err := history.History.CountTransfersToPayee(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, transfer := range history.earlier {
+		if transfer.FromAccountID == arg.FromAccountID && transfer.ToAccountID == arg.ToAccountID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (history batchHistory) CountNewPayeesSince(ctx context.Context, arg db.CountNewPayeesSinceParams) (int64, error) {
+	count, err := history.History.CountNewPayeesSince(ctx, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	// an earlier payee is new when the account has never paid it before the batch
+	seen := map[int64]bool{}
+	for _, transfer := range history.earlier {
+		if transfer.FromAccountID != arg.FromAccountID || seen[transfer.ToAccountID] {
+			continue
+		}
+		seen[transfer.ToAccountID] = true
+
+		paid, err := history.History.CountTransfersToPayee(ctx, db.CountTransfersToPayeeParams{
+			FromAccountID: transfer.FromAccountID,
+			ToAccountID:   transfer.ToAccountID,
+		})
+		if err != nil {
+			return 0, err
+		}
+		if paid == 0 {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (history batchHistory) ListTransferAmountsSince(ctx context.Context, arg db.ListTransferAmountsSinceParams) ([]int64, error) {
+	amounts, err := history.History.ListTransferAmountsSince(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, transfer := range history.earlier {
+		if transfer.FromAccountID == arg.FromAccountID {
+			amounts = append(amounts, transfer.Amount)
+		}
+	}
+	return amounts, nil
+}
@@ -0,0 +1,125 @@
+package fraud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type ruleFunc func(transfer Transfer) (*Reason, error)
+
+func (fn ruleFunc) Evaluate(ctx context.Context, history History, transfer Transfer) (*Reason, error) {
+	return fn(transfer)
+}
+
+func matching(action string) Rule {
+	return ruleFunc(func(transfer Transfer) (*Reason, error) {
+		return &Reason{Rule: action + "-rule", Action: action, Message: "matched"}, nil
+	})
+}
+
+var notMatching Rule = ruleFunc(func(transfer Transfer) (*Reason, error) {
+	return nil, nil
+})
+
+func TestEngineScreen(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rules   []Rule
+		action  string
+		reasons int
+	}{
+		{
+			name:   "NoRules",
+			action: ActionAllow,
+		},
+		{
+			name:   "NoMatch",
+			rules:  []Rule{notMatching},
+			action: ActionAllow,
+		},
+		{
+			name:    "Review",
+			rules:   []Rule{notMatching, matching(ActionReview)},
+			action:  ActionReview,
+			reasons: 1,
+		},
+		{
+			name:    "BlockWins",
+			rules:   []Rule{matching(ActionBlock), matching(ActionReview)},
+			action:  ActionBlock,
+			reasons: 2,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			engine := NewEngine(nil, tc.rules...)
+
+			decision, err := engine.Screen(context.Background(), testTransfer(100))
+			require.NoError(t, err)
+			require.Equal(t, tc.action, decision.Action)
+			require.Len(t, decision.Reasons, tc.reasons)
+		})
+	}
+}
+
+func TestEngineScreenSetsTime(t *testing.T) {
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	var got time.Time
+	engine := NewEngine(nil, ruleFunc(func(transfer Transfer) (*Reason, error) {
+		got = transfer.At
+		return nil, nil
+	}))
+	engine.now = func() time.Time { return now }
+
+	_, err := engine.Screen(context.Background(), Transfer{Amount: 100})
+	require.NoError(t, err)
+	require.Equal(t, now, got)
+}
+
+func TestEngineScreenError(t *testing.T) {
+	ruleErr := errors.New("rule failed")
+	engine := NewEngine(nil, ruleFunc(func(transfer Transfer) (*Reason, error) {
+		return nil, ruleErr
+	}))
+
+	_, err := engine.Screen(context.Background(), testTransfer(100))
+	require.ErrorIs(t, err, ruleErr)
+}
+
+func TestEngineScreenBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the account has never paid anyone, so each leg of the batch is to a new payee
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CountTransfersToPayee(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+	store.EXPECT().CountNewPayeesSince(gomock.Any(), gomock.Any()).AnyTimes().Return(int64(0), nil)
+
+	engine := NewEngine(store, &FanOutRule{
+		ruleBase:     ruleBase{name: "fan-out", action: ActionReview},
+		Window:       time.Hour,
+		MaxNewPayees: 2,
+	})
+
+	transfers := make([]Transfer, 3)
+	for i := range transfers {
+		transfers[i] = testTransfer(100)
+		transfers[i].ToAccountID = int64(i + 2)
+	}
+
+	decisions, err := engine.ScreenBatch(context.Background(), transfers)
+	require.NoError(t, err)
+	require.Len(t, decisions, 3)
+	require.Equal(t, ActionAllow, decisions[0].Action)
+	require.Equal(t, ActionAllow, decisions[1].Action)
+	require.Equal(t, ActionReview, decisions[2].Action)
+}
@@ -0,0 +1,108 @@
+package fraud
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Rule types of the rules file
+const (
+	RuleFirstTimePayee   = "first_time_payee"
+	RuleFanOut           = "fan_out"
+	RuleRoundAmountBurst = "round_amount_burst"
+)
+
+type fileRule struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Action string `json:"action"`
+	// Currency limits the rule to transfers from accounts in that currency, amounts are in its minor units
+	Currency     string `json:"currency"`
+	MinAmount    int64  `json:"min_amount"`
+	Window       string `json:"window"`
+	MaxNewPayees int64  `json:"max_new_payees"`
+	RoundTo      int64  `json:"round_to"`
+	MaxCount     int64  `json:"max_count"`
+}
+
+// LoadRules reads rules from a JSON file. The file holds a list of objects with a "name", a "type", the
+// "action" (review or block) and the settings of the type:
+//
+//	first_time_payee:   min_amount
+//	fan_out:            window, max_new_payees
+//	round_amount_burst: window, round_to, max_count
+//
+// Windows are durations such as "1h". Any rule may be limited to one "currency".
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read fraud rules: %w", err)
+	}
+
+	var entries []fileRule
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("cannot parse fraud rules: %w", err)
+	}
+
+	rules := make([]Rule, len(entries))
+	for i, entry := range entries {
+		rules[i], err = entry.rule()
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", entry.Name, err)
+		}
+	}
+
+	return rules, nil
+}
+
+func (entry fileRule) rule() (Rule, error) {
+	if entry.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if entry.Action != ActionReview && entry.Action != ActionBlock {
+		return nil, fmt.Errorf("action must be %s or %s", ActionReview, ActionBlock)
+	}
+	base := ruleBase{name: entry.Name, action: entry.Action, currency: entry.Currency}
+
+	switch entry.Type {
+	case RuleFirstTimePayee:
+		if entry.MinAmount < 1 {
+			return nil, errors.New("min_amount must be positive")
+		}
+		return &FirstTimePayeeRule{ruleBase: base, MinAmount: entry.MinAmount}, nil
+	case RuleFanOut:
+		window, err := entry.window()
+		if err != nil {
+			return nil, err
+		}
+		if entry.MaxNewPayees < 1 {
+			return nil, errors.New("max_new_payees must be positive")
+		}
+		return &FanOutRule{ruleBase: base, Window: window, MaxNewPayees: entry.MaxNewPayees}, nil
+	case RuleRoundAmountBurst:
+		window, err := entry.window()
+		if err != nil {
+			return nil, err
+		}
+		if entry.RoundTo < 1 || entry.MaxCount < 1 {
+			return nil, errors.New("round_to and max_count must be positive")
+		}
+		return &RoundAmountBurstRule{ruleBase: base, Window: window, RoundTo: entry.RoundTo, MaxCount: entry.MaxCount}, nil
+	}
+
+	return nil, fmt.Errorf("unknown type %q", entry.Type)
+}
+
+func (entry fileRule) window() (time.Duration, error) {
+	window, err := time.ParseDuration(entry.Window)
+	if err != nil {
+		return 0, fmt.Errorf("invalid window: %w", err)
+	}
+	if window <= 0 {
+		return 0, errors.New("window must be positive")
+	}
+	return window, nil
+}
@@ -0,0 +1,95 @@
+package fraud
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeRulesFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+	return path
+}
+
+func TestLoadRules(t *testing.T) {
+	path := writeRulesFile(t, `[
+		{"name": "large-first-payment", "type": "first_time_payee", "action": "review", "currency": "USD", "min_amount": 100000},
+		{"name": "fan-out", "type": "fan_out", "action": "review", "window": "1h", "max_new_payees": 5},
+		{"name": "round-burst", "type": "round_amount_burst", "action": "block", "window": "10m", "round_to": 10000, "max_count": 3}
+	]`)
+
+	rules, err := LoadRules(path)
+	require.NoError(t, err)
+	require.Equal(t, []Rule{
+		&FirstTimePayeeRule{
+			ruleBase:  ruleBase{name: "large-first-payment", action: ActionReview, currency: "USD"},
+			MinAmount: 100000,
+		},
+		&FanOutRule{
+			ruleBase:     ruleBase{name: "fan-out", action: ActionReview},
+			Window:       time.Hour,
+			MaxNewPayees: 5,
+		},
+		&RoundAmountBurstRule{
+			ruleBase: ruleBase{name: "round-burst", action: ActionBlock},
+			Window:   10 * time.Minute,
+			RoundTo:  10000,
+			MaxCount: 3,
+		},
+	}, rules)
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+	}{
+		{
+			name:     "NotJSON",
+			contents: `rules`,
+		},
+		{
+			name:     "UnknownType",
+			contents: `[{"name": "rule", "type": "velocity", "action": "review"}]`,
+		},
+		{
+			name:     "MissingName",
+			contents: `[{"type": "first_time_payee", "action": "review", "min_amount": 100}]`,
+		},
+		{
+			name:     "InvalidAction",
+			contents: `[{"name": "rule", "type": "first_time_payee", "action": "allow", "min_amount": 100}]`,
+		},
+		{
+			name:     "MissingMinAmount",
+			contents: `[{"name": "rule", "type": "first_time_payee", "action": "review"}]`,
+		},
+		{
+			name:     "InvalidWindow",
+			contents: `[{"name": "rule", "type": "fan_out", "action": "review", "window": "soon", "max_new_payees": 5}]`,
+		},
+		{
+			name:     "MissingRoundTo",
+			contents: `[{"name": "rule", "type": "round_amount_burst", "action": "block", "window": "1h", "max_count": 3}]`,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := LoadRules(writeRulesFile(t, tc.contents))
+			require.Error(t, err)
+			require.Nil(t, rules)
+		})
+	}
+}
+
+func TestLoadRulesMissingFile(t *testing.T) {
+	rules, err := LoadRules(filepath.Join(t.TempDir(), "missing.json"))
+	require.Error(t, err)
+	require.Nil(t, rules)
+}
@@ -0,0 +1,119 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+var (
+	ErrTransferBlocked  = errors.New("transfer was blocked by fraud screening")
+	ErrBatchNeedsReview = errors.New("batch has transfers that need review, make them as single transfers")
+)
+
+// BlockedError is returned for a transfer the rules blocked, or a batch with such a transfer
+type BlockedError struct {
+	Reasons []Reason
+}
+
+func (err *BlockedError) Error() string {
+	return ErrTransferBlocked.Error() + ": " + reasonMessages(err.Reasons)
+}
+
+func (err *BlockedError) Is(target error) bool {
+	return target == ErrTransferBlocked
+}
+
+// BatchReviewError is returned for a batch with transfers the rules send to review. A batch is made all at
+// once, so it cannot wait for one of its transfers to be approved.
+type BatchReviewError struct {
+	Reasons []Reason
+}
+
+func (err *BatchReviewError) Error() string {
+	return ErrBatchNeedsReview.Error() + ": " + reasonMessages(err.Reasons)
+}
+
+func (err *BatchReviewError) Is(target error) bool {
+	return target == ErrBatchNeedsReview
+}
+
+func reasonMessages(reasons []Reason) string {
+	messages := make([]string, len(reasons))
+	for i, reason := range reasons {
+		messages[i] = reason.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Reviews is the part of the store that holds transfers back for review
+type Reviews interface {
+	CreatePendingTransferTx(ctx context.Context, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error)
+}
+
+// ScreenTransfer screens a transfer before it is made. It returns nil when the transfer may go ahead and a
+// *BlockedError when it was blocked. A transfer that needs review is stored as a pending transfer, together
+// with the idempotency key of the request when there is one, and returned.
+func ScreenTransfer(ctx context.Context, screener Screener, reviews Reviews, transfer Transfer, requestedBy string, idempotency *db.IdempotencyParams) (*db.PendingTransfer, error) {
+	decision, err := screener.Screen(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	switch decision.Action {
+	case ActionBlock:
+		return nil, &BlockedError{Reasons: decision.Reasons}
+	case ActionReview:
+		reasons, err := json.Marshal(decision.Reasons)
+		if err != nil {
+			return nil, err
+		}
+
+		pending, err := reviews.CreatePendingTransferTx(ctx, db.CreatePendingTransferTxParams{
+			CreatePendingTransferParams: db.CreatePendingTransferParams{
+				FromAccountID: transfer.FromAccountID,
+				ToAccountID:   transfer.ToAccountID,
+				Amount:        transfer.Amount,
+				Currency:      transfer.Currency,
+				RequestedBy:   requestedBy,
+				Reasons:       reasons,
+			},
+			Idempotency: idempotency,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &pending, nil
+	}
+
+	return nil, nil
+}
+
+// ScreenBatch screens the transfers of a batch. It returns a *BlockedError when one of them was blocked
+// and a *BatchReviewError when one of them needs review.
+func ScreenBatch(ctx context.Context, screener Screener, transfers []Transfer) error {
+	decisions, err := screener.ScreenBatch(ctx, transfers)
+	if err != nil {
+		return err
+	}
+
+	action := ActionAllow
+	var reasons []Reason
+	for _, decision := range decisions {
+		reasons = append(reasons, decision.Reasons...)
+		if severity[decision.Action] > severity[action] {
+			action = decision.Action
+		}
+	}
+
+	switch action {
+	case ActionBlock:
+		return &BlockedError{Reasons: reasons}
+	case ActionReview:
+		return &BatchReviewError{Reasons: reasons}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScreenTransfer(t *testing.T) {
+	transfer := testTransfer(100)
+	idempotency := &db.IdempotencyParams{Key: "key", Username: "alice"}
+
+	testCases := []struct {
+		name          string
+		rules         []Rule
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, pending *db.PendingTransfer, err error)
+	}{
+		{
+			name:  "Allow",
+			rules: []Rule{notMatching},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, pending *db.PendingTransfer, err error) {
+				require.NoError(t, err)
+				require.Nil(t, pending)
+			},
+		},
+		{
+			name:  "Block",
+			rules: []Rule{matching(ActionBlock), matching(ActionReview)},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, pending *db.PendingTransfer, err error) {
+				require.ErrorIs(t, err, ErrTransferBlocked)
+				require.Nil(t, pending)
+
+				blocked, ok := err.(*BlockedError)
+				require.True(t, ok)
+				require.Len(t, blocked.Reasons, 2)
+			},
+		},
+		{
+			name:  "Review",
+			rules: []Rule{matching(ActionReview)},
+			buildStubs: func(store *mockdb.MockStore) {
+				reasons, err := json.Marshal([]Reason{{Rule: "review-rule", Action: ActionReview, Message: "matched"}})
+				require.NoError(t, err)
+
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Eq(db.CreatePendingTransferTxParams{
+					CreatePendingTransferParams: db.CreatePendingTransferParams{
+						FromAccountID: transfer.FromAccountID,
+						ToAccountID:   transfer.ToAccountID,
+						Amount:        transfer.Amount,
+						Currency:      transfer.Currency,
+						RequestedBy:   "alice",
+						Reasons:       reasons,
+					},
+					Idempotency: idempotency,
+				})).Times(1).Return(db.PendingTransfer{ID: 7}, nil)
+			},
+			checkResponse: func(t *testing.T, pending *db.PendingTransfer, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, pending)
+				require.Equal(t, int64(7), pending.ID)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			pending, err := ScreenTransfer(context.Background(), NewEngine(store, tc.rules...), store, transfer, "alice", idempotency)
+			tc.checkResponse(t, pending, err)
+		})
+	}
+}
+
+func TestScreenBatch(t *testing.T) {
+	transfers := []Transfer{testTransfer(100), testTransfer(200)}
+
+	testCases := []struct {
+		name   string
+		rules  []Rule
+		target error
+	}{
+		{
+			name:  "Allow",
+			rules: []Rule{notMatching},
+		},
+		{
+			name:   "Review",
+			rules:  []Rule{matching(ActionReview)},
+			target: ErrBatchNeedsReview,
+		},
+		{
+			name:   "Block",
+			rules:  []Rule{matching(ActionReview), matching(ActionBlock)},
+			target: ErrTransferBlocked,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			err := ScreenBatch(context.Background(), NewEngine(nil, tc.rules...), transfers)
+			if tc.target == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.target)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package fraud
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+// ruleBase holds what every rule has: a name to report it under, the action it asks for and optionally the
+// one currency it applies to
+type ruleBase struct {
+	name     string
+	action   string
+	currency string
+}
+
+func (rule ruleBase) applies(transfer Transfer) bool {
+	return rule.currency == "" || rule.currency == transfer.Currency
+}
+
+func (rule ruleBase) reason(format string, args ...interface{}) *Reason {
+	return &Reason{
+		Rule:    rule.name,
+		Action:  rule.action,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// FirstTimePayeeRule matches a transfer of at least MinAmount to an account the source account has never
+// sent money to
+type FirstTimePayeeRule struct {
+	ruleBase
+	MinAmount int64
+}
+
+func (rule *FirstTimePayeeRule) Evaluate(ctx context.Context, history History, transfer Transfer) (*Reason, error) {
+	if !rule.applies(transfer) || transfer.Amount < rule.MinAmount {
+		return nil, nil
+	}
+
+	isNew, err := isNewPayee(ctx, history, transfer)
+	if err != nil || !isNew {
+		return nil, err
+	}
+
+	return rule.reason("first transfer to account [%d] is %d, at least %d", transfer.ToAccountID, transfer.Amount, rule.MinAmount), nil
+}
+
+// FanOutRule matches a transfer that makes the source account pay more than MaxNewPayees accounts it had
+// never paid before within Window
+type FanOutRule struct {
+	ruleBase
+	Window       time.Duration
+	MaxNewPayees int64
+}
+
+func (rule *FanOutRule) Evaluate(ctx context.Context, history History, transfer Transfer) (*Reason, error) {
+	if !rule.applies(transfer) {
+		return nil, nil
+	}
+
+	isNew, err := isNewPayee(ctx, history, transfer)
+	if err != nil || !isNew {
+		return nil, err
+	}
+
+	count, err := history.CountNewPayeesSince(ctx, db.CountNewPayeesSinceParams{
+		FromAccountID: transfer.FromAccountID,
+		Since:         transfer.At.Add(-rule.Window),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if count+1 <= rule.MaxNewPayees {
+		return nil, nil
+	}
+
+	return rule.reason("transfer would make %d new payees within %s, more than %d", count+1, rule.Window, rule.MaxNewPayees), nil
+}
+
+// RoundAmountBurstRule matches a transfer of a multiple of RoundTo when the source account has already made
+// MaxCount such transfers within Window
+type RoundAmountBurstRule struct {
+	ruleBase
+	Window   time.Duration
+	RoundTo  int64
+	MaxCount int64
+}
+
+func (rule *RoundAmountBurstRule) Evaluate(ctx context.Context, history History, transfer Transfer) (*Reason, error) {
+	if !rule.applies(transfer) || transfer.Amount%rule.RoundTo != 0 {
+		return nil, nil
+	}
+
+	amounts, err := history.ListTransferAmountsSince(ctx, db.ListTransferAmountsSinceParams{
+		FromAccountID: transfer.FromAccountID,
+		Since:         transfer.At.Add(-rule.Window),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64 = 1
+	for _, amount := range amounts {
+		if amount%rule.RoundTo == 0 {
+			count++
+		}
+	}
+	if count <= rule.MaxCount {
+		return nil, nil
+	}
+
+	return rule.reason("transfer would make %d transfers of multiples of %d within %s, more than %d", count, rule.RoundTo, rule.Window, rule.MaxCount), nil
+}
+
+func isNewPayee(ctx context.Context, history History, transfer Transfer) (bool, error) {
+	count, err := history.CountTransfersToPayee(ctx, db.CountTransfersToPayeeParams{
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID:   transfer.ToAccountID,
+	})
+	return count == 0, err
+}
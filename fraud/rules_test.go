@@ -0,0 +1,213 @@
+package fraud
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/stretchr/testify/require"
+)
+
+func testTransfer(amount int64) Transfer {
+	return Transfer{
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        amount,
+		Currency:      "USD",
+		At:            time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC),
+	}
+}
+
+func expectPayeeCount(store *mockdb.MockStore, count int64) {
+	store.EXPECT().CountTransfersToPayee(gomock.Any(), gomock.Eq(db.CountTransfersToPayeeParams{
+		FromAccountID: 1,
+		ToAccountID:   2,
+	})).Times(1).Return(count, nil)
+}
+
+func TestFirstTimePayeeRule(t *testing.T) {
+	rule := &FirstTimePayeeRule{
+		ruleBase:  ruleBase{name: "large-first-payment", action: ActionReview, currency: "USD"},
+		MinAmount: 1000,
+	}
+
+	testCases := []struct {
+		name       string
+		transfer   Transfer
+		buildStubs func(store *mockdb.MockStore)
+		matches    bool
+	}{
+		{
+			name:     "NewPayee",
+			transfer: testTransfer(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayeeCount(store, 0)
+			},
+			matches: true,
+		},
+		{
+			name:     "KnownPayee",
+			transfer: testTransfer(1000),
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayeeCount(store, 3)
+			},
+		},
+		{
+			name:     "SmallAmount",
+			transfer: testTransfer(999),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersToPayee(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name: "OtherCurrency",
+			transfer: Transfer{
+				FromAccountID: 1,
+				ToAccountID:   2,
+				Amount:        5000,
+				Currency:      "EUR",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersToPayee(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			reason, err := rule.Evaluate(context.Background(), store, tc.transfer)
+			require.NoError(t, err)
+			if !tc.matches {
+				require.Nil(t, reason)
+				return
+			}
+			require.NotNil(t, reason)
+			require.Equal(t, "large-first-payment", reason.Rule)
+			require.Equal(t, ActionReview, reason.Action)
+			require.NotEmpty(t, reason.Message)
+		})
+	}
+}
+
+func TestFanOutRule(t *testing.T) {
+	rule := &FanOutRule{
+		ruleBase:     ruleBase{name: "fan-out", action: ActionReview},
+		Window:       time.Hour,
+		MaxNewPayees: 3,
+	}
+	transfer := testTransfer(100)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		matches    bool
+	}{
+		{
+			name: "TooManyNewPayees",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayeeCount(store, 0)
+				store.EXPECT().CountNewPayeesSince(gomock.Any(), gomock.Eq(db.CountNewPayeesSinceParams{
+					FromAccountID: 1,
+					Since:         transfer.At.Add(-time.Hour),
+				})).Times(1).Return(int64(3), nil)
+			},
+			matches: true,
+		},
+		{
+			name: "WithinLimit",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayeeCount(store, 0)
+				store.EXPECT().CountNewPayeesSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(2), nil)
+			},
+		},
+		{
+			name: "KnownPayee",
+			buildStubs: func(store *mockdb.MockStore) {
+				expectPayeeCount(store, 1)
+				store.EXPECT().CountNewPayeesSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			reason, err := rule.Evaluate(context.Background(), store, transfer)
+			require.NoError(t, err)
+			require.Equal(t, tc.matches, reason != nil)
+		})
+	}
+}
+
+func TestRoundAmountBurstRule(t *testing.T) {
+	rule := &RoundAmountBurstRule{
+		ruleBase: ruleBase{name: "round-burst", action: ActionBlock},
+		Window:   10 * time.Minute,
+		RoundTo:  10000,
+		MaxCount: 3,
+	}
+
+	testCases := []struct {
+		name       string
+		transfer   Transfer
+		buildStubs func(store *mockdb.MockStore)
+		matches    bool
+	}{
+		{
+			name:     "Burst",
+			transfer: testTransfer(50000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferAmountsSince(gomock.Any(), gomock.Eq(db.ListTransferAmountsSinceParams{
+					FromAccountID: 1,
+					Since:         testTransfer(0).At.Add(-10 * time.Minute),
+				})).Times(1).Return([]int64{10000, 1234, 20000, 30000}, nil)
+			},
+			matches: true,
+		},
+		{
+			name:     "FewRoundAmounts",
+			transfer: testTransfer(50000),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferAmountsSince(gomock.Any(), gomock.Any()).Times(1).Return([]int64{10000, 1234, 20000}, nil)
+			},
+		},
+		{
+			name:     "NotRound",
+			transfer: testTransfer(50001),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListTransferAmountsSince(gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			reason, err := rule.Evaluate(context.Background(), store, tc.transfer)
+			require.NoError(t, err)
+			require.Equal(t, tc.matches, reason != nil)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	authorizationHeader     = "authorization"
+	authorizationTypeBearer = "bearer"
+)
+
+var errTokenRevoked = errors.New("token was issued before the last password change")
+
+// authorizeUser checks the bearer token in the metadata of the call like the auth middleware of the HTTP
+// server, rejecting tokens issued before the user last changed their password. The returned context records
+// the user as the actor of the audit events of the call.
+func (server *Server) authorizeUser(ctx context.Context) (context.Context, *token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil, unauthenticatedError(errors.New("missing metadata"))
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return ctx, nil, unauthenticatedError(errors.New("authorization header is not provided"))
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return ctx, nil, unauthenticatedError(errors.New("invalid authorization header format"))
+	}
+
+	authorizationType := strings.ToLower(fields[0])
+	if authorizationType != authorizationTypeBearer {
+		return ctx, nil, unauthenticatedError(fmt.Errorf("unsupported authorization type %s", authorizationType))
+	}
+
+	payload, err := server.tokenMaker.VerifyToken(fields[1], token.TokenTypeAccess)
+	if err != nil {
+		return ctx, nil, unauthenticatedError(err)
+	}
+
+	user, err := server.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ctx, nil, unauthenticatedError(err)
+		}
+		return ctx, nil, status.Errorf(codes.Internal, "cannot find user: %s", err)
+	}
+
+	if payload.IssuedAt.Before(user.PasswordChangedAt) {
+		return ctx, nil, unauthenticatedError(errTokenRevoked)
+	}
+
+	audit := db.AuditContextFrom(ctx)
+	audit.Actor = payload.Username
+	return db.WithAuditContext(ctx, audit), payload, nil
+}
@@ -0,0 +1,138 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := utils.Config{
+		TokenSymmetricKey:    utils.RandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+
+	server, err := NewServer(config, store)
+	require.NoError(t, err)
+
+	return server
+}
+
+// newContextWithBearerToken returns an incoming context carrying an access token for username
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, token.TokenTypeAccess, duration)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken)},
+	}
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// stubAuthUser lets authorizeUser look up any user that never changed their password
+func stubAuthUser(store *mockdb.MockStore) {
+	store.EXPECT().GetUser(gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(
+		func(_ context.Context, username string) (db.User, error) {
+			return db.User{Username: username}, nil
+		})
+}
+
+// testCurrencies mirrors the currencies seeded by the migrations
+var testCurrencies = []db.Currency{
+	{Code: "CAD", Name: "Canadian Dollar", MinorUnits: 2, Enabled: true},
+	{Code: "EUR", Name: "Euro", MinorUnits: 2, Enabled: true},
+	{Code: "USD", Name: "US Dollar", MinorUnits: 2, Enabled: true},
+}
+
+// stubCurrencies loads testCurrencies into the currency registry
+func stubCurrencies(store *mockdb.MockStore) {
+	store.EXPECT().ListCurrencies(gomock.Any()).AnyTimes().Return(testCurrencies, nil)
+}
+
+// stubScreener returns the same decision for every transfer
+type stubScreener struct {
+	decision fraud.Decision
+	err      error
+}
+
+func (screener stubScreener) Screen(ctx context.Context, transfer fraud.Transfer) (fraud.Decision, error) {
+	return screener.decision, screener.err
+}
+
+func (screener stubScreener) ScreenBatch(ctx context.Context, transfers []fraud.Transfer) ([]fraud.Decision, error) {
+	decisions := make([]fraud.Decision, len(transfers))
+	for i := range transfers {
+		decisions[i] = screener.decision
+	}
+	return decisions, screener.err
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	password = utils.RandomString(6)
+	hashedPassword, err := utils.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username:       utils.RandomOwner(),
+		HashedPassword: hashedPassword,
+		FullName:       utils.RandomOwner(),
+		Email:          utils.RandomEmail(),
+	}
+	return
+}
+
+// randomCurrency returns the code of one of testCurrencies
+func randomCurrency() string {
+	codes := make([]string, len(testCurrencies))
+	for i, currency := range testCurrencies {
+		codes[i] = currency.Code
+	}
+	return utils.RandomCurrency(codes)
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       utils.RandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  utils.RandomMoney(),
+		Currency: randomCurrency(),
+		Kind:     db.AccountKindCustomer,
+		Status:   db.AccountStatusActive,
+	}
+}
+
+func requireStatusCode(t *testing.T, err error, code codes.Code) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code(), st.Message())
+}
+
+// requireFieldViolations checks that err is an InvalidArgument status naming exactly the given fields
+func requireFieldViolations(t *testing.T, err error, fields ...string) {
+	requireStatusCode(t, err, codes.InvalidArgument)
+
+	st, _ := status.FromError(err)
+	var violated []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violated = append(violated, violation.GetField())
+			}
+		}
+	}
+	require.ElementsMatch(t, fields, violated)
+}
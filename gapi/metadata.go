@@ -0,0 +1,65 @@
+package gapi
+
+import (
+	"context"
+	"net"
+
+	"github.com/google/uuid"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	userAgentHeader    = "user-agent"
+	requestIDHeader    = "x-request-id"
+	maxRequestIDLength = 128
+)
+
+// Metadata is what a call tells about its client, the counterpart of the request headers over HTTP
+type Metadata struct {
+	UserAgent string
+	ClientIP  string
+	RequestID string
+}
+
+func extractMetadata(ctx context.Context) *Metadata {
+	mtdt := &Metadata{}
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+			mtdt.UserAgent = userAgents[0]
+		}
+		if requestIDs := md.Get(requestIDHeader); len(requestIDs) > 0 {
+			mtdt.RequestID = requestIDs[0]
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok {
+		mtdt.ClientIP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(mtdt.ClientIP); err == nil {
+			mtdt.ClientIP = host
+		}
+	}
+
+	return mtdt
+}
+
+// auditInterceptor tags every call with the request id and client address its audit events record, the same
+// way the audit middleware of the HTTP server does. The actor stays anonymous until authorizeUser knows who
+// is calling.
+func auditInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	mtdt := extractMetadata(ctx)
+	if mtdt.RequestID == "" || len(mtdt.RequestID) > maxRequestIDLength {
+		mtdt.RequestID = uuid.NewString()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, mtdt.RequestID))
+
+	ctx = db.WithAuditContext(ctx, db.AuditContext{
+		Actor:     db.AuditActorAnonymous,
+		RequestID: mtdt.RequestID,
+		ClientIP:  mtdt.ClientIP,
+	})
+	return handler(ctx, req)
+}
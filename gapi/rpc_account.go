@@ -0,0 +1,115 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/pb"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	ctx, authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := server.newValidation()
+	v.check("currency", req.GetCurrency(), "required")
+	if req.GetCurrency() != "" {
+		if _, enabled := server.currencies.Enabled(ctx, req.GetCurrency()); !enabled {
+			v.add("currency", fmt.Errorf("currency %s is not supported", req.GetCurrency()))
+		}
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	account, err := server.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Balance:  0,
+		Currency: req.GetCurrency(),
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation":
+				return nil, status.Errorf(codes.PermissionDenied, "%s", err)
+			case "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "%s", err)
+			}
+		}
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	ctx, authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := server.newValidation()
+	v.check("id", req.GetId(), "required,min=1")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	account, err := server.authorizedAccount(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	ctx, authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := server.newValidation()
+	v.check("page_id", req.GetPageId(), "required,min=1")
+	v.check("page_size", req.GetPageSize(), "required,min=1,max=10")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	accounts, err := server.store.ListAccountsByOwner(ctx, db.ListAccountsByOwnerParams{
+		Owner:  authPayload.Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, len(accounts))}
+	for i, account := range accounts {
+		rsp.Accounts[i] = convertAccount(account)
+	}
+	return rsp, nil
+}
+
+// authorizedAccount fetches the account and makes sure it belongs to the authenticated user
+func (server *Server) authorizedAccount(ctx context.Context, authPayload *token.Payload, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, storeError(err)
+	}
+
+	if account.Owner != authPayload.Username {
+		err := errors.New("account doesn't belong to the authenticated user")
+		return account, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return account, nil
+}
@@ -0,0 +1,43 @@
+package gapi
+
+import (
+	"context"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/pb"
+)
+
+// ListEntries lists the entries of an account of the authenticated user, oldest first
+func (server *Server) ListEntries(ctx context.Context, req *pb.ListEntriesRequest) (*pb.ListEntriesResponse, error) {
+	ctx, authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := server.newValidation()
+	v.check("account_id", req.GetAccountId(), "required,min=1")
+	v.check("page_id", req.GetPageId(), "required,min=1")
+	v.check("page_size", req.GetPageSize(), "required,min=5,max=10")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := server.authorizedAccount(ctx, authPayload, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	entries, err := server.store.ListEntriesForAccount(ctx, db.ListEntriesForAccountParams{
+		AccountID: req.GetAccountId(),
+		Limit:     req.GetPageSize(),
+		Offset:    (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListEntriesResponse{Entries: make([]*pb.Entry, len(entries))}
+	for i, entry := range entries {
+		rsp.Entries[i] = convertEntry(entry)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,171 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/exchange"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/pb"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CreateTransfer moves money out of an account of the authenticated user. The checks are those of the HTTP
+// API: transfers between currencies are converted at the current rate, and transfers the fraud rules send
+// to review are held back and only return the id of the pending transfer.
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	ctx, authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := server.newValidation()
+	v.check("from_account_id", req.GetFromAccountId(), "required,min=1")
+	v.check("to_account_id", req.GetToAccountId(), "required,min=1")
+	if req.GetToAccountId() != 0 && req.GetToAccountId() == req.GetFromAccountId() {
+		v.add("to_account_id", errors.New("must differ from from_account_id"))
+	}
+	v.check("currency", req.GetCurrency(), "required")
+	switch value := req.GetValue().(type) {
+	case *pb.CreateTransferRequest_Amount:
+		v.check("amount", value.Amount, "gt=0")
+	case *pb.CreateTransferRequest_DecimalAmount:
+		v.check("decimal_amount", value.DecimalAmount, "required")
+	default:
+		v.add("amount", errors.New("either amount or decimal_amount is required"))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	currency, enabled := server.currencies.Enabled(ctx, req.GetCurrency())
+	if !enabled {
+		v.add("currency", fmt.Errorf("currency %s is not supported", req.GetCurrency()))
+		return nil, v.err()
+	}
+
+	amount := req.GetAmount()
+	if decimalAmount := req.GetDecimalAmount(); decimalAmount != "" {
+		amount, err = utils.ParseAmount(decimalAmount, currency.MinorUnits)
+		if err != nil {
+			v.add("decimal_amount", err)
+			return nil, v.err()
+		}
+	}
+
+	fromAccount, err := server.store.GetAccount(ctx, req.GetFromAccountId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	if fromAccount.Currency != currency.Code {
+		err := fmt.Errorf("account [%d] currency mismatch: %s vs %s", fromAccount.ID, fromAccount.Currency, currency.Code)
+		v.add("currency", err)
+		return nil, v.err()
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Error(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, req.GetToAccountId())
+	if err != nil {
+		return nil, storeError(err)
+	}
+	if toAccount.Kind != db.AccountKindCustomer {
+		return nil, status.Errorf(codes.PermissionDenied, "account [%d] cannot receive transfers", toAccount.ID)
+	}
+
+	pending, err := server.screenTransfer(ctx, req, amount, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+	if pending != nil {
+		return &pb.CreateTransferResponse{PendingTransferId: pending.ID}, nil
+	}
+
+	var result db.TransferTxResult
+	if toAccount.Currency == fromAccount.Currency {
+		result, err = server.store.TransferTx(ctx, db.TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+		})
+	} else {
+		var arg db.CrossCurrencyTransferTxParams
+		arg, err = server.converter.Transfer(ctx, fromAccount.ID, toAccount, amount, currency)
+		if err != nil {
+			if errors.Is(err, exchange.ErrCannotConvert) {
+				return nil, status.Error(codes.FailedPrecondition, err.Error())
+			}
+			return nil, status.Errorf(codes.Internal, "%s", err)
+		}
+		result, err = server.store.CrossCurrencyTransferTx(ctx, arg)
+	}
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	return &pb.CreateTransferResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount:   convertAccount(result.ToAccount),
+		FromEntry:   convertEntry(result.FromEntry),
+		ToEntry:     convertEntry(result.ToEntry),
+	}, nil
+}
+
+// screenTransfer runs the fraud rules on a transfer. Blocked transfers fail, and those sent to review are
+// stored as pending transfers and returned.
+func (server *Server) screenTransfer(ctx context.Context, req *pb.CreateTransferRequest, amount int64, username string) (*db.PendingTransfer, error) {
+	pending, err := fraud.ScreenTransfer(ctx, server.screener, server.store, fraud.Transfer{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   req.GetToAccountId(),
+		Amount:        amount,
+		Currency:      req.GetCurrency(),
+	}, username, nil)
+	if err != nil {
+		if errors.Is(err, fraud.ErrTransferBlocked) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, storeError(err)
+	}
+	return pending, nil
+}
+
+// ListTransfers lists the transfers into and out of an account of the authenticated user, oldest first
+func (server *Server) ListTransfers(ctx context.Context, req *pb.ListTransfersRequest) (*pb.ListTransfersResponse, error) {
+	ctx, authPayload, err := server.authorizeUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v := server.newValidation()
+	v.check("account_id", req.GetAccountId(), "required,min=1")
+	v.check("page_id", req.GetPageId(), "required,min=1")
+	v.check("page_size", req.GetPageSize(), "required,min=5,max=10")
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := server.authorizedAccount(ctx, authPayload, req.GetAccountId()); err != nil {
+		return nil, err
+	}
+
+	transfers, err := server.store.ListTransfersForAccount(ctx, db.ListTransfersForAccountParams{
+		AccountID: req.GetAccountId(),
+		Limit:     req.GetPageSize(),
+		Offset:    (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, storeError(err)
+	}
+
+	rsp := &pb.ListTransfersResponse{Transfers: make([]*pb.Transfer, len(transfers))}
+	for i, transfer := range transfers {
+		rsp.Transfers[i] = convertTransfer(transfer)
+	}
+	return rsp, nil
+}
@@ -0,0 +1,315 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/pb"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateTransferRPC(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+
+	account1 := randomAccount(user1.Username)
+	account1.Currency = "USD"
+	account2 := randomAccount(user2.Username)
+	account2.ID = account1.ID + 1
+	account2.Currency = "USD"
+	account3 := randomAccount(user2.Username)
+	account3.ID = account1.ID + 2
+	account3.Currency = "EUR"
+
+	amount := int64(10)
+	transferRequest := func(from, to db.Account) *pb.CreateTransferRequest {
+		return &pb.CreateTransferRequest{
+			FromAccountId: from.ID,
+			ToAccountId:   to.ID,
+			Currency:      "USD",
+			Value:         &pb.CreateTransferRequest_Amount{Amount: amount},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateTransferRequest
+		screener      fraud.Screener
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, rsp *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  transferRequest(account1, account2),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        amount,
+				})).Times(1).Return(db.TransferTxResult{
+					Transfer:  db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+					FromEntry: db.Entry{ID: 1, AccountID: account1.ID, Amount: -amount},
+					ToEntry:   db.Entry{ID: 2, AccountID: account2.ID, Amount: amount},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, amount, rsp.GetTransfer().GetAmount())
+				require.Equal(t, -amount, rsp.GetFromEntry().GetAmount())
+				require.Zero(t, rsp.GetPendingTransferId())
+			},
+		},
+		{
+			name: "DecimalAmount",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Currency:      "USD",
+				Value:         &pb.CreateTransferRequest_DecimalAmount{DecimalAmount: "12.50"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID:   account2.ID,
+					Amount:        1250,
+				})).Times(1).Return(db.TransferTxResult{}, nil)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "TooManyDecimalPlaces",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Currency:      "USD",
+				Value:         &pb.CreateTransferRequest_DecimalAmount{DecimalAmount: "12.505"},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireFieldViolations(t, err, "decimal_amount")
+			},
+		},
+		{
+			name: "InvalidArguments",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account1.ID,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireFieldViolations(t, err, "to_account_id", "currency", "amount")
+			},
+		},
+		{
+			name: "FromAccountNotFound",
+			req:  transferRequest(account1, account2),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, err, codes.NotFound)
+			},
+		},
+		{
+			name: "FromAccountNotOwned",
+			req:  transferRequest(account2, account1),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, err, codes.PermissionDenied)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId:   account2.ID,
+				Currency:      "EUR",
+				Value:         &pb.CreateTransferRequest_Amount{Amount: amount},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireFieldViolations(t, err, "currency")
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req:  transferRequest(account1, account2),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.TransferTxResult{}, fmt.Errorf("account [%d]: %w", account1.ID, db.ErrInsufficientFunds))
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, err, codes.FailedPrecondition)
+			},
+		},
+		{
+			name: "CrossCurrencyWithoutRate",
+			req:  transferRequest(account1, account3),
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().GetExchangeRate(gomock.Any(), gomock.Any()).AnyTimes().Return(db.ExchangeRate{}, sql.ErrNoRows)
+				store.EXPECT().CrossCurrencyTransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, err, codes.FailedPrecondition)
+			},
+		},
+		{
+			name: "HeldForReview",
+			req:  transferRequest(account1, account2),
+			screener: stubScreener{decision: fraud.Decision{
+				Action:  fraud.ActionReview,
+				Reasons: []fraud.Reason{{Rule: "large_amount", Action: fraud.ActionReview, Message: "amount is large"}},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreatePendingTransferTxParams) (db.PendingTransfer, error) {
+						require.Nil(t, arg.Idempotency)
+						require.Equal(t, user1.Username, arg.RequestedBy)
+						require.Equal(t, amount, arg.Amount)
+						return db.PendingTransfer{ID: 7}, nil
+					})
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, int64(7), rsp.GetPendingTransferId())
+				require.Nil(t, rsp.GetTransfer())
+			},
+		},
+		{
+			name: "Blocked",
+			req:  transferRequest(account1, account2),
+			screener: stubScreener{decision: fraud.Decision{
+				Action:  fraud.ActionBlock,
+				Reasons: []fraud.Reason{{Rule: "blocked_account", Action: fraud.ActionBlock, Message: "account is blocked"}},
+			}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().CreatePendingTransferTx(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, rsp *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, err, codes.FailedPrecondition)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUser(store)
+			stubCurrencies(store)
+			server := newTestServer(t, store)
+			if tc.screener != nil {
+				server.screener = tc.screener
+			}
+
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user1.Username, time.Minute)
+			rsp, err := server.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, rsp, err)
+		})
+	}
+}
+
+func TestListTransfersRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	transfers := []db.Transfer{
+		{ID: 1, FromAccountID: account.ID, ToAccountID: account.ID + 1, Amount: 10},
+		{ID: 2, FromAccountID: account.ID + 1, ToAccountID: account.ID, Amount: 5},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListTransfersForAccount(gomock.Any(), gomock.Eq(db.ListTransfersForAccountParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    0,
+	})).Times(1).Return(transfers, nil)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+	rsp, err := server.ListTransfers(ctx, &pb.ListTransfersRequest{AccountId: account.ID, PageId: 1, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetTransfers(), 2)
+	require.Equal(t, transfers[1].ID, rsp.GetTransfers()[1].GetId())
+
+	// page sizes follow the HTTP API, which lists at least 5 transfers
+	_, err = server.ListTransfers(ctx, &pb.ListTransfersRequest{AccountId: account.ID, PageId: 1, PageSize: 2})
+	requireFieldViolations(t, err, "page_size")
+}
+
+func TestListEntriesRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	other, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	entries := []db.Entry{
+		{ID: 1, AccountID: account.ID, Amount: 10, TransferID: sql.NullInt64{Int64: 3, Valid: true}},
+		{ID: 2, AccountID: account.ID, Amount: -5},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+	store.EXPECT().ListEntriesForAccount(gomock.Any(), gomock.Eq(db.ListEntriesForAccountParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    5,
+	})).Times(1).Return(entries, nil)
+	stubAuthUser(store)
+	server := newTestServer(t, store)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+	rsp, err := server.ListEntries(ctx, &pb.ListEntriesRequest{AccountId: account.ID, PageId: 2, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, rsp.GetEntries(), 2)
+	require.Equal(t, int64(3), rsp.GetEntries()[0].GetTransferId())
+	require.Zero(t, rsp.GetEntries()[1].GetTransferId())
+
+	ctx = newContextWithBearerToken(t, server.tokenMaker, other.Username, time.Minute)
+	_, err = server.ListEntries(ctx, &pb.ListEntriesRequest{AccountId: account.ID, PageId: 1, PageSize: 5})
+	requireStatusCode(t, err, codes.PermissionDenied)
+}
@@ -0,0 +1,82 @@
+package gapi
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/go-playground/validator/v10"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/exchange"
+	"github.com/mrityunjaygr8/simplebank/fraud"
+	"github.com/mrityunjaygr8/simplebank/pb"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server serves the SimpleBank gRPC service from the same store, token maker, exchange rates and fraud
+// rules as the HTTP server
+type Server struct {
+	pb.UnimplementedSimpleBankServer
+	config     utils.Config
+	store      db.Store
+	tokenMaker token.Maker
+	screener   fraud.Screener
+	currencies *exchange.CurrencyRegistry
+	converter  *exchange.Converter
+	validate   *validator.Validate
+}
+
+func NewServer(config utils.Config, store db.Store) (*Server, error) {
+	tokenMaker, err := token.NewMaker(config.TokenType, config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	rates := exchange.NewDBRateProvider(store)
+	if config.ExchangeRatesFile != "" {
+		rates, err = exchange.NewFileRateProvider(config.ExchangeRatesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load exchange rates: %w", err)
+		}
+	}
+
+	var rules []fraud.Rule
+	if config.FraudRulesFile != "" {
+		rules, err = fraud.LoadRules(config.FraudRulesFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load fraud rules: %w", err)
+		}
+	}
+
+	currencies := exchange.NewCurrencyRegistry(store)
+	server := &Server{
+		config:     config,
+		store:      store,
+		tokenMaker: tokenMaker,
+		screener:   fraud.NewEngine(store, rules...),
+		currencies: currencies,
+		converter:  exchange.NewConverter(currencies, rates),
+		validate:   validator.New(),
+	}
+	return server, nil
+}
+
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("cannot listen on %s: %w", address, err)
+	}
+
+	return server.serve(listener)
+}
+
+func (server *Server) serve(listener net.Listener) error {
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(auditInterceptor))
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	// lets clients such as grpcurl discover the service without the proto files
+	reflection.Register(grpcServer)
+
+	return grpcServer.Serve(listener)
+}
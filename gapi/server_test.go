@@ -0,0 +1,78 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/pb"
+	"github.com/mrityunjaygr8/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialTestServer serves server over an in-memory connection and returns a client for it
+func dialTestServer(t *testing.T, server *Server) pb.SimpleBankClient {
+	listener := bufconn.Listen(1024 * 1024)
+	go server.serve(listener)
+	t.Cleanup(func() { listener.Close() })
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewSimpleBankClient(conn)
+}
+
+func TestServerAuditContext(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	stubAuthUser(store)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).
+		DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+			audit := db.AuditContextFrom(ctx)
+			require.Equal(t, user.Username, audit.Actor)
+			require.Equal(t, "request-1", audit.RequestID)
+			require.NotEmpty(t, audit.ClientIP)
+			return account, nil
+		})
+	server := newTestServer(t, store)
+	client := dialTestServer(t, server)
+
+	accessToken, _, err := server.tokenMaker.CreateToken(user.Username, token.TokenTypeAccess, time.Minute)
+	require.NoError(t, err)
+	ctx := metadata.AppendToOutgoingContext(context.Background(),
+		authorizationHeader, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken),
+		requestIDHeader, "request-1",
+	)
+
+	var header metadata.MD
+	rsp, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Equal(t, account.ID, rsp.GetAccount().GetId())
+	require.Equal(t, []string{"request-1"}, header.Get(requestIDHeader))
+
+	// without a token the call is refused before it reaches the store, and still gets a request id
+	_, err = client.GetAccount(context.Background(), &pb.GetAccountRequest{Id: account.ID}, grpc.Header(&header))
+	requireStatusCode(t, err, codes.Unauthenticated)
+	require.Len(t, header.Get(requestIDHeader), 1)
+}
@@ -0,0 +1,33 @@
+package gapi
+
+import "google.golang.org/genproto/googleapis/rpc/errdetails"
+
+// validation collects the fields of a request that break the rules of the matching HTTP handler. The rules
+// use the same validator tags as the gin bindings.
+type validation struct {
+	server     *Server
+	violations []*errdetails.BadRequest_FieldViolation
+}
+
+func (server *Server) newValidation() *validation {
+	return &validation{server: server}
+}
+
+// check validates value against tag and records a violation of field if it fails
+func (v *validation) check(field string, value interface{}, tag string) {
+	if err := v.server.validate.Var(value, tag); err != nil {
+		v.violations = append(v.violations, fieldViolation(field, err))
+	}
+}
+
+func (v *validation) add(field string, err error) {
+	v.violations = append(v.violations, fieldViolation(field, err))
+}
+
+// err is an InvalidArgument status listing the violations, or nil when there are none
+func (v *validation) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return invalidArgumentError(v.violations)
+}
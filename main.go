@@ -1,14 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"flag"
 	"log"
+	"os"
+	"time"
 
 	_ "github.com/lib/pq"
 
 	"github.com/mrityunjaygr8/simplebank/api"
 	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/gapi"
+	"github.com/mrityunjaygr8/simplebank/outbox"
 	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/mrityunjaygr8/simplebank/worker"
 )
 
 func main() {
@@ -23,10 +31,147 @@ func main() {
 	}
 
 	store := db.NewStore(conn)
-	server := api.NewServer(store)
+
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		os.Exit(reconcile(store, os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		os.Exit(audit(store, os.Args[2:]))
+	}
+
+	scheduler := worker.NewScheduler(store, config)
+	go scheduler.Start(context.Background())
+
+	holdExpirer := worker.NewHoldExpirer(store, config)
+	go holdExpirer.Start(context.Background())
+
+	balanceSnapshotter := worker.NewBalanceSnapshotter(store, config)
+	go balanceSnapshotter.Start(context.Background())
+
+	webhookDispatcher := worker.NewWebhookDispatcher(store, config)
+	go webhookDispatcher.Start(context.Background())
+
+	// without a publisher events stay in the outbox until one is configured
+	if config.OutboxPublisher != "" {
+		publisher, err := outbox.NewPublisher(config.OutboxPublisher, config.OutboxTarget)
+		if err != nil {
+			log.Fatal("Could not create outbox publisher: ", err)
+		}
+		outboxRelay := worker.NewOutboxRelay(store, publisher, config)
+		go outboxRelay.Start(context.Background())
+	}
+
+	// the gRPC server runs next to the HTTP one when it has an address
+	if config.GRPCServerAddress != "" {
+		grpcServer, err := gapi.NewServer(config, store)
+		if err != nil {
+			log.Fatal("Could not create gRPC server: ", err)
+		}
+		go func() {
+			if err := grpcServer.Start(config.GRPCServerAddress); err != nil {
+				log.Fatal("error starting gRPC server", err)
+			}
+		}()
+	}
+
+	server, err := api.NewServer(config, store)
+	if err != nil {
+		log.Fatal("Could not create server: ", err)
+	}
 
 	err = server.Start(config.ServerAddress)
 	if err != nil {
 		log.Fatal("error starting server", err)
 	}
 }
+
+// reconcile runs a ledger reconciliation and prints the report as JSON. The exit status is 1
+// when the report has discrepancies and 2 when the reconciliation could not run.
+func reconcile(store db.Store, args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+	full := flags.Bool("full", false, "check the whole ledger instead of continuing from the last run")
+	flags.Parse(args)
+
+	report, err := store.Reconcile(context.Background(), db.ReconcileParams{Full: *full})
+	if err != nil {
+		log.Print("cannot reconcile ledger: ", err)
+		return 2
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Print("cannot write report: ", err)
+		return 2
+	}
+
+	if report.Discrepancies > 0 {
+		return 1
+	}
+	return 0
+}
+
+// audit queries the audit log. "audit verify" checks the hash chain and exits with 1 when it is broken,
+// "audit list" prints the newest events matching its flags. Either exits with 2 when the query fails.
+func audit(store db.Store, args []string) int {
+	if len(args) == 0 || (args[0] != "verify" && args[0] != "list") {
+		log.Print("usage: audit verify [-batch-size n] | audit list [flags]")
+		return 2
+	}
+
+	var result interface{}
+	valid := true
+
+	switch args[0] {
+	case "verify":
+		flags := flag.NewFlagSet("audit verify", flag.ExitOnError)
+		batchSize := flags.Int("batch-size", 0, "events to read at a time, 0 for the default")
+		flags.Parse(args[1:])
+
+		verification, err := store.VerifyAuditChain(context.Background(), int32(*batchSize))
+		if err != nil {
+			log.Print("cannot verify audit log: ", err)
+			return 2
+		}
+		result, valid = verification, verification.Valid
+	case "list":
+		flags := flag.NewFlagSet("audit list", flag.ExitOnError)
+		actor := flags.String("actor", "", "only events of this actor")
+		action := flags.String("action", "", "only events with this action")
+		resourceType := flags.String("resource-type", "", "only events on this type of resource")
+		resourceID := flags.String("resource-id", "", "only events on this resource")
+		since := flags.Duration("since", 0, "only events from this long ago up to now")
+		limit := flags.Int("limit", 50, "how many events to print")
+		flags.Parse(args[1:])
+
+		arg := db.ListAuditEventsParams{
+			Limit:        int32(*limit),
+			Actor:        sql.NullString{String: *actor, Valid: *actor != ""},
+			Action:       sql.NullString{String: *action, Valid: *action != ""},
+			ResourceType: sql.NullString{String: *resourceType, Valid: *resourceType != ""},
+			ResourceID:   sql.NullString{String: *resourceID, Valid: *resourceID != ""},
+		}
+		if *since > 0 {
+			arg.StartTime = sql.NullTime{Time: time.Now().Add(-*since), Valid: true}
+		}
+
+		events, err := store.ListAuditEvents(context.Background(), arg)
+		if err != nil {
+			log.Print("cannot list audit events: ", err)
+			return 2
+		}
+		result = events
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Print("cannot write audit events: ", err)
+		return 2
+	}
+
+	if !valid {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,59 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const defaultHTTPPublishTimeout = 10 * time.Second
+
+// HTTPPublisher posts every message as JSON to a URL. Any 2xx response counts as delivered. The event id
+// and type are repeated in headers, so receivers can drop repeats without parsing the body.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(target string) (*HTTPPublisher, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("the http publisher needs an absolute http(s) URL, got %q", target)
+	}
+
+	return &HTTPPublisher{
+		url:    target,
+		client: &http.Client{Timeout: defaultHTTPPublishTimeout},
+	}, nil
+}
+
+func (publisher *HTTPPublisher) Publish(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, publisher.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Event-ID", strconv.FormatInt(message.ID, 10))
+	request.Header.Set("X-Event-Type", message.Type)
+
+	response, err := publisher.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("publishing event %d: unexpected status %s", message.ID, response.Status)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPPublisher(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, "3", r.Header.Get("X-Event-ID"))
+		require.Equal(t, "TransferCompleted", r.Header.Get("X-Event-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	publisher, err := NewHTTPPublisher(server.URL)
+	require.NoError(t, err)
+
+	message := randomMessage(3)
+	require.NoError(t, publisher.Publish(context.Background(), message))
+	require.Equal(t, message, received)
+}
+
+func TestHTTPPublisherErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	publisher, err := NewHTTPPublisher(server.URL)
+	require.NoError(t, err)
+
+	err = publisher.Publish(context.Background(), randomMessage(3))
+	require.ErrorContains(t, err, "503")
+}
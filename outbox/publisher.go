@@ -0,0 +1,65 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+)
+
+const (
+	PublisherStdout = "stdout"
+	PublisherFile   = "file"
+	PublisherHTTP   = "http"
+)
+
+// Message is an outbox event as downstream systems receive it. The same event may be delivered more than
+// once, consumers recognize repeats by the id.
+type Message struct {
+	ID        int64           `json:"id"`
+	Type      string          `json:"type"`
+	AccountID int64           `json:"account_id"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func NewMessage(event db.Outbox) Message {
+	return Message{
+		ID:        event.ID,
+		Type:      event.EventType,
+		AccountID: event.AccountID,
+		Payload:   event.Payload,
+		CreatedAt: event.CreatedAt,
+	}
+}
+
+// Publisher delivers messages to downstream systems. A nil error means the message was delivered, it is
+// not published again after that.
+type Publisher interface {
+	Publish(ctx context.Context, message Message) error
+}
+
+// NewPublisher creates the publisher of the given kind. target is the path of the file publisher and the
+// URL of the HTTP publisher, the stdout publisher has none.
+func NewPublisher(kind, target string) (Publisher, error) {
+	switch kind {
+	case PublisherStdout:
+		return NewStdoutPublisher(), nil
+	case PublisherFile:
+		publisher, err := NewFilePublisher(target)
+		if err != nil {
+			return nil, err
+		}
+		return publisher, nil
+	case PublisherHTTP:
+		publisher, err := NewHTTPPublisher(target)
+		if err != nil {
+			return nil, err
+		}
+		return publisher, nil
+	default:
+		return nil, fmt.Errorf("unknown outbox publisher %q", kind)
+	}
+}
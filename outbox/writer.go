@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// WriterPublisher writes every message as a line of JSON
+type WriterPublisher struct {
+	mu     sync.Mutex
+	writer io.Writer
+	// sync, when set, flushes each line to stable storage before the message counts as delivered
+	sync func() error
+}
+
+func NewWriterPublisher(writer io.Writer) *WriterPublisher {
+	return &WriterPublisher{writer: writer}
+}
+
+func NewStdoutPublisher() *WriterPublisher {
+	return NewWriterPublisher(os.Stdout)
+}
+
+// NewFilePublisher appends messages to the file at path, creating it when it does not exist
+func NewFilePublisher(path string) (*WriterPublisher, error) {
+	if path == "" {
+		return nil, errors.New("the file publisher needs a path")
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open outbox file: %w", err)
+	}
+
+	publisher := NewWriterPublisher(file)
+	publisher.sync = file.Sync
+	return publisher, nil
+}
+
+func (publisher *WriterPublisher) Publish(ctx context.Context, message Message) error {
+	line, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	if _, err := publisher.writer.Write(line); err != nil {
+		return err
+	}
+	if publisher.sync != nil {
+		return publisher.sync()
+	}
+	return nil
+}
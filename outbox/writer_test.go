@@ -0,0 +1,77 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func randomMessage(id int64) Message {
+	return Message{
+		ID:        id,
+		Type:      "TransferCompleted",
+		AccountID: 7,
+		Payload:   json.RawMessage(`{"id":1,"amount":10}`),
+		CreatedAt: time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func readMessages(t *testing.T, data []byte) []Message {
+	var messages []Message
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var message Message
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &message))
+		messages = append(messages, message)
+	}
+	require.NoError(t, scanner.Err())
+	return messages
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buffer bytes.Buffer
+	publisher := NewWriterPublisher(&buffer)
+
+	messages := []Message{randomMessage(1), randomMessage(2)}
+	for _, message := range messages {
+		require.NoError(t, publisher.Publish(context.Background(), message))
+	}
+
+	require.Equal(t, messages, readMessages(t, buffer.Bytes()))
+}
+
+func TestFilePublisherAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+
+	for _, id := range []int64{1, 2} {
+		publisher, err := NewFilePublisher(path)
+		require.NoError(t, err)
+		require.NoError(t, publisher.Publish(context.Background(), randomMessage(id)))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []Message{randomMessage(1), randomMessage(2)}, readMessages(t, data))
+}
+
+func TestNewPublisher(t *testing.T) {
+	publisher, err := NewPublisher(PublisherStdout, "")
+	require.NoError(t, err)
+	require.IsType(t, &WriterPublisher{}, publisher)
+
+	_, err = NewPublisher(PublisherFile, "")
+	require.Error(t, err)
+
+	_, err = NewPublisher(PublisherHTTP, "not a url")
+	require.Error(t, err)
+
+	_, err = NewPublisher("kafka", "")
+	require.Error(t, err)
+}
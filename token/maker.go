@@ -0,0 +1,32 @@
+package token
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	MakerPaseto = "paseto"
+	MakerJWT    = "jwt"
+)
+
+// Maker is an interface for managing tokens
+type Maker interface {
+	// CreateToken creates a new token of the given type for a specific username and duration
+	CreateToken(username string, tokenType TokenType, duration time.Duration) (string, *Payload, error)
+
+	// VerifyToken checks if the token is valid and of the given type or not
+	VerifyToken(token string, tokenType TokenType) (*Payload, error)
+}
+
+// NewMaker creates the token maker of the given kind with the symmetric key. An empty kind means paseto.
+func NewMaker(kind, symmetricKey string) (Maker, error) {
+	switch kind {
+	case "", MakerPaseto:
+		return NewPasetoMaker(symmetricKey)
+	case MakerJWT:
+		return NewJWTMaker(symmetricKey)
+	default:
+		return nil, fmt.Errorf("unknown token maker %q", kind)
+	}
+}
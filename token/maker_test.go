@@ -0,0 +1,28 @@
+package token
+
+import (
+	"testing"
+
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewMaker(t *testing.T) {
+	key := utils.RandomString(32)
+
+	maker, err := NewMaker(MakerPaseto, key)
+	require.NoError(t, err)
+	require.IsType(t, &PasetoMaker{}, maker)
+
+	maker, err = NewMaker("", key)
+	require.NoError(t, err)
+	require.IsType(t, &PasetoMaker{}, maker)
+
+	maker, err = NewMaker(MakerJWT, key)
+	require.NoError(t, err)
+	require.IsType(t, &JWTMaker{}, maker)
+
+	maker, err = NewMaker("unknown", key)
+	require.EqualError(t, err, `unknown token maker "unknown"`)
+	require.Nil(t, maker)
+}
@@ -1,13 +1,34 @@
 package utils
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	DBDriver      string `mapstructure:"SB_DB_DRIVER"`
-	DBSource      string `mapstructure:"SB_DB_SOURCE"`
-	ServerAddress string `mapstructure:"SB_SERVER_ADDRESS"`
+	DBDriver                string        `mapstructure:"SB_DB_DRIVER"`
+	DBSource                string        `mapstructure:"SB_DB_SOURCE"`
+	ServerAddress           string        `mapstructure:"SB_SERVER_ADDRESS"`
+	GRPCServerAddress       string        `mapstructure:"SB_GRPC_SERVER_ADDRESS"`
+	TokenType               string        `mapstructure:"SB_TOKEN_TYPE"`
+	TokenSymmetricKey       string        `mapstructure:"SB_TOKEN_SYMMETRIC_KEY"`
+	AccessTokenDuration     time.Duration `mapstructure:"SB_ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration    time.Duration `mapstructure:"SB_REFRESH_TOKEN_DURATION"`
+	ExchangeRatesFile       string        `mapstructure:"SB_EXCHANGE_RATES_FILE"`
+	FraudRulesFile          string        `mapstructure:"SB_FRAUD_RULES_FILE"`
+	SchedulerInterval       time.Duration `mapstructure:"SB_SCHEDULER_INTERVAL"`
+	SchedulerBatchSize      int32         `mapstructure:"SB_SCHEDULER_BATCH_SIZE"`
+	SchedulerMaxFailures    int32         `mapstructure:"SB_SCHEDULER_MAX_FAILURES"`
+	SchedulerRetryDelay     time.Duration `mapstructure:"SB_SCHEDULER_RETRY_DELAY"`
+	HoldExpiryInterval      time.Duration `mapstructure:"SB_HOLD_EXPIRY_INTERVAL"`
+	BalanceSnapshotInterval time.Duration `mapstructure:"SB_BALANCE_SNAPSHOT_INTERVAL"`
+	OutboxPublisher         string        `mapstructure:"SB_OUTBOX_PUBLISHER"`
+	OutboxTarget            string        `mapstructure:"SB_OUTBOX_TARGET"`
+	OutboxRelayInterval     time.Duration `mapstructure:"SB_OUTBOX_RELAY_INTERVAL"`
+	WebhookInterval         time.Duration `mapstructure:"SB_WEBHOOK_INTERVAL"`
+	WebhookMaxAttempts      int32         `mapstructure:"SB_WEBHOOK_MAX_ATTEMPTS"`
+	WebhookRetryDelay       time.Duration `mapstructure:"SB_WEBHOOK_RETRY_DELAY"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
@@ -1,12 +1,37 @@
 package utils
 
-var validCurrency = map[string]bool{
-	"USD": true,
-	"CAD": true,
-	"EUR": true,
-}
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidAmount   = errors.New("amount must be a positive decimal number")
+	ErrAmountPrecision = errors.New("amount has more decimal places than the currency allows")
+)
+
+var decimalAmount = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+// ParseAmount converts a decimal amount such as "12.34" into minor units of a currency
+// with the given ISO 4217 exponent. Amounts that need more decimal places than the currency has are rejected.
+func ParseAmount(amount string, minorUnits int32) (int64, error) {
+	if !decimalAmount.MatchString(amount) {
+		return 0, ErrInvalidAmount
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > int(minorUnits) {
+		return 0, fmt.Errorf("%w: %s allows %d", ErrAmountPrecision, amount, minorUnits)
+	}
+	fraction += strings.Repeat("0", int(minorUnits)-len(fraction))
 
-func IsSupportedCurrency(currency string) bool {
-	ok, _ := validCurrency[currency]
-	return ok
+	value, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil || value <= 0 {
+		return 0, ErrInvalidAmount
+	}
+	return value, nil
 }
@@ -28,9 +28,10 @@ func RandomString(n int) string {
 	return sb.String()
 }
 
-func RandomCurrency() string {
-	curs := []string{"USD", "EUR", "CAD"}
-	return curs[rand.Intn(len(curs))]
+// RandomCurrency returns one of the given currency codes. Callers pass the codes of the currency registry,
+// so tests only open accounts in currencies that exist.
+func RandomCurrency(codes []string) string {
+	return codes[rand.Intn(len(codes))]
 }
 
 func RandomOwner() string {
@@ -40,3 +41,7 @@ func RandomOwner() string {
 func RandomMoney() int64 {
 	return RandomInt(10, 1000)
 }
+
+func RandomEmail() string {
+	return RandomString(6) + "@email.com"
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// minScheduleInterval matches the resolution of a cron expression
+const minScheduleInterval = time.Minute
+
+// ParseSchedule parses a five field cron expression, a descriptor such as @daily,
+// or an interval such as @every 24h
+func ParseSchedule(spec string) (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+
+	if interval, ok := schedule.(cron.ConstantDelaySchedule); ok && interval.Delay < minScheduleInterval {
+		return nil, fmt.Errorf("invalid schedule %q: interval must be at least %s", spec, minScheduleInterval)
+	}
+	return schedule, nil
+}
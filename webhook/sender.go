@@ -0,0 +1,108 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/mrityunjaygr8/simplebank/outbox"
+)
+
+const defaultSendTimeout = 10 * time.Second
+
+var ErrInternalAddress = errors.New("webhook deliveries may not connect to internal addresses")
+
+// Sender posts signed events to webhook subscribers
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a sender that only connects to public addresses. The address is checked after the host
+// name has been resolved, so a name pointing at the bank's own network is refused on every delivery. Redirects
+// are not followed and no proxy is used, either would connect somewhere other than the checked address.
+func NewSender() *Sender {
+	dialer := &net.Dialer{
+		Timeout: defaultSendTimeout,
+		Control: refuseInternalAddress,
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return NewSenderWithClient(&http.Client{
+		Timeout:   defaultSendTimeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	})
+}
+
+// NewSenderWithClient returns a sender that uses the connection and redirect policy of client. It is meant
+// for receivers the caller trusts, subscriber URLs go through NewSender.
+func NewSenderWithClient(client *http.Client) *Sender {
+	return &Sender{
+		client: client,
+		now:    time.Now,
+	}
+}
+
+// PublicIP reports whether deliveries may connect to ip. Loopback, link-local, private and unspecified
+// addresses could reach services on the bank's own network.
+func PublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsPrivate() && !ip.IsUnspecified()
+}
+
+// refuseInternalAddress is the dialer control that keeps deliveries off addresses PublicIP refuses
+func refuseInternalAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrInternalAddress, host)
+	}
+	return nil
+}
+
+// Send posts the event as JSON to url and returns the status code of the response, zero when there was
+// none. Any status outside 2xx is an error.
+func (sender *Sender) Send(ctx context.Context, url, secret string, deliveryID int64, message outbox.Message) (int, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := sender.now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderDeliveryID, strconv.FormatInt(deliveryID, 10))
+	request.Header.Set(HeaderEventID, strconv.FormatInt(message.ID, 10))
+	request.Header.Set(HeaderEventType, message.Type)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	response, err := sender.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+	return response.StatusCode, nil
+}
@@ -0,0 +1,116 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mrityunjaygr8/simplebank/outbox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSenderSend(t *testing.T) {
+	secret := "0123456789abcdef"
+	message := outbox.Message{
+		ID:        12,
+		Type:      "TransferCompleted",
+		AccountID: 7,
+		Payload:   json.RawMessage(`{"amount":10}`),
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	var received outbox.Message
+	var header http.Header
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if err := Verify(secret, r.Header, body, DefaultTolerance, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		header = r.Header
+		require.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := NewSenderWithClient(receiver.Client())
+	statusCode, err := sender.Send(context.Background(), receiver.URL, secret, 3, message)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, statusCode)
+	require.Equal(t, message, received)
+	require.Equal(t, "3", header.Get(HeaderDeliveryID))
+	require.Equal(t, strconv.FormatInt(message.ID, 10), header.Get(HeaderEventID))
+	require.Equal(t, message.Type, header.Get(HeaderEventType))
+
+	// the receiver rejects a delivery signed with another secret
+	statusCode, err = sender.Send(context.Background(), receiver.URL, "fedcba9876543210", 3, message)
+	require.Error(t, err)
+	require.Equal(t, http.StatusUnauthorized, statusCode)
+}
+
+func TestSenderSendUnreachable(t *testing.T) {
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	statusCode, err := NewSenderWithClient(receiver.Client()).Send(context.Background(), receiver.URL, "secret", 1, outbox.Message{})
+	require.Error(t, err)
+	require.Zero(t, statusCode)
+}
+
+func TestSenderSendDoesNotFollowRedirects(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer receiver.Close()
+
+	// the test servers listen on loopback, which NewSender refuses to dial
+	sender := NewSender()
+	sender.client.Transport = receiver.Client().Transport
+
+	statusCode, err := sender.Send(context.Background(), receiver.URL, "secret", 1, outbox.Message{})
+	require.Error(t, err)
+	require.Equal(t, http.StatusTemporaryRedirect, statusCode)
+	require.False(t, redirected)
+}
+
+func TestSenderSendRefusesInternalAddresses(t *testing.T) {
+	var received bool
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = true
+	}))
+	defer receiver.Close()
+
+	receiverURL, err := url.Parse(receiver.URL)
+	require.NoError(t, err)
+
+	// localhost passes as a host name and is only refused once it has been resolved to loopback
+	target := fmt.Sprintf("http://localhost:%s/hooks", receiverURL.Port())
+	statusCode, err := NewSender().Send(context.Background(), target, "secret", 1, outbox.Message{})
+	require.ErrorIs(t, err, ErrInternalAddress)
+	require.Zero(t, statusCode)
+	require.False(t, received)
+}
+
+func TestPublicIP(t *testing.T) {
+	require.True(t, PublicIP(net.ParseIP("93.184.216.34")))
+	require.False(t, PublicIP(net.ParseIP("127.0.0.1")))
+	require.False(t, PublicIP(net.ParseIP("::ffff:127.0.0.1")))
+	require.False(t, PublicIP(net.ParseIP("169.254.169.254")))
+	require.False(t, PublicIP(net.ParseIP("10.1.2.3")))
+	require.False(t, PublicIP(net.ParseIP("0.0.0.0")))
+}
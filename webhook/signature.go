@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	HeaderDeliveryID = "X-Webhook-Delivery-ID"
+	HeaderEventID    = "X-Webhook-Event-ID"
+	HeaderEventType  = "X-Webhook-Event-Type"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+)
+
+// DefaultTolerance is how far the timestamp of a delivery may be from the receiver's clock
+const DefaultTolerance = 5 * time.Minute
+
+const signaturePrefix = "v1="
+
+var (
+	ErrMissingSignature = errors.New("webhook signature or timestamp is missing")
+	ErrInvalidSignature = errors.New("webhook signature does not match")
+	ErrStaleTimestamp   = errors.New("webhook timestamp is outside the tolerance")
+)
+
+// Sign returns the signature of a delivery body: an HMAC-SHA256 keyed with the subscription secret over the
+// timestamp in unix seconds, a dot and the body. Covering the timestamp keeps a captured delivery from being
+// replayed later with a fresh one.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature headers of a delivery the way a receiver should: the signature has to match
+// and the timestamp has to be within tolerance of now. Receivers drop repeated deliveries by event id.
+func Verify(secret string, header http.Header, body []byte, tolerance time.Duration, now time.Time) error {
+	signature := header.Get(HeaderSignature)
+	timestamp, err := strconv.ParseInt(header.Get(HeaderTimestamp), 10, 64)
+	if signature == "" || err != nil {
+		return ErrMissingSignature
+	}
+
+	age := now.Sub(time.Unix(timestamp, 0))
+	if age > tolerance || age < -tolerance {
+		return ErrStaleTimestamp
+	}
+
+	if !strings.HasPrefix(signature, signaturePrefix) ||
+		!hmac.Equal([]byte(signature), []byte(Sign(secret, timestamp, body))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func signedHeader(secret string, timestamp int64, body []byte) http.Header {
+	header := http.Header{}
+	header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	header.Set(HeaderSignature, Sign(secret, timestamp, body))
+	return header
+}
+
+func TestVerify(t *testing.T) {
+	secret := "0123456789abcdef"
+	body := []byte(`{"id":1}`)
+	now := time.Unix(1700000000, 0)
+
+	testCases := []struct {
+		name   string
+		header http.Header
+		body   []byte
+		err    error
+	}{
+		{
+			name:   "OK",
+			header: signedHeader(secret, now.Unix(), body),
+			body:   body,
+		},
+		{
+			name:   "WithinTolerance",
+			header: signedHeader(secret, now.Add(-4*time.Minute).Unix(), body),
+			body:   body,
+		},
+		{
+			name:   "Missing",
+			header: http.Header{},
+			body:   body,
+			err:    ErrMissingSignature,
+		},
+		{
+			name:   "TamperedBody",
+			header: signedHeader(secret, now.Unix(), body),
+			body:   []byte(`{"id":2}`),
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "WrongSecret",
+			header: signedHeader("fedcba9876543210", now.Unix(), body),
+			body:   body,
+			err:    ErrInvalidSignature,
+		},
+		{
+			name:   "Replayed",
+			header: signedHeader(secret, now.Add(-10*time.Minute).Unix(), body),
+			body:   body,
+			err:    ErrStaleTimestamp,
+		},
+		{
+			name:   "FromTheFuture",
+			header: signedHeader(secret, now.Add(10*time.Minute).Unix(), body),
+			body:   body,
+			err:    ErrStaleTimestamp,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(secret, tc.header, tc.body, DefaultTolerance, now)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestSignCoversTimestamp(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	require.NotEqual(t, Sign("secret", 1, body), Sign("secret", 2, body))
+	require.Equal(t, Sign("secret", 1, body), Sign("secret", 1, body))
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+)
+
+const (
+	defaultBalanceSnapshotInterval = 24 * time.Hour
+	// balanceSnapshotSettleTime keeps snapshots clear of transactions still in flight, whose entries
+	// could otherwise commit with a created_at the snapshot already covers
+	balanceSnapshotSettleTime = 5 * time.Minute
+)
+
+// BalanceSnapshotter periodically records the balance of every account, so point in time balances
+// only need to add up the entries after the closest snapshot
+type BalanceSnapshotter struct {
+	store    db.Store
+	interval time.Duration
+	now      func() time.Time
+}
+
+func NewBalanceSnapshotter(store db.Store, config utils.Config) *BalanceSnapshotter {
+	snapshotter := &BalanceSnapshotter{
+		store:    store,
+		interval: config.BalanceSnapshotInterval,
+		now:      time.Now,
+	}
+
+	if snapshotter.interval <= 0 {
+		snapshotter.interval = defaultBalanceSnapshotInterval
+	}
+	return snapshotter
+}
+
+// Start takes a snapshot every interval until the context is cancelled
+func (snapshotter *BalanceSnapshotter) Start(ctx context.Context) {
+	ticker := time.NewTicker(snapshotter.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := snapshotter.Snapshot(ctx); err != nil {
+			log.Printf("cannot snapshot balances: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Snapshot records the balance of every account as of a few minutes ago
+func (snapshotter *BalanceSnapshotter) Snapshot(ctx context.Context) error {
+	_, err := snapshotter.store.CreateBalanceSnapshots(ctx, snapshotter.now().Add(-balanceSnapshotSettleTime))
+	return err
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBalanceSnapshotterSnapshot(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	store := mockdb.NewMockStore(ctrl)
+	snapshotter := NewBalanceSnapshotter(store, utils.Config{})
+	snapshotter.now = func() time.Time { return now }
+
+	store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Eq(now.Add(-balanceSnapshotSettleTime))).Times(1).Return(int64(3), nil)
+
+	require.NoError(t, snapshotter.Snapshot(context.Background()))
+	require.Equal(t, defaultBalanceSnapshotInterval, snapshotter.interval)
+}
+
+func TestBalanceSnapshotterError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().CreateBalanceSnapshots(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+
+	err := NewBalanceSnapshotter(store, utils.Config{}).Snapshot(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
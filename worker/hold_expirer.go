@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+)
+
+const (
+	defaultHoldExpiryInterval  = time.Minute
+	defaultHoldExpiryBatchSize = 100
+)
+
+// HoldExpirer releases the funds of holds that were neither captured nor released before they expired
+type HoldExpirer struct {
+	store     db.Store
+	interval  time.Duration
+	batchSize int32
+	now       func() time.Time
+}
+
+func NewHoldExpirer(store db.Store, config utils.Config) *HoldExpirer {
+	expirer := &HoldExpirer{
+		store:     store,
+		interval:  config.HoldExpiryInterval,
+		batchSize: defaultHoldExpiryBatchSize,
+		now:       time.Now,
+	}
+
+	if expirer.interval <= 0 {
+		expirer.interval = defaultHoldExpiryInterval
+	}
+	return expirer
+}
+
+// Start expires holds every interval until the context is cancelled
+func (expirer *HoldExpirer) Start(ctx context.Context) {
+	ticker := time.NewTicker(expirer.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := expirer.ExpireDue(ctx); err != nil {
+			log.Printf("cannot expire holds: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ExpireDue expires every hold that is past its expiry, one batch at a time
+func (expirer *HoldExpirer) ExpireDue(ctx context.Context) error {
+	for {
+		expired, err := expirer.store.ExpireHolds(ctx, db.ExpireHoldsParams{
+			Now:   expirer.now(),
+			Limit: expirer.batchSize,
+		})
+		if err != nil {
+			return err
+		}
+		if len(expired) < int(expirer.batchSize) {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldExpirerExpireDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	store := mockdb.NewMockStore(ctrl)
+	expirer := NewHoldExpirer(store, utils.Config{})
+	expirer.now = func() time.Time { return now }
+
+	arg := db.ExpireHoldsParams{Now: now, Limit: expirer.batchSize}
+	gomock.InOrder(
+		store.EXPECT().ExpireHolds(gomock.Any(), gomock.Eq(arg)).Times(1).Return(make([]db.Hold, expirer.batchSize), nil),
+		store.EXPECT().ExpireHolds(gomock.Any(), gomock.Eq(arg)).Times(1).Return(make([]db.Hold, 1), nil),
+	)
+
+	require.NoError(t, expirer.ExpireDue(context.Background()))
+}
+
+func TestHoldExpirerError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ExpireHolds(gomock.Any(), gomock.Any()).Times(1).Return(nil, sql.ErrConnDone)
+
+	err := NewHoldExpirer(store, utils.Config{}).ExpireDue(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/outbox"
+	"github.com/mrityunjaygr8/simplebank/utils"
+)
+
+const (
+	defaultOutboxRelayInterval  = 5 * time.Second
+	defaultOutboxRelayBatchSize = 100
+)
+
+// OutboxRelay publishes the events written to the outbox. Events that cannot be published are retried on
+// the next run.
+type OutboxRelay struct {
+	store     db.Store
+	publisher outbox.Publisher
+	interval  time.Duration
+	batchSize int32
+}
+
+func NewOutboxRelay(store db.Store, publisher outbox.Publisher, config utils.Config) *OutboxRelay {
+	relay := &OutboxRelay{
+		store:     store,
+		publisher: publisher,
+		interval:  config.OutboxRelayInterval,
+		batchSize: defaultOutboxRelayBatchSize,
+	}
+
+	if relay.interval <= 0 {
+		relay.interval = defaultOutboxRelayInterval
+	}
+	return relay
+}
+
+// Start relays events every interval until the context is cancelled
+func (relay *OutboxRelay) Start(ctx context.Context) {
+	ticker := time.NewTicker(relay.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := relay.RelayPending(ctx); err != nil {
+			log.Printf("cannot relay outbox events: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending publishes unpublished events one batch at a time. It stops at the first batch that was not
+// published in full, whatever is left waits for the next run.
+func (relay *OutboxRelay) RelayPending(ctx context.Context) error {
+	for {
+		result, err := relay.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Limit:   relay.batchSize,
+			Publish: relay.publish,
+		})
+		if err != nil {
+			return err
+		}
+		if result.Failed > 0 {
+			log.Printf("cannot publish %d outbox events, holding back %d", result.Failed, result.HeldBack)
+		}
+		if result.Published < int(relay.batchSize) {
+			return nil
+		}
+	}
+}
+
+func (relay *OutboxRelay) publish(ctx context.Context, event db.Outbox) error {
+	return relay.publisher.Publish(ctx, outbox.NewMessage(event))
+}
@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/outbox"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingPublisher struct {
+	messages []outbox.Message
+	err      error
+}
+
+func (publisher *recordingPublisher) Publish(ctx context.Context, message outbox.Message) error {
+	if publisher.err != nil {
+		return publisher.err
+	}
+	publisher.messages = append(publisher.messages, message)
+	return nil
+}
+
+// relayEvents stubs RelayOutboxTx by handing the events to the relay's publish function
+func relayEvents(events []db.Outbox) func(ctx context.Context, arg db.RelayOutboxTxParams) (db.RelayOutboxTxResult, error) {
+	return func(ctx context.Context, arg db.RelayOutboxTxParams) (db.RelayOutboxTxResult, error) {
+		var result db.RelayOutboxTxResult
+		for _, event := range events {
+			if err := arg.Publish(ctx, event); err != nil {
+				result.Failed++
+				continue
+			}
+			result.Published++
+		}
+		return result, nil
+	}
+}
+
+func TestOutboxRelayRelayPending(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	publisher := &recordingPublisher{}
+	relay := NewOutboxRelay(store, publisher, utils.Config{})
+	relay.batchSize = 2
+
+	events := []db.Outbox{
+		{ID: 1, AccountID: 7, EventType: db.EventTransferCompleted},
+		{ID: 2, AccountID: 7, EventType: db.EventEntryPosted},
+		{ID: 3, AccountID: 8, EventType: db.EventEntryPosted},
+	}
+	gomock.InOrder(
+		store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayEvents(events[:2])),
+		store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayEvents(events[2:])),
+	)
+
+	require.NoError(t, relay.RelayPending(context.Background()))
+	require.Len(t, publisher.messages, 3)
+	for i, message := range publisher.messages {
+		require.Equal(t, outbox.NewMessage(events[i]), message)
+	}
+}
+
+func TestOutboxRelayPublishFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	publisher := &recordingPublisher{err: errors.New("connection refused")}
+	relay := NewOutboxRelay(store, publisher, utils.Config{})
+	relay.batchSize = 2
+
+	events := []db.Outbox{{ID: 1, AccountID: 7}, {ID: 2, AccountID: 8}}
+	// a batch that was not published in full waits for the next run
+	store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(relayEvents(events))
+
+	require.NoError(t, relay.RelayPending(context.Background()))
+	require.Empty(t, publisher.messages)
+}
+
+func TestOutboxRelayError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().RelayOutboxTx(gomock.Any(), gomock.Any()).Times(1).Return(db.RelayOutboxTxResult{}, sql.ErrConnDone)
+
+	err := NewOutboxRelay(store, &recordingPublisher{}, utils.Config{}).RelayPending(context.Background())
+	require.ErrorIs(t, err, sql.ErrConnDone)
+}
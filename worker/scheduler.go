@@ -0,0 +1,219 @@
+package worker
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+)
+
+const (
+	defaultSchedulerInterval    = time.Minute
+	defaultSchedulerBatchSize   = 50
+	defaultSchedulerMaxFailures = 3
+	defaultSchedulerRetryDelay  = time.Hour
+	// defaultSchedulerLease outlasts running a whole batch of transfers one after the other
+	defaultSchedulerLease = 5 * time.Minute
+)
+
+// Scheduler executes scheduled transfers once they are due. Several schedulers may run against
+// the same database, a claimed scheduled transfer is leased to one of them until its run is recorded.
+type Scheduler struct {
+	store       db.Store
+	interval    time.Duration
+	batchSize   int32
+	maxFailures int32
+	retryDelay  time.Duration
+	lease       time.Duration
+	now         func() time.Time
+}
+
+func NewScheduler(store db.Store, config utils.Config) *Scheduler {
+	scheduler := &Scheduler{
+		store:       store,
+		interval:    config.SchedulerInterval,
+		batchSize:   config.SchedulerBatchSize,
+		maxFailures: config.SchedulerMaxFailures,
+		retryDelay:  config.SchedulerRetryDelay,
+		lease:       defaultSchedulerLease,
+		now:         time.Now,
+	}
+
+	if scheduler.interval <= 0 {
+		scheduler.interval = defaultSchedulerInterval
+	}
+	if scheduler.batchSize <= 0 {
+		scheduler.batchSize = defaultSchedulerBatchSize
+	}
+	if scheduler.maxFailures <= 0 {
+		scheduler.maxFailures = defaultSchedulerMaxFailures
+	}
+	if scheduler.retryDelay <= 0 {
+		scheduler.retryDelay = defaultSchedulerRetryDelay
+	}
+	return scheduler
+}
+
+// Start runs due scheduled transfers every interval until the context is cancelled
+func (scheduler *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(scheduler.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := scheduler.RunDue(ctx); err != nil {
+			log.Printf("cannot run scheduled transfers: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDue runs every scheduled transfer that is due, one batch at a time
+func (scheduler *Scheduler) RunDue(ctx context.Context) error {
+	for {
+		claim, err := scheduler.store.ClaimScheduledTransfersTx(ctx, db.ClaimScheduledTransfersTxParams{
+			Now:   scheduler.now(),
+			Limit: scheduler.batchSize,
+			Lease: scheduler.lease,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, scheduled := range claim.Due {
+			err := scheduler.run(ctx, scheduled, claim.LeasedUntil)
+			if errors.Is(err, sql.ErrNoRows) {
+				// the lease ran out and another scheduler recorded the run first, or the scheduled transfer changed
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(claim.Due) < int(scheduler.batchSize) {
+			return nil
+		}
+	}
+}
+
+// run executes one occurrence of a scheduled transfer and records it. Running out of funds or hitting a
+// frozen account is retried after retryDelay, and pauses the scheduled transfer after maxFailures attempts.
+func (scheduler *Scheduler) run(ctx context.Context, scheduled db.ScheduledTransfer, leasedUntil time.Time) error {
+	now := scheduler.now()
+	arg := db.RunScheduledTransferTxParams{
+		Scheduled:   scheduled,
+		LeasedUntil: leasedUntil,
+	}
+
+	schedule, err := utils.ParseSchedule(scheduled.Schedule)
+	if err != nil {
+		next := nextRun(scheduled)
+		next.Status = db.ScheduledTransferStatusPaused
+		arg.Outcome = db.ScheduledTransferOutcome{Error: err.Error(), Next: next}
+		_, err = scheduler.store.RunScheduledTransferTx(ctx, arg)
+		return err
+	}
+
+	succeeded := nextRun(scheduled)
+	succeeded.ConsecutiveFailures = 0
+	succeeded.NextRunAt = schedule.Next(now)
+	completeAfterEnd(&succeeded)
+
+	// the key is tied to this occurrence, so it is never paid twice
+	key := fmt.Sprintf("scheduled-transfer-%d-%d", scheduled.ID, scheduled.NextRunAt.UnixNano())
+	arg.Transfer = &db.TransferTxParams{
+		FromAccountID: scheduled.FromAccountID,
+		ToAccountID:   scheduled.ToAccountID,
+		Amount:        scheduled.Amount,
+		Idempotency: &db.IdempotencyParams{
+			Username:     scheduled.Owner,
+			Key:          key,
+			RequestHash:  hashKey(key),
+			ResponseCode: http.StatusCreated,
+		},
+	}
+	arg.Outcome = db.ScheduledTransferOutcome{Next: succeeded}
+	_, err = scheduler.store.RunScheduledTransferTx(ctx, arg)
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, db.ErrDuplicateIdempotencyKey):
+		// the occurrence was paid without its run being recorded, record it against the stored transfer
+		result, err := scheduler.storedTransfer(ctx, scheduled.Owner, key)
+		if err != nil {
+			return err
+		}
+		arg.Transfer = nil
+		arg.Outcome.TransferID = sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountNotActive), errors.Is(err, db.ErrTransferLimitExceeded):
+		failed := nextRun(scheduled)
+		failed.ConsecutiveFailures++
+		failed.NextRunAt = now.Add(scheduler.retryDelay)
+		if failed.ConsecutiveFailures >= scheduler.maxFailures {
+			failed.Status = db.ScheduledTransferStatusPaused
+		}
+		completeAfterEnd(&failed)
+		arg.Transfer = nil
+		arg.Outcome = db.ScheduledTransferOutcome{Error: err.Error(), Next: failed}
+	default:
+		return err
+	}
+
+	_, err = scheduler.store.RunScheduledTransferTx(ctx, arg)
+	return err
+}
+
+// nextRun starts the schedule update of a run from the scheduled transfer as it was claimed
+func nextRun(scheduled db.ScheduledTransfer) db.UpdateScheduledTransferParams {
+	return db.UpdateScheduledTransferParams{
+		ID:                  scheduled.ID,
+		Amount:              scheduled.Amount,
+		Schedule:            scheduled.Schedule,
+		NextRunAt:           scheduled.NextRunAt,
+		EndAt:               scheduled.EndAt,
+		Status:              scheduled.Status,
+		ConsecutiveFailures: scheduled.ConsecutiveFailures,
+	}
+}
+
+// storedTransfer returns the transfer an earlier attempt recorded under the idempotency key
+func (scheduler *Scheduler) storedTransfer(ctx context.Context, owner, key string) (db.TransferTxResult, error) {
+	var result db.TransferTxResult
+
+	stored, err := scheduler.store.GetIdempotencyKey(ctx, db.GetIdempotencyKeyParams{
+		Username: owner,
+		Key:      key,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = json.Unmarshal(stored.ResponseBody, &result)
+	return result, err
+}
+
+// completeAfterEnd ends a scheduled transfer whose next run would fall after its end date
+func completeAfterEnd(next *db.UpdateScheduledTransferParams) {
+	if next.EndAt.Valid && next.NextRunAt.After(next.EndAt.Time) {
+		next.Status = db.ScheduledTransferStatusCompleted
+	}
+}
+
+func hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,271 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestScheduler(store db.Store, now time.Time) *Scheduler {
+	scheduler := NewScheduler(store, utils.Config{
+		SchedulerBatchSize:   10,
+		SchedulerMaxFailures: 3,
+		SchedulerRetryDelay:  time.Hour,
+	})
+	scheduler.now = func() time.Time { return now }
+	return scheduler
+}
+
+// runOnce makes ClaimScheduledTransfersTx hand the given scheduled transfer to the scheduler and captures
+// the outcome RunScheduledTransferTx recorded. The transfer made with the run goes through TransferTx, so
+// the test cases stub it as they would a transfer of its own.
+func runOnce(store *mockdb.MockStore, scheduled db.ScheduledTransfer, outcome *db.ScheduledTransferOutcome) {
+	leasedUntil := scheduled.NextRunAt.Add(time.Minute)
+	store.EXPECT().ClaimScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.ClaimScheduledTransfersTxResult{Due: []db.ScheduledTransfer{scheduled}, LeasedUntil: leasedUntil}, nil)
+	store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, arg db.RunScheduledTransferTxParams) (db.ScheduledTransferRun, error) {
+			if arg.Scheduled.ID != scheduled.ID || !arg.LeasedUntil.Equal(leasedUntil) {
+				return db.ScheduledTransferRun{}, sql.ErrNoRows
+			}
+
+			result := arg.Outcome
+			if arg.Transfer != nil {
+				transfer, err := store.TransferTx(ctx, *arg.Transfer)
+				if err != nil {
+					return db.ScheduledTransferRun{}, err
+				}
+				result.TransferID = sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}
+			}
+			*outcome = result
+			return db.ScheduledTransferRun{ScheduledTransferID: scheduled.ID}, nil
+		})
+}
+
+func TestSchedulerRun(t *testing.T) {
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	scheduled := db.ScheduledTransfer{
+		ID:            1,
+		Owner:         utils.RandomOwner(),
+		FromAccountID: 1,
+		ToAccountID:   2,
+		Amount:        100,
+		Schedule:      "@every 24h",
+		NextRunAt:     now,
+		Status:        db.ScheduledTransferStatusActive,
+	}
+	transfer := db.TransferTxResult{Transfer: db.Transfer{ID: 42}}
+
+	testCases := []struct {
+		name         string
+		scheduled    func() db.ScheduledTransfer
+		buildStubs   func(store *mockdb.MockStore)
+		checkOutcome func(t *testing.T, outcome db.ScheduledTransferOutcome, err error)
+	}{
+		{
+			name: "Succeeded",
+			scheduled: func() db.ScheduledTransfer {
+				failing := scheduled
+				failing.ConsecutiveFailures = 2
+				return failing
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.TransferTxParams) (db.TransferTxResult, error) {
+						require.Equal(t, scheduled.FromAccountID, arg.FromAccountID)
+						require.Equal(t, scheduled.ToAccountID, arg.ToAccountID)
+						require.Equal(t, scheduled.Amount, arg.Amount)
+						require.NotNil(t, arg.Idempotency)
+						require.Equal(t, scheduled.Owner, arg.Idempotency.Username)
+						return transfer, nil
+					})
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.Equal(t, sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}, outcome.TransferID)
+				require.Empty(t, outcome.Error)
+				require.Equal(t, now.Add(24*time.Hour), outcome.Next.NextRunAt)
+				require.Equal(t, db.ScheduledTransferStatusActive, outcome.Next.Status)
+				require.Zero(t, outcome.Next.ConsecutiveFailures)
+			},
+		},
+		{
+			name: "InsufficientFundsRetried",
+			scheduled: func() db.ScheduledTransfer {
+				return scheduled
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.False(t, outcome.TransferID.Valid)
+				require.Equal(t, db.ErrInsufficientFunds.Error(), outcome.Error)
+				require.Equal(t, now.Add(time.Hour), outcome.Next.NextRunAt)
+				require.Equal(t, db.ScheduledTransferStatusActive, outcome.Next.Status)
+				require.Equal(t, int32(1), outcome.Next.ConsecutiveFailures)
+			},
+		},
+		{
+			name: "PausedAfterRepeatedFailures",
+			scheduled: func() db.ScheduledTransfer {
+				failing := scheduled
+				failing.ConsecutiveFailures = 2
+				return failing
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.Equal(t, db.ScheduledTransferStatusPaused, outcome.Next.Status)
+				require.Equal(t, int32(3), outcome.Next.ConsecutiveFailures)
+			},
+		},
+		{
+			name: "TransferLimitRetried",
+			scheduled: func() db.ScheduledTransfer {
+				return scheduled
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, &db.TransferLimitError{
+					AccountID: scheduled.FromAccountID,
+					Limit:     db.TransferLimitDailyCount,
+					Max:       5,
+					Used:      5,
+					Requested: 1,
+				})
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.False(t, outcome.TransferID.Valid)
+				require.Contains(t, outcome.Error, db.TransferLimitDailyCount)
+				require.Equal(t, now.Add(time.Hour), outcome.Next.NextRunAt)
+				require.Equal(t, int32(1), outcome.Next.ConsecutiveFailures)
+			},
+		},
+		{
+			name: "FrozenAccount",
+			scheduled: func() db.ScheduledTransfer {
+				return scheduled
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, &db.AccountStatusError{
+					AccountID: scheduled.ToAccountID,
+					Status:    db.AccountStatusFrozen,
+				})
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.Equal(t, "account [2] is frozen", outcome.Error)
+				require.Equal(t, int32(1), outcome.Next.ConsecutiveFailures)
+			},
+		},
+		{
+			name: "CompletedAfterEnd",
+			scheduled: func() db.ScheduledTransfer {
+				ending := scheduled
+				ending.EndAt = sql.NullTime{Time: now.Add(time.Hour), Valid: true}
+				return ending
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(transfer, nil)
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.True(t, outcome.TransferID.Valid)
+				require.Equal(t, db.ScheduledTransferStatusCompleted, outcome.Next.Status)
+			},
+		},
+		{
+			name: "AlreadyTransferred",
+			scheduled: func() db.ScheduledTransfer {
+				return scheduled
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				body, err := json.Marshal(transfer)
+				require.NoError(t, err)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrDuplicateIdempotencyKey)
+				store.EXPECT().GetIdempotencyKey(gomock.Any(), gomock.Any()).Times(1).Return(db.IdempotencyKey{ResponseBody: body}, nil)
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.NoError(t, err)
+				require.Equal(t, sql.NullInt64{Int64: transfer.Transfer.ID, Valid: true}, outcome.TransferID)
+			},
+		},
+		{
+			name: "UnexpectedError",
+			scheduled: func() db.ScheduledTransfer {
+				return scheduled
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, sql.ErrConnDone)
+			},
+			checkOutcome: func(t *testing.T, outcome db.ScheduledTransferOutcome, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			var outcome db.ScheduledTransferOutcome
+			runOnce(store, tc.scheduled(), &outcome)
+
+			err := newTestScheduler(store, now).RunDue(context.Background())
+			tc.checkOutcome(t, outcome, err)
+		})
+	}
+}
+
+func TestSchedulerRunDueClaimLost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ClaimScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).
+		Return(db.ClaimScheduledTransfersTxResult{Due: []db.ScheduledTransfer{
+			{ID: 1, Schedule: "@every 24h", FromAccountID: 1, ToAccountID: 2, Amount: 100},
+			{ID: 2, Schedule: "@every 24h", FromAccountID: 1, ToAccountID: 3, Amount: 100},
+		}}, nil)
+	gomock.InOrder(
+		store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransferRun{}, sql.ErrNoRows),
+		store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.ScheduledTransferRun{ID: 2}, nil),
+	)
+
+	err := newTestScheduler(store, time.Now()).RunDue(context.Background())
+	require.NoError(t, err)
+}
+
+func TestSchedulerRunDueBatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	gomock.InOrder(
+		store.EXPECT().ClaimScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).
+			Return(db.ClaimScheduledTransfersTxResult{Due: make([]db.ScheduledTransfer, 10)}, nil),
+		store.EXPECT().ClaimScheduledTransfersTx(gomock.Any(), gomock.Any()).Times(1).
+			Return(db.ClaimScheduledTransfersTxResult{Due: make([]db.ScheduledTransfer, 3)}, nil),
+	)
+	store.EXPECT().RunScheduledTransferTx(gomock.Any(), gomock.Any()).Times(13).Return(db.ScheduledTransferRun{}, nil)
+
+	err := newTestScheduler(store, time.Now()).RunDue(context.Background())
+	require.NoError(t, err)
+}
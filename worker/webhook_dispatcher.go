@@ -0,0 +1,157 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/outbox"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/mrityunjaygr8/simplebank/webhook"
+)
+
+const (
+	defaultWebhookInterval    = 10 * time.Second
+	defaultWebhookBatchSize   = 50
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookRetryDelay  = 30 * time.Second
+	maxWebhookRetryDelay      = 6 * time.Hour
+	// defaultWebhookLease outlasts a whole batch of sends timing out one after the other
+	defaultWebhookLease = 15 * time.Minute
+)
+
+// WebhookDispatcher sends due webhook deliveries. A failed attempt is retried with exponential backoff,
+// after maxAttempts the delivery is dead until it is retried by hand. Several dispatchers may run against
+// the same database, a claimed delivery is leased to one of them until it has been sent.
+type WebhookDispatcher struct {
+	store       db.Store
+	sender      *webhook.Sender
+	interval    time.Duration
+	batchSize   int32
+	maxAttempts int32
+	retryDelay  time.Duration
+	lease       time.Duration
+	now         func() time.Time
+}
+
+func NewWebhookDispatcher(store db.Store, config utils.Config) *WebhookDispatcher {
+	dispatcher := &WebhookDispatcher{
+		store:       store,
+		sender:      webhook.NewSender(),
+		interval:    config.WebhookInterval,
+		batchSize:   defaultWebhookBatchSize,
+		maxAttempts: config.WebhookMaxAttempts,
+		retryDelay:  config.WebhookRetryDelay,
+		lease:       defaultWebhookLease,
+		now:         time.Now,
+	}
+
+	if dispatcher.interval <= 0 {
+		dispatcher.interval = defaultWebhookInterval
+	}
+	if dispatcher.maxAttempts <= 0 {
+		dispatcher.maxAttempts = defaultWebhookMaxAttempts
+	}
+	if dispatcher.retryDelay <= 0 {
+		dispatcher.retryDelay = defaultWebhookRetryDelay
+	}
+	return dispatcher
+}
+
+// Start sends due deliveries every interval until the context is cancelled
+func (dispatcher *WebhookDispatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(dispatcher.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := dispatcher.DeliverDue(ctx); err != nil {
+			log.Printf("cannot deliver webhooks: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends every delivery that is due, one batch at a time. Each batch is claimed in its own
+// transaction and sent after it has been committed, so no locks are held while waiting on subscribers.
+func (dispatcher *WebhookDispatcher) DeliverDue(ctx context.Context) error {
+	for {
+		due, err := dispatcher.store.ClaimWebhookDeliveriesTx(ctx, db.ClaimWebhookDeliveriesTxParams{
+			Now:   dispatcher.now(),
+			Limit: dispatcher.batchSize,
+			Lease: dispatcher.lease,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, delivery := range due {
+			_, err := dispatcher.store.UpdateWebhookDelivery(ctx, dispatcher.deliver(ctx, delivery))
+			if errors.Is(err, sql.ErrNoRows) {
+				// the lease ran out and another dispatcher recorded an attempt first, or the subscription is gone
+				continue
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if len(due) < int(dispatcher.batchSize) {
+			return nil
+		}
+	}
+}
+
+// deliver makes one attempt at a delivery and works out when to try again if it failed
+func (dispatcher *WebhookDispatcher) deliver(ctx context.Context, delivery db.ListDueWebhookDeliveriesRow) db.UpdateWebhookDeliveryParams {
+	statusCode, err := dispatcher.sender.Send(ctx, delivery.Url, delivery.Secret, delivery.ID, outbox.Message{
+		ID:        delivery.EventID,
+		Type:      delivery.EventType,
+		AccountID: delivery.AccountID,
+		Payload:   delivery.Payload,
+		CreatedAt: delivery.EventCreatedAt,
+	})
+
+	now := dispatcher.now()
+	next := db.UpdateWebhookDeliveryParams{
+		ID:              delivery.ID,
+		Status:          db.WebhookDeliveryStatusDelivered,
+		Attempts:        delivery.Attempts + 1,
+		NextAttemptAt:   now,
+		ClaimedAttempts: delivery.Attempts,
+	}
+	if statusCode != 0 {
+		next.LastStatusCode = sql.NullInt32{Int32: int32(statusCode), Valid: true}
+	}
+
+	switch {
+	case err == nil:
+		next.DeliveredAt = sql.NullTime{Time: now, Valid: true}
+	case next.Attempts >= dispatcher.maxAttempts:
+		next.Status = db.WebhookDeliveryStatusDead
+		next.LastError = err.Error()
+	default:
+		next.Status = db.WebhookDeliveryStatusPending
+		next.LastError = err.Error()
+		next.NextAttemptAt = now.Add(dispatcher.backoff(next.Attempts))
+	}
+	return next
+}
+
+// backoff doubles the retry delay with every failed attempt, up to maxWebhookRetryDelay
+func (dispatcher *WebhookDispatcher) backoff(attempts int32) time.Duration {
+	delay := dispatcher.retryDelay
+	for i := int32(1); i < attempts && delay < maxWebhookRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxWebhookRetryDelay {
+		delay = maxWebhookRetryDelay
+	}
+	return delay
+}
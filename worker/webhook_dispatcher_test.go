@@ -0,0 +1,194 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	mockdb "github.com/mrityunjaygr8/simplebank/db/mock"
+	db "github.com/mrityunjaygr8/simplebank/db/sqlc"
+	"github.com/mrityunjaygr8/simplebank/utils"
+	"github.com/mrityunjaygr8/simplebank/webhook"
+	"github.com/stretchr/testify/require"
+)
+
+// recordWebhooks stubs UpdateWebhookDelivery by keeping the states the dispatcher records
+func recordWebhooks(updates *[]db.UpdateWebhookDeliveryParams) func(ctx context.Context, arg db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+	return func(ctx context.Context, arg db.UpdateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+		*updates = append(*updates, arg)
+		return db.WebhookDelivery{ID: arg.ID, Status: arg.Status, Attempts: arg.Attempts}, nil
+	}
+}
+
+func TestWebhookDispatcherDeliverDue(t *testing.T) {
+	secret := "0123456789abcdef"
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if err := webhook.Verify(secret, r.Header, body, webhook.DefaultTolerance, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		bodies = append(bodies, body)
+	}))
+	defer receiver.Close()
+
+	now := time.Now().Truncate(time.Second)
+	due := []db.ListDueWebhookDeliveriesRow{
+		{ID: 1, EventID: 10, EventType: db.EventTransferCompleted, Url: receiver.URL, Secret: secret, AccountID: 7, Payload: json.RawMessage(`{}`)},
+		{ID: 2, EventID: 11, EventType: db.EventEntryPosted, Url: receiver.URL, Secret: secret, AccountID: 7, Payload: json.RawMessage(`{}`)},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	dispatcher := NewWebhookDispatcher(store, utils.Config{})
+	dispatcher.sender = webhook.NewSenderWithClient(receiver.Client())
+	dispatcher.now = func() time.Time { return now }
+
+	var updates []db.UpdateWebhookDeliveryParams
+	store.EXPECT().ClaimWebhookDeliveriesTx(gomock.Any(), gomock.Eq(db.ClaimWebhookDeliveriesTxParams{
+		Now:   now,
+		Limit: defaultWebhookBatchSize,
+		Lease: defaultWebhookLease,
+	})).Times(1).Return(due, nil)
+	store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(recordWebhooks(&updates))
+
+	require.NoError(t, dispatcher.DeliverDue(context.Background()))
+	require.Len(t, bodies, 2)
+	require.Len(t, updates, 2)
+	for i, update := range updates {
+		require.Equal(t, due[i].ID, update.ID)
+		require.Equal(t, db.WebhookDeliveryStatusDelivered, update.Status)
+		require.Equal(t, int32(1), update.Attempts)
+		require.Zero(t, update.ClaimedAttempts)
+		require.Equal(t, int32(http.StatusOK), update.LastStatusCode.Int32)
+		require.True(t, update.DeliveredAt.Valid)
+		require.Empty(t, update.LastError)
+	}
+}
+
+func TestWebhookDispatcherRetries(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	now := time.Now().Truncate(time.Second)
+
+	testCases := []struct {
+		name     string
+		attempts int32
+		status   string
+		next     time.Time
+	}{
+		{
+			name:     "FirstFailure",
+			attempts: 0,
+			status:   db.WebhookDeliveryStatusPending,
+			next:     now.Add(30 * time.Second),
+		},
+		{
+			name:     "Backoff",
+			attempts: 3,
+			status:   db.WebhookDeliveryStatusPending,
+			next:     now.Add(8 * 30 * time.Second),
+		},
+		{
+			name:     "DeadLetter",
+			attempts: 7,
+			status:   db.WebhookDeliveryStatusDead,
+			next:     now,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			dispatcher := NewWebhookDispatcher(store, utils.Config{})
+			dispatcher.sender = webhook.NewSenderWithClient(receiver.Client())
+			dispatcher.now = func() time.Time { return now }
+
+			due := []db.ListDueWebhookDeliveriesRow{{ID: 1, Url: receiver.URL, Secret: "secret", Attempts: tc.attempts}}
+			var updates []db.UpdateWebhookDeliveryParams
+			store.EXPECT().ClaimWebhookDeliveriesTx(gomock.Any(), gomock.Any()).Times(1).Return(due, nil)
+			store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(recordWebhooks(&updates))
+
+			require.NoError(t, dispatcher.DeliverDue(context.Background()))
+			require.Len(t, updates, 1)
+			require.Equal(t, tc.status, updates[0].Status)
+			require.Equal(t, tc.attempts+1, updates[0].Attempts)
+			require.Equal(t, tc.attempts, updates[0].ClaimedAttempts)
+			require.Equal(t, tc.next, updates[0].NextAttemptAt)
+			require.Equal(t, int32(http.StatusServiceUnavailable), updates[0].LastStatusCode.Int32)
+			require.NotEmpty(t, updates[0].LastError)
+			require.False(t, updates[0].DeliveredAt.Valid)
+		})
+	}
+}
+
+func TestWebhookDispatcherLeaseLost(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	dispatcher := NewWebhookDispatcher(store, utils.Config{})
+	dispatcher.sender = webhook.NewSenderWithClient(receiver.Client())
+
+	due := []db.ListDueWebhookDeliveriesRow{
+		{ID: 1, Url: receiver.URL, Secret: "secret"},
+		{ID: 2, Url: receiver.URL, Secret: "secret"},
+	}
+	store.EXPECT().ClaimWebhookDeliveriesTx(gomock.Any(), gomock.Any()).Times(1).Return(due, nil)
+
+	// a delivery another dispatcher recorded first is skipped, the rest of the batch is still recorded
+	var updates []db.UpdateWebhookDeliveryParams
+	gomock.InOrder(
+		store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrNoRows),
+		store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(recordWebhooks(&updates)),
+	)
+
+	require.NoError(t, dispatcher.DeliverDue(context.Background()))
+	require.Len(t, updates, 1)
+	require.Equal(t, int64(2), updates[0].ID)
+}
+
+func TestWebhookDispatcherRecordError(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	dispatcher := NewWebhookDispatcher(store, utils.Config{})
+	dispatcher.sender = webhook.NewSenderWithClient(receiver.Client())
+
+	due := []db.ListDueWebhookDeliveriesRow{{ID: 1, Url: receiver.URL, Secret: "secret"}}
+	store.EXPECT().ClaimWebhookDeliveriesTx(gomock.Any(), gomock.Any()).Times(1).Return(due, nil)
+	store.EXPECT().UpdateWebhookDelivery(gomock.Any(), gomock.Any()).Times(1).Return(db.WebhookDelivery{}, sql.ErrConnDone)
+
+	require.ErrorIs(t, dispatcher.DeliverDue(context.Background()), sql.ErrConnDone)
+}
+
+func TestWebhookDispatcherBackoffCap(t *testing.T) {
+	dispatcher := NewWebhookDispatcher(nil, utils.Config{})
+	require.Equal(t, defaultWebhookRetryDelay, dispatcher.backoff(1))
+	require.Equal(t, 2*defaultWebhookRetryDelay, dispatcher.backoff(2))
+	require.Equal(t, maxWebhookRetryDelay, dispatcher.backoff(30))
+}